@@ -44,6 +44,9 @@ network shares, and remote storage with multiple comparison methods.`,
 	// Add commands
 	rootCmd.AddCommand(cli.NewSyncCommand())
 	rootCmd.AddCommand(cli.NewCompareCommand())
+	rootCmd.AddCommand(cli.NewCacheCommand())
+	rootCmd.AddCommand(cli.NewManifestCommand())
+	rootCmd.AddCommand(cli.NewScrubCommand())
 	rootCmd.AddCommand(cli.NewConfigCommand())
 	rootCmd.AddCommand(cli.NewVersionCommand())
 
@@ -0,0 +1,291 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/sdejongh/syncnorris/pkg/compare"
+	"github.com/sdejongh/syncnorris/pkg/config"
+	"github.com/sdejongh/syncnorris/pkg/hashcache"
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+// validHashCacheModes lists the accepted --hash-cache values
+var validHashCacheModes = map[string]bool{
+	"off":   true,
+	"db":    true,
+	"xattr": true,
+}
+
+// CacheFlags holds cache command flags
+type CacheFlags struct {
+	Path         string
+	Mode         string
+	Dir          string
+	MetadataOnly bool
+}
+
+var cacheFlags CacheFlags
+
+// NewCacheCommand creates the cache command
+func NewCacheCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the persistent hash cache",
+		Long: `Inspect and maintain the persistent hash cache used by the hash-based
+comparison methods to skip rehashing unchanged files.`,
+	}
+
+	cmd.PersistentFlags().StringVar(&cacheFlags.Path, "path", "", "directory whose hash cache is managed (required)")
+	cmd.PersistentFlags().StringVar(&cacheFlags.Mode, "hash-cache", "", "hash cache store: db, xattr (default: from config)")
+	cmd.PersistentFlags().StringVar(&cacheFlags.Dir, "hash-cache-dir", "", "directory for hash cache databases (default: user cache directory)")
+	cmd.MarkPersistentFlagRequired("path")
+
+	verifyCmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Check cached hashes against the files",
+		Long: `Report cache entries whose file is missing or has changed since it was hashed,
+and rehash unchanged files to detect content that no longer matches its cached hash.
+Exits with an error if any cached hash does not match the file content.`,
+		RunE: runCacheVerify,
+	}
+	verifyCmd.Flags().BoolVar(&cacheFlags.MetadataOnly, "metadata-only", false, "only compare size, modification time, inode and device (don't rehash)")
+
+	pruneCmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove stale cache entries",
+		Long:  `Remove cache entries whose file is missing or has changed since it was hashed.`,
+		RunE:  runCachePrune,
+	}
+
+	cmd.AddCommand(verifyCmd)
+	cmd.AddCommand(pruneCmd)
+
+	return cmd
+}
+
+// openHashCache opens the configured hash cache for a backend root
+// Returns a nil cache when caching is disabled
+func openHashCache(cfg config.HashCacheConfig, root string) (hashcache.Cache, error) {
+	switch cfg.Mode {
+	case "", "off":
+		return nil, nil
+
+	case "db":
+		var path string
+		if cfg.Dir != "" {
+			path = filepath.Join(cfg.Dir, hashcache.DBFileName(root))
+		} else {
+			var err error
+			if path, err = hashcache.DefaultDBPath(root); err != nil {
+				return nil, err
+			}
+		}
+		return hashcache.OpenDB(path, root)
+
+	case "xattr":
+		return hashcache.NewXattr(root)
+
+	default:
+		return nil, fmt.Errorf("unsupported hash cache mode: %s", cfg.Mode)
+	}
+}
+
+// attachHashCaches opens a hash cache for each backend when caching is enabled
+// The returned function flushes and closes the caches; call it before os.Exit
+// since deferred calls don't run on exit
+func attachHashCaches(cfg *config.Config, backends ...*storage.Local) (func(), error) {
+	var caches []hashcache.Cache
+	closeAll := func() {
+		for _, cache := range caches {
+			if err := cache.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to save hash cache: %v\n", err)
+			}
+		}
+		caches = nil
+	}
+
+	for _, backend := range backends {
+		cache, err := openHashCache(cfg.HashCache, backend.RootPath())
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("failed to open hash cache for %s: %w", backend.RootPath(), err)
+		}
+		if cache == nil {
+			continue
+		}
+		backend.SetHashCache(cache)
+		caches = append(caches, cache)
+	}
+
+	return closeAll, nil
+}
+
+// openCacheForCommand opens the hash cache selected by the cache command flags
+func openCacheForCommand() (*storage.Local, hashcache.Cache, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if cacheFlags.Mode != "" {
+		cfg.HashCache.Mode = cacheFlags.Mode
+	}
+	if cacheFlags.Dir != "" {
+		cfg.HashCache.Dir = cacheFlags.Dir
+	}
+	if cfg.HashCache.Mode == "" || cfg.HashCache.Mode == "off" {
+		return nil, nil, fmt.Errorf("hash cache is disabled (use --hash-cache db or --hash-cache xattr)")
+	}
+
+	backend, err := storage.NewLocal(cacheFlags.Path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open path: %w", err)
+	}
+
+	cache, err := openHashCache(cfg.HashCache, backend.RootPath())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open hash cache: %w", err)
+	}
+
+	return backend, cache, nil
+}
+
+// cacheEntryStatus classifies a cache entry against the file on disk
+type cacheEntryStatus string
+
+const (
+	cacheEntryValid    cacheEntryStatus = "valid"
+	cacheEntryStale    cacheEntryStatus = "stale"
+	cacheEntryMissing  cacheEntryStatus = "missing"
+	cacheEntryMismatch cacheEntryStatus = "mismatch"
+)
+
+// checkCacheEntry compares a cache entry with the current file
+// When rehash is true, hashes of unchanged files are recomputed and compared
+func checkCacheEntry(ctx context.Context, backend *storage.Local, entry hashcache.Entry, rehash bool) (cacheEntryStatus, string, error) {
+	info, err := backend.Stat(ctx, entry.Path)
+	if err != nil {
+		return cacheEntryMissing, "file no longer exists", nil
+	}
+	if !info.CacheKey().Matches(entry.Key) {
+		return cacheEntryStale, "file changed since it was hashed", nil
+	}
+	if !rehash {
+		return cacheEntryValid, "", nil
+	}
+
+	algorithms := make([]string, 0, len(entry.Hashes))
+	for algo := range entry.Hashes {
+		algorithms = append(algorithms, algo)
+	}
+	sort.Strings(algorithms)
+
+	for _, algo := range algorithms {
+		hasher, err := compare.NewHasher(algo)
+		if err != nil {
+			return "", "", err
+		}
+		reader, err := backend.Read(ctx, entry.Path)
+		if err != nil {
+			return "", "", err
+		}
+		_, err = io.Copy(hasher, reader)
+		reader.Close()
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read %s: %w", entry.Path, err)
+		}
+		if actual := fmt.Sprintf("%x", hasher.Sum(nil)); actual != entry.Hashes[algo] {
+			return cacheEntryMismatch, fmt.Sprintf("%s hash is %s, cache has %s", algo, actual, entry.Hashes[algo]), nil
+		}
+	}
+
+	return cacheEntryValid, "", nil
+}
+
+func runCacheVerify(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	backend, cache, err := openCacheForCommand()
+	if err != nil {
+		return err
+	}
+	defer cache.Close()
+
+	entries, err := cache.Entries(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read hash cache: %w", err)
+	}
+
+	counts := make(map[cacheEntryStatus]int)
+	for _, entry := range entries {
+		status, detail, err := checkCacheEntry(ctx, backend, entry, !cacheFlags.MetadataOnly)
+		if err != nil {
+			return err
+		}
+		counts[status]++
+		if status != cacheEntryValid && !globalFlags.Quiet {
+			fmt.Printf("%-8s %s: %s\n", status, entry.Path, detail)
+		}
+	}
+
+	fmt.Printf("Checked %d entries: %d valid, %d stale, %d missing, %d mismatched\n",
+		len(entries), counts[cacheEntryValid], counts[cacheEntryStale], counts[cacheEntryMissing], counts[cacheEntryMismatch])
+
+	if counts[cacheEntryMismatch] > 0 {
+		return fmt.Errorf("%d cached hashes do not match file content", counts[cacheEntryMismatch])
+	}
+	return nil
+}
+
+func runCachePrune(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	backend, cache, err := openCacheForCommand()
+	if err != nil {
+		return err
+	}
+
+	entries, err := cache.Entries(ctx)
+	if err != nil {
+		cache.Close()
+		return fmt.Errorf("failed to read hash cache: %w", err)
+	}
+
+	var pruned int
+	for _, entry := range entries {
+		status, _, err := checkCacheEntry(ctx, backend, entry, false)
+		if err != nil {
+			cache.Close()
+			return err
+		}
+		if status == cacheEntryValid {
+			continue
+		}
+		if err := cache.Remove(entry.Path); err != nil {
+			cache.Close()
+			return fmt.Errorf("failed to remove cache entry for %s: %w", entry.Path, err)
+		}
+		pruned++
+		if globalFlags.Verbose {
+			fmt.Printf("pruned %s (%s)\n", entry.Path, status)
+		}
+	}
+
+	if err := cache.Close(); err != nil {
+		return fmt.Errorf("failed to save hash cache: %w", err)
+	}
+
+	fmt.Printf("Pruned %d of %d entries\n", pruned, len(entries))
+	return nil
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"github.com/sdejongh/syncnorris/pkg/compare"
+	"github.com/sdejongh/syncnorris/pkg/manifest"
 	"github.com/sdejongh/syncnorris/pkg/models"
 	"github.com/sdejongh/syncnorris/pkg/output"
 	"github.com/sdejongh/syncnorris/pkg/storage"
@@ -19,24 +21,41 @@ func NewCompareCommand() *cobra.Command {
 		Use:   "compare",
 		Short: "Compare folders without syncing (dry-run)",
 		Long: `Compare source and destination folders and report differences
-without performing any file operations. This is equivalent to sync --dry-run.`,
+without performing any file operations. This is equivalent to sync --dry-run.
+
+The source may also be a checksum manifest (see "syncnorris manifest create"), in
+which case destination files are hashed and compared with the recorded hashes.`,
 		RunE: runCompare,
 	}
 
 	// Reuse sync flags for comparison
-	cmd.Flags().StringVarP(&syncFlags.Source, "source", "s", "", "source directory path (required)")
+	cmd.Flags().StringVarP(&syncFlags.Source, "source", "s", "", "source directory or checksum manifest path (required)")
 	cmd.Flags().StringVarP(&syncFlags.Dest, "dest", "d", "", "destination directory path (required)")
 	cmd.MarkFlagRequired("source")
 	cmd.MarkFlagRequired("dest")
 
-	cmd.Flags().StringVar(&syncFlags.Comparison, "comparison", "hash", "comparison method: namesize, md5, binary, hash")
+	cmd.Flags().StringVar(&syncFlags.Comparison, "comparison", "", "comparison method: namesize, timestamp, md5, blake3, xxh3, xxh128, binary, hash, chain (default: hash)")
+	cmd.Flags().StringVar(&syncFlags.CompareChain, "compare-chain", "", "comparison stages run in order until one decides, e.g. \"size,mtime(window=2s),full-hash(blake3)\" (implies --comparison chain)")
 	cmd.Flags().StringSliceVar(&syncFlags.Exclude, "exclude", []string{}, "glob patterns to exclude")
-	cmd.Flags().StringVarP(&syncFlags.Output, "output", "o", "human", "output format: human, json")
+	cmd.Flags().StringVarP(&syncFlags.Output, "output", "o", "human", "output format: human, json, ndjson")
 	cmd.Flags().StringVar(&syncFlags.DiffReport, "diff-report", "", "write differences report to file")
-	cmd.Flags().StringVar(&syncFlags.DiffFormat, "diff-format", "human", "differences report format: human, json")
+	cmd.Flags().StringVar(&syncFlags.DiffFormat, "diff-format", "human", "differences report format: human, json, html, junit, csv")
+	cmd.Flags().BoolVar(&syncFlags.ShowDiff, "show-diff", false, "show a unified diff of differing text files (up to 1MB) and the first differing byte of other files")
 	cmd.Flags().IntVarP(&syncFlags.Parallel, "parallel", "p", 0, "number of parallel workers (default: 5)")
 	cmd.Flags().StringVarP(&syncFlags.Bandwidth, "bandwidth", "b", "", "bandwidth limit (e.g., \"10M\", \"1G\")")
+	cmd.Flags().StringVar(&syncFlags.BandwidthSchedule, "bandwidth-schedule", "", "time-of-day bandwidth limits, e.g. \"Mon-Fri 08:00-18:00 2M; * unlimited\"")
+	cmd.Flags().StringVar(&syncFlags.SourceReadLimit, "source-read-limit", "", "limit for reads from the source, independent of --bandwidth (e.g., \"50M\")")
+	cmd.Flags().StringVar(&syncFlags.DestReadLimit, "dest-read-limit", "", "limit for reads from the destination, independent of --bandwidth (e.g., \"50M\")")
+	cmd.Flags().IntVar(&syncFlags.OpsLimit, "ops-limit", 0, "storage operations (list, stat, open) per second on both sides (0 = unlimited)")
 	cmd.Flags().BoolVar(&syncFlags.Delete, "delete", false, "include files that would be deleted from destination")
+	cmd.Flags().StringVar(&syncFlags.Links, "links", "", "symbolic links: preserve, follow, skip, safe-follow (default: preserve)")
+	cmd.Flags().StringVar(&syncFlags.ModifyWindow, "modify-window", "", "largest modification time difference considered equal, or \"auto\" to probe timestamp granularity (default: 1s)")
+	cmd.Flags().BoolVar(&syncFlags.IgnoreHourOffsets, "ignore-hour-offsets", false, "treat modification times differing by whole hours as equal (FAT/exFAT after DST or timezone changes)")
+	cmd.Flags().StringSliceVar(&syncFlags.TextPatterns, "text-pattern", []string{}, "glob patterns of files compared as text, ignoring line ending differences (can be repeated)")
+	cmd.Flags().BoolVar(&syncFlags.IgnoreTrailingWhitespace, "ignore-trailing-whitespace", false, "ignore trailing spaces and tabs when comparing text files")
+	cmd.Flags().BoolVar(&syncFlags.IgnoreBOM, "ignore-bom", false, "ignore a leading UTF-8 byte order mark when comparing text files")
+	cmd.Flags().StringVar(&syncFlags.HashCache, "hash-cache", "", "persistent hash cache: off, db, xattr (default: off)")
+	cmd.Flags().StringVar(&syncFlags.HashCacheDir, "hash-cache-dir", "", "directory for hash cache databases (default: user cache directory)")
 
 	// Logging flags
 	cmd.Flags().StringVar(&syncFlags.LogFile, "log-file", "", "write logs to file (enables logging)")
@@ -75,18 +94,56 @@ func runCompare(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create sync operation: %w", err)
 	}
 
-	// Create storage backends
-	source, err := storage.NewLocal(syncFlags.Source)
+	// A checksum manifest given as source is compared against its recorded hashes
+	var algorithmHint string
+	if cmd.Flags().Changed("comparison") {
+		algorithmHint = comparisonAlgorithms[operation.ComparisonMethod]
+	}
+	sourceManifest, err := loadSourceManifest(algorithmHint)
 	if err != nil {
-		return fmt.Errorf("failed to create source backend: %w", err)
+		return err
+	}
+	if syncFlags.ShowDiff && sourceManifest != nil {
+		return fmt.Errorf("--show-diff needs a source directory, not a checksum manifest")
 	}
-	defer source.Close()
+	operation.ShowDiff = syncFlags.ShowDiff
+	// Comparing is about content: a separate copy of a hard-linked file is not a difference
+	operation.HardLinks = false
 
+	// Create storage backends
 	dest, err := storage.NewLocal(syncFlags.Dest)
 	if err != nil {
 		return fmt.Errorf("failed to create destination backend: %w", err)
 	}
 	defer dest.Close()
+	configureLocal(cfg, dest)
+
+	var source storage.Backend
+	var closeHashCaches func()
+	if sourceManifest != nil {
+		source = manifest.NewBackend(sourceManifest)
+		operation.Mode = models.ModeOneWay
+		// Don't report the manifest itself when it is stored in the destination
+		if rel, ok := pathWithin(syncFlags.Dest, syncFlags.Source); ok {
+			operation.ExcludePatterns = append(operation.ExcludePatterns, filepath.ToSlash(rel))
+		}
+		closeHashCaches, err = attachHashCaches(cfg, dest)
+	} else {
+		local, localErr := storage.NewLocal(syncFlags.Source)
+		if localErr != nil {
+			return fmt.Errorf("failed to create source backend: %w", localErr)
+		}
+		defer local.Close()
+		configureLocal(cfg, local)
+		source = local
+		closeHashCaches, err = attachHashCaches(cfg, local, dest)
+	}
+	if err != nil {
+		return err
+	}
+
+	// --modify-window auto falls back to the default window: compare never writes to probe
+	resolveModifyWindow(ctx, cfg, operation, dest)
 
 	// Create comparator
 	var comparator compare.Comparator
@@ -103,16 +160,48 @@ func runCompare(cmd *cobra.Command, args []string) error {
 		// Fast hash: MD5 comparison
 		comparator = compare.NewMD5Comparator(cfg.Performance.BufferSize)
 
+	case models.CompareBLAKE3:
+		// Fast cryptographic hash: BLAKE3 comparison
+		comparator = compare.NewBLAKE3Comparator(cfg.Performance.BufferSize)
+
+	case models.CompareXXH3:
+		// Fastest hash: 64-bit XXH3 comparison (non-cryptographic)
+		comparator = compare.NewXXH3Comparator(cfg.Performance.BufferSize)
+
+	case models.CompareXXH128:
+		// Fast hash: 128-bit XXH3 comparison (non-cryptographic)
+		comparator = compare.NewXXH128Comparator(cfg.Performance.BufferSize)
+
 	case models.CompareBinary:
 		// Thorough: byte-by-byte comparison
 		comparator = compare.NewBinaryComparator(cfg.Performance.BufferSize)
 
 	case models.CompareTimestamp:
 		// Fast: name+size+timestamp comparison
-		comparator = compare.NewTimestampComparator()
+		comparator = compare.NewTimestampComparatorWithTolerance(sync.TimeTolerance(operation))
+
+	case models.CompareChain:
+		// Configurable: stages from sync.comparison_chain or --compare-chain
+		comparator, err = compare.ParseChain(cfg.Sync.ComparisonChain, cfg.Performance.BufferSize)
+		if err != nil {
+			return fmt.Errorf("invalid comparison chain: %w", err)
+		}
 
 	default:
-		return fmt.Errorf("unsupported comparison method: %s (use: namesize, timestamp, md5, binary, hash)", operation.ComparisonMethod)
+		return fmt.Errorf("unsupported comparison method: %s (use: namesize, timestamp, md5, blake3, xxh3, xxh128, binary, hash, chain)", operation.ComparisonMethod)
+	}
+
+	// Compare files matching the text patterns as text, other files with the selected method
+	if patterns := cfg.Sync.TextComparison.Patterns; len(patterns) > 0 {
+		comparator = compare.NewTextComparator(comparator, patterns, compare.TextOptions{
+			IgnoreTrailingWhitespace: cfg.Sync.TextComparison.IgnoreTrailingWhitespace,
+			IgnoreBOM:                cfg.Sync.TextComparison.IgnoreBOM,
+		})
+	}
+
+	// Manifest entries are checked by hashing destination files with the manifest's algorithm
+	if sourceManifest != nil {
+		comparator = compare.NewChecksumComparator()
 	}
 
 	// Create output formatter
@@ -120,6 +209,8 @@ func runCompare(cmd *cobra.Command, args []string) error {
 	switch syncFlags.Output {
 	case "json":
 		formatter = output.NewJSONFormatter()
+	case "ndjson":
+		formatter = output.NewNDJSONFormatter()
 	default:
 		if cfg.Output.Progress {
 			formatter = output.NewProgressFormatter()
@@ -140,20 +231,26 @@ func runCompare(cmd *cobra.Command, args []string) error {
 
 	// Run comparison (dry-run sync)
 	report, err := engine.Run(ctx)
+	closeHashCaches()
 	if err != nil {
 		return fmt.Errorf("comparison failed: %w", err)
 	}
 
 	// Write differences report for compare command
-	// In JSON output mode, skip the human-readable diff report (JSON formatter handles it)
-	if syncFlags.Output != "json" {
+	// In JSON/NDJSON output mode, skip the human-readable diff report (the formatter handles it)
+	if syncFlags.Output != "json" && syncFlags.Output != "ndjson" {
 		// If no file specified, write to stdout
-		if err := output.WriteDifferencesReport(report, syncFlags.DiffReport, syncFlags.DiffFormat); err != nil {
+		if err := output.WriteDifferencesReport(report, syncFlags.DiffReport, syncFlags.DiffFormat, syncFlags.ShowDiff); err != nil {
 			return fmt.Errorf("failed to write differences report: %w", err)
 		}
 	} else if syncFlags.DiffReport != "" {
 		// In JSON mode with explicit diff-report file, write JSON diff to file
-		if err := output.WriteDifferencesReport(report, syncFlags.DiffReport, "json"); err != nil {
+		// unless another file format (e.g., html) was explicitly requested
+		format := "json"
+		if cmd.Flags().Changed("diff-format") && syncFlags.DiffFormat != "human" {
+			format = syncFlags.DiffFormat
+		}
+		if err := output.WriteDifferencesReport(report, syncFlags.DiffReport, format, syncFlags.ShowDiff); err != nil {
 			return fmt.Errorf("failed to write differences report: %w", err)
 		}
 	}
@@ -0,0 +1,242 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/sdejongh/syncnorris/pkg/compare"
+	"github.com/sdejongh/syncnorris/pkg/manifest"
+	"github.com/sdejongh/syncnorris/pkg/models"
+	"github.com/sdejongh/syncnorris/pkg/output"
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+// ManifestFlags holds manifest command flags
+type ManifestFlags struct {
+	Path         string
+	Algorithm    string // Algorithm used by create
+	AlgoHint     string // Algorithm of sum-format manifests given to verify
+	Out          string
+	Format       string
+	Manifest     string
+	Parallel     int
+	DiffReport   string
+	DiffFormat   string
+	HashCache    string
+	HashCacheDir string
+}
+
+var manifestFlags ManifestFlags
+
+// comparisonAlgorithms maps hash-based comparison methods to their hash algorithm
+var comparisonAlgorithms = map[models.ComparisonMethod]string{
+	models.CompareHash:   compare.AlgorithmSHA256,
+	models.CompareMD5:    compare.AlgorithmMD5,
+	models.CompareBLAKE3: compare.AlgorithmBLAKE3,
+	models.CompareXXH3:   compare.AlgorithmXXH3,
+	models.CompareXXH128: compare.AlgorithmXXH128,
+}
+
+// NewManifestCommand creates the manifest command
+func NewManifestCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "manifest",
+		Short: "Create and verify checksum manifests",
+		Long: `Record the hash of every file in a directory and later check that the
+directory still matches. Manifests are compatible with sha256sum -c (sum format)
+and shasum --tag (bsd format), or can be written as JSON with sizes and timestamps.`,
+	}
+
+	cmd.PersistentFlags().StringVar(&manifestFlags.Path, "path", "", "directory to hash or verify (required)")
+	cmd.PersistentFlags().IntVarP(&manifestFlags.Parallel, "parallel", "p", 5, "number of files hashed in parallel")
+	cmd.MarkPersistentFlagRequired("path")
+
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Write a checksum manifest of a directory",
+		RunE:  runManifestCreate,
+	}
+	createCmd.Flags().StringVar(&manifestFlags.Algorithm, "algo", compare.AlgorithmSHA256, "hash algorithm: sha256, md5, blake3, xxh3, xxh128")
+	createCmd.Flags().StringVar(&manifestFlags.Out, "out", "", "manifest file (default: stdout)")
+	createCmd.Flags().StringVar(&manifestFlags.Format, "format", "", "manifest format: sum, bsd, json (default: json for *.json, sum otherwise)")
+	createCmd.Flags().StringVar(&manifestFlags.HashCache, "hash-cache", "", "persistent hash cache: off, db, xattr (default: off)")
+	createCmd.Flags().StringVar(&manifestFlags.HashCacheDir, "hash-cache-dir", "", "directory for hash cache databases (default: user cache directory)")
+
+	verifyCmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Check a directory against a checksum manifest",
+		Long: `Report files that are missing, not listed in the manifest, or whose content
+no longer matches. Exits with code 1 if anything differs.`,
+		RunE: runManifestVerify,
+	}
+	verifyCmd.Flags().StringVar(&manifestFlags.Manifest, "manifest", "", "manifest file to verify against (required)")
+	verifyCmd.Flags().StringVar(&manifestFlags.AlgoHint, "algo", "", "hash algorithm of sum-format manifests (default: guessed from the hash length)")
+	verifyCmd.Flags().StringVar(&manifestFlags.DiffReport, "diff-report", "", "write differences report to file")
+	verifyCmd.Flags().StringVar(&manifestFlags.DiffFormat, "diff-format", "human", "differences report format: human, json, html, junit, csv")
+	verifyCmd.MarkFlagRequired("manifest")
+
+	cmd.AddCommand(createCmd)
+	cmd.AddCommand(verifyCmd)
+
+	return cmd
+}
+
+// openManifestBackend opens the directory of manifest create with the configured hash cache
+func openManifestBackend() (*storage.Local, func(), error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if manifestFlags.HashCache != "" {
+		if !validHashCacheModes[manifestFlags.HashCache] {
+			return nil, nil, fmt.Errorf("invalid hash cache mode: %s (valid: off, db, xattr)", manifestFlags.HashCache)
+		}
+		cfg.HashCache.Mode = manifestFlags.HashCache
+	}
+	if manifestFlags.HashCacheDir != "" {
+		cfg.HashCache.Dir = manifestFlags.HashCacheDir
+	}
+
+	backend, err := storage.NewLocal(manifestFlags.Path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open path: %w", err)
+	}
+
+	closeHashCaches, err := attachHashCaches(cfg, backend)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return backend, closeHashCaches, nil
+}
+
+// pathWithin returns the path of file relative to root, if file is inside root
+func pathWithin(root, file string) (string, bool) {
+	rootAbs, err := filepath.Abs(root)
+	if err != nil {
+		return "", false
+	}
+	fileAbs, err := filepath.Abs(file)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(rootAbs, fileAbs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+func runManifestCreate(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	format := manifestFlags.Format
+	if format == "" {
+		format = manifest.FormatForPath(manifestFlags.Out)
+	}
+
+	backend, closeHashCaches, err := openManifestBackend()
+	if err != nil {
+		return err
+	}
+	defer backend.Close()
+	defer closeHashCaches()
+
+	opts := manifest.Options{Workers: manifestFlags.Parallel}
+	if manifestFlags.Out != "" {
+		// Don't record the manifest being written
+		if rel, ok := pathWithin(manifestFlags.Path, manifestFlags.Out); ok {
+			opts.Skip = []string{rel}
+		}
+	}
+	if globalFlags.Verbose {
+		opts.Progress = func(path string) {
+			fmt.Fprintf(os.Stderr, "hashed %s\n", path)
+		}
+	}
+
+	m, err := manifest.Create(ctx, backend, manifestFlags.Algorithm, opts)
+	if err != nil {
+		return fmt.Errorf("failed to create manifest: %w", err)
+	}
+
+	if manifestFlags.Out == "" {
+		return manifest.Write(os.Stdout, m, format)
+	}
+	if err := manifest.Save(manifestFlags.Out, m, format); err != nil {
+		return err
+	}
+	if !globalFlags.Quiet {
+		fmt.Fprintf(os.Stderr, "Wrote %s manifest of %d files to %s\n", m.Algorithm, len(m.Entries), manifestFlags.Out)
+	}
+	return nil
+}
+
+func runManifestVerify(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	m, err := manifest.Load(manifestFlags.Manifest, manifestFlags.AlgoHint)
+	if err != nil {
+		return err
+	}
+
+	// No hash cache: every file is read again, so corruption keeping the size and mtime is found
+	backend, err := storage.NewLocal(manifestFlags.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open path: %w", err)
+	}
+	defer backend.Close()
+
+	opts := manifest.Options{Workers: manifestFlags.Parallel}
+	if rel, ok := pathWithin(manifestFlags.Path, manifestFlags.Manifest); ok {
+		opts.Skip = []string{rel}
+	}
+
+	report, err := manifest.Verify(ctx, m, backend, opts)
+	if err != nil {
+		return fmt.Errorf("failed to verify manifest: %w", err)
+	}
+	report.SourcePath = manifestFlags.Manifest
+	report.DestPath = manifestFlags.Path
+
+	counts := make(map[models.DifferenceReason]int)
+	for _, diff := range report.Differences {
+		counts[diff.Reason]++
+	}
+	if !globalFlags.Quiet {
+		fmt.Printf("Verified %d files against %s manifest: %d ok, %d missing, %d extra, %d changed, %d errors\n",
+			report.Stats.FilesScanned.Load(), m.Algorithm, report.Stats.FilesSynchronized.Load(),
+			counts[models.ReasonOnlyInSource], counts[models.ReasonOnlyInDest],
+			counts[models.ReasonSizeDiff]+counts[models.ReasonHashDiff], len(report.Errors))
+	}
+
+	if manifestFlags.DiffReport != "" || !globalFlags.Quiet {
+		if err := output.WriteDifferencesReport(report, manifestFlags.DiffReport, manifestFlags.DiffFormat, false); err != nil {
+			return fmt.Errorf("failed to write differences report: %w", err)
+		}
+	}
+
+	os.Exit(report.Status.ExitCode())
+	return nil
+}
+
+// loadSourceManifest loads --source as a checksum manifest when it is a file rather than a directory
+// Returns nil when the source is a directory
+func loadSourceManifest(algorithm string) (*manifest.Manifest, error) {
+	info, err := os.Stat(syncFlags.Source)
+	if err != nil || info.IsDir() {
+		return nil, nil
+	}
+
+	return manifest.Load(syncFlags.Source, algorithm)
+}
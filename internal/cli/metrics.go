@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/sdejongh/syncnorris/pkg/config"
+	"github.com/sdejongh/syncnorris/pkg/logging"
+	"github.com/sdejongh/syncnorris/pkg/metrics"
+	"github.com/sdejongh/syncnorris/pkg/models"
+	"github.com/sdejongh/syncnorris/pkg/sync"
+)
+
+// createMetrics attaches a metrics collector to the engine when metrics export is enabled
+// Returns a nil collector when no textfile is configured
+// Metrics are exported once at the end of the run: a one-shot sync has no lifetime to serve /metrics over
+func createMetrics(cfg *config.Config, engine *sync.Engine) *metrics.Collector {
+	if cfg.Metrics.Textfile == "" {
+		return nil
+	}
+
+	collector := metrics.NewCollector(metrics.Labels{
+		Job:        cfg.Metrics.Job,
+		Mode:       string(cfg.Sync.Mode),
+		Comparison: string(cfg.Sync.Comparison),
+	})
+	engine.SetMetricsObserver(collector)
+
+	return collector
+}
+
+// finishMetrics records the final status of a run, including failed and cancelled ones, and writes
+// the metrics textfile (failures are reported but don't change the exit code)
+func finishMetrics(cfg *config.Config, collector *metrics.Collector, logger logging.Logger, report *models.SyncReport) {
+	if collector == nil {
+		return
+	}
+
+	collector.Finish(report)
+	if cfg.Metrics.Textfile != "" {
+		if err := collector.WriteTextfile(cfg.Metrics.Textfile); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			logger.Error(context.Background(), "Failed to write metrics textfile", err, nil)
+		}
+	}
+}
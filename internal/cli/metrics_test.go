@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sdejongh/syncnorris/pkg/config"
+	"github.com/sdejongh/syncnorris/pkg/logging"
+	"github.com/sdejongh/syncnorris/pkg/metrics"
+	"github.com/sdejongh/syncnorris/pkg/models"
+)
+
+func TestFinishMetrics_Failure(t *testing.T) {
+	cfg := config.Default()
+	cfg.Metrics.Textfile = filepath.Join(t.TempDir(), "syncnorris.prom")
+
+	collector := metrics.NewCollector(metrics.Labels{Job: "test"})
+	finishMetrics(cfg, collector, logging.NewNullLogger(), &models.SyncReport{Status: models.StatusFailed})
+
+	data, err := os.ReadFile(cfg.Metrics.Textfile)
+	if err != nil {
+		t.Fatalf("failed to read metrics file: %v", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "syncnorris_last_run_status{") && strings.Contains(line, `status="failed"`) {
+			if !strings.HasSuffix(line, " 1") {
+				t.Errorf("failed status line = %q, want value 1", line)
+			}
+			return
+		}
+	}
+	t.Errorf("metrics file missing the failed run status:\n%s", data)
+}
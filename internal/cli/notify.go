@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/config"
+	"github.com/sdejongh/syncnorris/pkg/logging"
+	"github.com/sdejongh/syncnorris/pkg/models"
+	"github.com/sdejongh/syncnorris/pkg/notify"
+)
+
+// notifyTimeout bounds the delivery of all notifications, retries included
+const notifyTimeout = 2 * time.Minute
+
+// createNotifier builds the notification dispatcher from configuration and flags
+// Webhooks from --notify-webhook are added to those declared in the config file
+func createNotifier(cfg *config.Config) (*notify.Dispatcher, error) {
+	dispatcher := notify.NewDispatcher()
+
+	for _, wh := range cfg.Notifications.Webhooks {
+		n, err := notify.NewWebhookNotifier(notify.WebhookConfig{
+			URL:            wh.URL,
+			Headers:        wh.Headers,
+			Secret:         wh.Secret,
+			BodyTemplate:   wh.BodyTemplate,
+			ContentType:    wh.ContentType,
+			On:             wh.On,
+			MaxRetries:     wh.MaxRetries,
+			InitialBackoff: wh.InitialBackoff,
+			Timeout:        wh.Timeout,
+		})
+		if err != nil {
+			return nil, err
+		}
+		dispatcher.Add(n)
+	}
+
+	for _, url := range syncFlags.NotifyWebhook {
+		n, err := notify.NewWebhookNotifier(notify.WebhookConfig{
+			URL:        url,
+			On:         syncFlags.NotifyOn,
+			MaxRetries: 3,
+		})
+		if err != nil {
+			return nil, err
+		}
+		dispatcher.Add(n)
+	}
+
+	return dispatcher, nil
+}
+
+// sendNotifications notifies the configured targets of a finished run, whatever its status
+// Delivery uses its own context, so an interrupted run still reports it was cancelled; failures
+// are reported but don't change the exit code
+func sendNotifications(cfg *config.Config, logger logging.Logger, report *models.SyncReport) {
+	notifier, err := createNotifier(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to configure notifications: %v\n", err)
+		return
+	}
+	if notifier.Len() == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+
+	if err := notifier.Notify(ctx, report); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: notification failed: %v\n", err)
+		logger.Error(ctx, "Notification failed", err, nil)
+	}
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sdejongh/syncnorris/pkg/config"
+	"github.com/sdejongh/syncnorris/pkg/logging"
+	"github.com/sdejongh/syncnorris/pkg/models"
+	"github.com/sdejongh/syncnorris/pkg/notify"
+)
+
+func TestSendNotifications_Failure(t *testing.T) {
+	var received []notify.Summary
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var summary notify.Summary
+		if err := json.NewDecoder(r.Body).Decode(&summary); err != nil {
+			t.Errorf("failed to decode body: %v", err)
+		}
+		received = append(received, summary)
+	}))
+	defer server.Close()
+
+	saved := syncFlags
+	defer func() { syncFlags = saved }()
+	syncFlags.NotifyWebhook = []string{server.URL}
+	syncFlags.NotifyOn = []string{"failed", "cancelled"}
+
+	for _, status := range []models.SyncStatus{models.StatusFailed, models.StatusCancelled, models.StatusSuccess} {
+		sendNotifications(config.Default(), logging.NewNullLogger(), &models.SyncReport{OperationID: "op", Status: status})
+	}
+
+	if len(received) != 2 {
+		t.Fatalf("received %d notifications, want 2 (failed and cancelled)", len(received))
+	}
+	if received[0].Status != "failed" || received[0].ExitCode != 2 {
+		t.Errorf("first notification status = %s, exit code = %d; want failed, 2", received[0].Status, received[0].ExitCode)
+	}
+	if received[1].Status != "cancelled" {
+		t.Errorf("second notification status = %s, want cancelled", received[1].Status)
+	}
+}
@@ -0,0 +1,287 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/sdejongh/syncnorris/pkg/config"
+	"github.com/sdejongh/syncnorris/pkg/manifest"
+	"github.com/sdejongh/syncnorris/pkg/models"
+	"github.com/sdejongh/syncnorris/pkg/output"
+	"github.com/sdejongh/syncnorris/pkg/ratelimit"
+	"github.com/sdejongh/syncnorris/pkg/scrub"
+	"github.com/sdejongh/syncnorris/pkg/storage"
+	"github.com/sdejongh/syncnorris/pkg/sync"
+)
+
+// ScrubFlags holds scrub command flags
+type ScrubFlags struct {
+	Path         string
+	From         string
+	Manifest     string
+	Algorithm    string
+	Replica      string
+	Repair       bool
+	Parallel     int
+	Bandwidth    string
+	Resume       bool
+	Checkpoint   string
+	DiffReport   string
+	DiffFormat   string
+	HashCache    string
+	HashCacheDir string
+}
+
+var scrubFlags ScrubFlags
+
+// NewScrubCommand creates the scrub command
+func NewScrubCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scrub",
+		Short: "Detect bit rot by re-hashing files against recorded hashes",
+		Long: `Re-hash files and compare them with hashes recorded earlier by a checksum
+manifest (--from manifest), the persistent hash cache (--from cache) or the
+bidirectional sync state (--from state). Files whose content changed while their
+size and modification time did not are reported as bit rot; files modified since
+their hash was recorded are skipped.
+
+With --repair, rotten files are copied back from --replica when the replica still
+matches the recorded hash. An interrupted scrub can be continued with --resume.
+Exits with code 1 if bit rot, missing files or read errors were found.`,
+		RunE: runScrub,
+	}
+
+	cmd.Flags().StringVar(&scrubFlags.Path, "path", "", "directory to scrub (required)")
+	cmd.Flags().StringVar(&scrubFlags.From, "from", "", "source of recorded hashes: manifest, cache, state (default: manifest if --manifest is set, cache otherwise)")
+	cmd.Flags().StringVar(&scrubFlags.Manifest, "manifest", "", "checksum manifest with the recorded hashes (--from manifest)")
+	cmd.Flags().StringVar(&scrubFlags.Algorithm, "algo", "", "hash algorithm of sum-format manifests, or of hash cache entries (default: guessed / fastest cached)")
+	cmd.Flags().StringVar(&scrubFlags.Replica, "replica", "", "other copy of the tree: the other side of the sync pair (--from state) and the repair source")
+	cmd.Flags().BoolVar(&scrubFlags.Repair, "repair", false, "copy rotten files back from --replica")
+	cmd.Flags().IntVarP(&scrubFlags.Parallel, "parallel", "p", 1, "number of files hashed in parallel")
+	cmd.Flags().StringVarP(&scrubFlags.Bandwidth, "bandwidth", "b", "", "read bandwidth limit (e.g., \"10M\", \"1G\")")
+	cmd.Flags().BoolVar(&scrubFlags.Resume, "resume", false, "continue an interrupted scrub instead of starting over")
+	cmd.Flags().StringVar(&scrubFlags.Checkpoint, "checkpoint", "", "checkpoint file used to resume (default: user cache directory)")
+	cmd.Flags().StringVar(&scrubFlags.DiffReport, "diff-report", "", "write differences report to file")
+	cmd.Flags().StringVar(&scrubFlags.DiffFormat, "diff-format", "human", "differences report format: human, json, html, junit, csv")
+	cmd.Flags().StringVar(&scrubFlags.HashCache, "hash-cache", "", "hash cache store read by --from cache: db, xattr (default: from config)")
+	cmd.Flags().StringVar(&scrubFlags.HashCacheDir, "hash-cache-dir", "", "directory for hash cache databases (default: user cache directory)")
+	cmd.MarkFlagRequired("path")
+
+	return cmd
+}
+
+// loadScrubRecords loads the recorded hashes selected by the scrub flags
+func loadScrubRecords(ctx context.Context, cfg *config.Config, root string) ([]scrub.Record, error) {
+	switch scrubFlags.From {
+	case "manifest":
+		if scrubFlags.Manifest == "" {
+			return nil, fmt.Errorf("--from manifest requires --manifest")
+		}
+		m, err := manifest.Load(scrubFlags.Manifest, scrubFlags.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		records := scrub.FromManifest(m)
+		if rel, ok := pathWithin(root, scrubFlags.Manifest); ok {
+			// The manifest can't record its own hash
+			for i, rec := range records {
+				if rec.Path == filepath.ToSlash(rel) {
+					records = append(records[:i], records[i+1:]...)
+					break
+				}
+			}
+		}
+		return records, nil
+
+	case "cache":
+		if scrubFlags.HashCache != "" {
+			cfg.HashCache.Mode = scrubFlags.HashCache
+		}
+		if scrubFlags.HashCacheDir != "" {
+			cfg.HashCache.Dir = scrubFlags.HashCacheDir
+		}
+		cache, err := openHashCache(cfg.HashCache, root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open hash cache: %w", err)
+		}
+		if cache == nil {
+			return nil, fmt.Errorf("hash cache is disabled (use --hash-cache db or --hash-cache xattr)")
+		}
+		defer cache.Close()
+		return scrub.FromHashCache(ctx, cache, scrubFlags.Algorithm)
+
+	case "state":
+		if scrubFlags.Replica == "" {
+			return nil, fmt.Errorf("--from state requires --replica, the other side of the sync pair")
+		}
+		return loadStateRecords()
+
+	default:
+		return nil, fmt.Errorf("invalid hash source: %s (valid: manifest, cache, state)", scrubFlags.From)
+	}
+}
+
+// loadStateRecords returns the hashes recorded in the sync state of --path and --replica
+// The pair may have been synced in either direction
+func loadStateRecords() ([]scrub.Record, error) {
+	pairs := [][2]string{
+		{scrubFlags.Replica, scrubFlags.Path},
+		{scrubFlags.Path, scrubFlags.Replica},
+	}
+	for _, pair := range pairs {
+		state, err := sync.LoadState(pair[0], pair[1])
+		if err != nil {
+			return nil, err
+		}
+		if state.IsFirstSync() {
+			continue
+		}
+
+		var records []scrub.Record
+		for path, fs := range state.Files {
+			if fs.IsDir || fs.Hash == "" || fs.HashAlgorithm == "" {
+				continue
+			}
+			records = append(records, scrub.Record{
+				Path:      filepath.ToSlash(path),
+				Algorithm: fs.HashAlgorithm,
+				Hash:      fs.Hash,
+				Size:      fs.Size,
+				ModTime:   fs.ModTime,
+			})
+		}
+		return records, nil
+	}
+	return nil, fmt.Errorf("no sync state found for %s and %s (run a bidirectional sync with --stateful first)", scrubFlags.Path, scrubFlags.Replica)
+}
+
+func runScrub(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	// Stop cleanly on Ctrl-C so the checkpoint can be resumed
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if scrubFlags.From == "" {
+		scrubFlags.From = "cache"
+		if scrubFlags.Manifest != "" {
+			scrubFlags.From = "manifest"
+		}
+	}
+	if scrubFlags.Repair && scrubFlags.Replica == "" {
+		return fmt.Errorf("--repair requires --replica")
+	}
+	if !validDiffFormats[scrubFlags.DiffFormat] {
+		return fmt.Errorf("invalid differences report format: %s (valid: human, json, html, junit, csv)", scrubFlags.DiffFormat)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	limiter := ratelimit.NewLimiter(cfg.Performance.BandwidthLimit)
+	if scrubFlags.Bandwidth != "" {
+		bandwidth, err := ratelimit.ParseRate(scrubFlags.Bandwidth)
+		if err != nil {
+			return err
+		}
+		limiter = ratelimit.NewLimiter(bandwidth)
+	} else if cfg.Performance.BandwidthSchedule != "" {
+		schedule, err := ratelimit.ParseSchedule(cfg.Performance.BandwidthSchedule)
+		if err != nil {
+			return err
+		}
+		limiter = ratelimit.NewScheduledLimiter(schedule)
+	}
+
+	backend, err := storage.NewLocal(scrubFlags.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open path: %w", err)
+	}
+	defer backend.Close()
+
+	records, err := loadScrubRecords(ctx, cfg, backend.RootPath())
+	if err != nil {
+		return err
+	}
+
+	opts := scrub.Options{
+		Workers: scrubFlags.Parallel,
+		Limiter: limiter,
+	}
+	if scrubFlags.Repair {
+		replica, err := storage.NewLocal(scrubFlags.Replica)
+		if err != nil {
+			return fmt.Errorf("failed to open replica: %w", err)
+		}
+		defer replica.Close()
+		opts.Replica = replica
+	}
+	if !globalFlags.Quiet {
+		opts.Progress = func(result scrub.Result) {
+			switch {
+			case result.Detail != "":
+				fmt.Printf("%-9s %s: %s\n", result.Status, result.Path, result.Detail)
+			case result.Status != scrub.StatusOK || globalFlags.Verbose:
+				fmt.Printf("%-9s %s\n", result.Status, result.Path)
+			}
+		}
+	}
+
+	checkpointPath := scrubFlags.Checkpoint
+	if checkpointPath == "" {
+		if checkpointPath, err = scrub.DefaultCheckpointPath(backend.RootPath()); err != nil {
+			return err
+		}
+	}
+	checkpoint, err := scrub.OpenCheckpoint(checkpointPath, scrubFlags.Resume)
+	if err != nil {
+		return err
+	}
+	opts.Checkpoint = checkpoint
+	if scrubFlags.Resume && checkpoint.Len() > 0 && !globalFlags.Quiet {
+		fmt.Printf("Resuming scrub: %d of %d files already checked\n", checkpoint.Len(), len(records))
+	}
+
+	report, err := scrub.Run(ctx, backend, records, opts)
+	if err != nil {
+		checkpoint.Close()
+		if ctx.Err() != nil {
+			fmt.Fprintf(os.Stderr, "Scrub interrupted; run again with --resume to continue\n")
+			os.Exit(models.StatusCancelled.ExitCode())
+		}
+		return fmt.Errorf("scrub failed: %w", err)
+	}
+	if err := checkpoint.Remove(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	report.SourcePath = scrubFlags.From
+	report.DestPath = scrubFlags.Path
+
+	counts := make(map[models.DifferenceReason]int)
+	for _, diff := range report.Differences {
+		counts[diff.Reason]++
+	}
+	if !globalFlags.Quiet {
+		fmt.Printf("Scrubbed %d files: %d ok, %d bit rot, %d repaired, %d changed, %d modified, %d missing, %d errors\n",
+			report.Stats.FilesScanned.Load(), report.Stats.FilesSynchronized.Load(), counts[models.ReasonBitRot],
+			report.Stats.FilesUpdated.Load(), counts[models.ReasonHashDiff], report.Stats.FilesSkipped.Load(),
+			counts[models.ReasonOnlyInSource], len(report.Errors))
+	}
+
+	if scrubFlags.DiffReport != "" {
+		if err := output.WriteDifferencesReport(report, scrubFlags.DiffReport, scrubFlags.DiffFormat, false); err != nil {
+			return fmt.Errorf("failed to write differences report: %w", err)
+		}
+	}
+
+	os.Exit(report.Status.ExitCode())
+	return nil
+}
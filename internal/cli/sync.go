@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/spf13/cobra"
 	"github.com/sdejongh/syncnorris/pkg/compare"
@@ -20,6 +22,7 @@ type SyncFlags struct {
 	Dest         string
 	Mode         string
 	Comparison   string
+	CompareChain string
 	Conflict     string
 	DryRun       bool
 	CreateDest   bool
@@ -30,11 +33,53 @@ type SyncFlags struct {
 	Output       string
 	DiffReport   string
 	DiffFormat   string
+	ShowDiff     bool
 	Stateful     bool
+	// Verification flags
+	Verify        bool
+	VerifyRetries int
+	// Timestamp flags
+	ModifyWindow      string
+	IgnoreHourOffsets bool
+	// Symbolic and hard link handling flags
+	Links       string
+	NoHardLinks bool
+	// Durability flag
+	Durability string
+	// Metadata preservation flags
+	Owner       bool
+	NumericIDs  bool
+	SpecialBits bool
+	Xattrs      bool
+	ACLs        bool
+	// Bandwidth schedule flag
+	BandwidthSchedule string
+	// Independent read, write and operation limit flags
+	SourceReadLimit string
+	DestReadLimit   string
+	WriteLimit      string
+	OpsLimit        int
+	// Adaptive throttling flags
+	Adaptive          bool
+	AdaptiveWorkers   string
+	AdaptiveBandwidth string
+	// Text comparison flags
+	TextPatterns             []string
+	IgnoreTrailingWhitespace bool
+	IgnoreBOM                bool
 	// Logging flags
 	LogFile      string
 	LogFormat    string
 	LogLevel     string
+	// Notification flags
+	NotifyWebhook []string
+	NotifyOn      []string
+	// Metrics flags
+	MetricsTextfile string
+	MetricsJob      string
+	// Hash cache flags
+	HashCache    string
+	HashCacheDir string
 }
 
 var syncFlags SyncFlags
@@ -57,24 +102,58 @@ Supports one-way and bidirectional sync with multiple comparison methods.`,
 
 	// Optional flags
 	cmd.Flags().StringVarP(&syncFlags.Mode, "mode", "m", "oneway", "sync mode: oneway, bidirectional")
-	cmd.Flags().StringVar(&syncFlags.Comparison, "comparison", "hash", "comparison method: namesize, md5, binary, hash")
+	cmd.Flags().StringVar(&syncFlags.Comparison, "comparison", "", "comparison method: namesize, timestamp, md5, blake3, xxh3, xxh128, binary, hash, chain (default: hash)")
+	cmd.Flags().StringVar(&syncFlags.CompareChain, "compare-chain", "", "comparison stages run in order until one decides, e.g. \"size,mtime(window=2s),full-hash(blake3)\" (implies --comparison chain)")
 	cmd.Flags().StringVar(&syncFlags.Conflict, "conflict", "newer", "conflict resolution: source-wins, dest-wins, newer, both")
 	cmd.Flags().BoolVar(&syncFlags.DryRun, "dry-run", false, "compare only, don't sync")
 	cmd.Flags().BoolVar(&syncFlags.CreateDest, "create-dest", false, "create destination directory if it doesn't exist")
 	cmd.Flags().BoolVar(&syncFlags.Delete, "delete", false, "delete files in destination that don't exist in source")
 	cmd.Flags().IntVarP(&syncFlags.Parallel, "parallel", "p", 0, "number of parallel workers (default: 5)")
 	cmd.Flags().StringVarP(&syncFlags.Bandwidth, "bandwidth", "b", "", "bandwidth limit (e.g., \"10M\", \"1G\")")
+	cmd.Flags().StringVar(&syncFlags.BandwidthSchedule, "bandwidth-schedule", "", "time-of-day bandwidth limits, e.g. \"Mon-Fri 08:00-18:00 2M; * unlimited\"")
+	cmd.Flags().StringVar(&syncFlags.SourceReadLimit, "source-read-limit", "", "limit for reads from the source, independent of --bandwidth (e.g., \"50M\")")
+	cmd.Flags().StringVar(&syncFlags.DestReadLimit, "dest-read-limit", "", "limit for reads from the destination, independent of --bandwidth (e.g., \"50M\")")
+	cmd.Flags().StringVar(&syncFlags.WriteLimit, "write-limit", "", "limit for writes, independent of --bandwidth (e.g., \"20M\")")
+	cmd.Flags().IntVar(&syncFlags.OpsLimit, "ops-limit", 0, "storage operations (list, stat, open, write, delete) per second on both sides (0 = unlimited)")
+	cmd.Flags().BoolVar(&syncFlags.Adaptive, "adaptive", false, "shrink and grow the active workers with write latency, error rate and load average (one-way mode)")
+	cmd.Flags().StringVar(&syncFlags.AdaptiveWorkers, "adaptive-workers", "", "bounds of the active workers, e.g. \"1-16\" (implies --adaptive, default: 1 to --parallel)")
+	cmd.Flags().StringVar(&syncFlags.AdaptiveBandwidth, "adaptive-bandwidth", "", "bounds of the adapted bandwidth limit, e.g. \"1M-50M\" (implies --adaptive)")
 	cmd.Flags().StringSliceVar(&syncFlags.Exclude, "exclude", []string{}, "glob patterns to exclude")
-	cmd.Flags().StringVarP(&syncFlags.Output, "output", "o", "human", "output format: human, json")
+	cmd.Flags().StringVarP(&syncFlags.Output, "output", "o", "human", "output format: human, json, ndjson")
 	cmd.Flags().StringVar(&syncFlags.DiffReport, "diff-report", "", "write differences report to file")
-	cmd.Flags().StringVar(&syncFlags.DiffFormat, "diff-format", "human", "differences report format: human, json")
+	cmd.Flags().StringVar(&syncFlags.DiffFormat, "diff-format", "human", "differences report format: human, json, html, junit, csv")
 	cmd.Flags().BoolVar(&syncFlags.Stateful, "stateful", false, "save sync state for bidirectional mode (enables change tracking between syncs)")
+	cmd.Flags().BoolVar(&syncFlags.Verify, "verify", false, "read back and hash every copied file from storage (page cache evicted on Linux), copying it again if it doesn't match the source")
+	cmd.Flags().IntVar(&syncFlags.VerifyRetries, "verify-retries", -1, "times a file is copied again after a verification failure (default: 1)")
+	cmd.Flags().StringVar(&syncFlags.ModifyWindow, "modify-window", "", "largest modification time difference considered equal, or \"auto\" to probe timestamp granularity (default: 1s)")
+	cmd.Flags().BoolVar(&syncFlags.IgnoreHourOffsets, "ignore-hour-offsets", false, "treat modification times differing by whole hours as equal (FAT/exFAT after DST or timezone changes)")
+	cmd.Flags().StringVar(&syncFlags.Links, "links", "", "symbolic links: preserve, follow, skip, safe-follow (default: preserve)")
+	cmd.Flags().BoolVar(&syncFlags.NoHardLinks, "no-hard-links", false, "copy every hard-linked source file instead of recreating the links (one-way mode)")
+	cmd.Flags().BoolVar(&syncFlags.Owner, "owner", false, "preserve file owner and group, mapped by name (usually requires root)")
+	cmd.Flags().BoolVar(&syncFlags.NumericIDs, "numeric-ids", false, "map owners by numeric uid/gid instead of name (implies --owner)")
+	cmd.Flags().BoolVar(&syncFlags.SpecialBits, "special-bits", false, "preserve setuid, setgid and sticky bits")
+	cmd.Flags().BoolVar(&syncFlags.Xattrs, "xattrs", false, "preserve user, trusted and security extended attributes")
+	cmd.Flags().BoolVar(&syncFlags.ACLs, "acls", false, "preserve POSIX ACLs")
+	cmd.Flags().StringVar(&syncFlags.Durability, "durability", "", "fsync writes: none, file (each file), full (files, directories and state file) (default: none)")
+	cmd.Flags().StringSliceVar(&syncFlags.TextPatterns, "text-pattern", []string{}, "glob patterns of files compared as text, ignoring line ending differences (can be repeated)")
+	cmd.Flags().BoolVar(&syncFlags.IgnoreTrailingWhitespace, "ignore-trailing-whitespace", false, "ignore trailing spaces and tabs when comparing text files")
+	cmd.Flags().BoolVar(&syncFlags.IgnoreBOM, "ignore-bom", false, "ignore a leading UTF-8 byte order mark when comparing text files")
+	cmd.Flags().StringVar(&syncFlags.HashCache, "hash-cache", "", "persistent hash cache: off, db, xattr (default: off)")
+	cmd.Flags().StringVar(&syncFlags.HashCacheDir, "hash-cache-dir", "", "directory for hash cache databases (default: user cache directory)")
 
 	// Logging flags
 	cmd.Flags().StringVar(&syncFlags.LogFile, "log-file", "", "write logs to file (enables logging)")
 	cmd.Flags().StringVar(&syncFlags.LogFormat, "log-format", "text", "log format: text, json")
 	cmd.Flags().StringVar(&syncFlags.LogLevel, "log-level", "info", "log level: debug, info, warn, error")
 
+	// Notification flags
+	cmd.Flags().StringSliceVar(&syncFlags.NotifyWebhook, "notify-webhook", []string{}, "POST a JSON summary to this URL when the sync completes (repeatable)")
+	cmd.Flags().StringSliceVar(&syncFlags.NotifyOn, "notify-on", []string{}, "statuses that trigger --notify-webhook: success, partial, failed, cancelled, always")
+
+	// Metrics flags
+	cmd.Flags().StringVar(&syncFlags.MetricsTextfile, "metrics-textfile", "", "write Prometheus metrics to file at the end of the run (node_exporter textfile collector)")
+	cmd.Flags().StringVar(&syncFlags.MetricsJob, "metrics-job", "", "job label for exported metrics (default: syncnorris)")
+
 	return cmd
 }
 
@@ -83,6 +162,9 @@ func runSync(cmd *cobra.Command, args []string) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	// Stop on Ctrl-C with a cancelled report, so notifications and metrics still see the run
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	// Validate flags
 	if err := validateSyncFlags(); err != nil {
@@ -117,6 +199,21 @@ func runSync(cmd *cobra.Command, args []string) error {
 	}
 	defer dest.Close()
 
+	configureLocal(cfg, source, dest)
+
+	// Attach persistent hash caches
+	closeHashCaches, err := attachHashCaches(cfg, source, dest)
+	if err != nil {
+		return err
+	}
+
+	// Probe timestamp granularity for --modify-window auto (the source is only written in bidirectional mode)
+	if operation.Mode == models.ModeBidirectional {
+		resolveModifyWindow(ctx, cfg, operation, dest, source)
+	} else {
+		resolveModifyWindow(ctx, cfg, operation, dest)
+	}
+
 	// Create comparator
 	var comparator compare.Comparator
 	switch operation.ComparisonMethod {
@@ -135,6 +232,18 @@ func runSync(cmd *cobra.Command, args []string) error {
 		// Suitable for non-critical data where speed matters
 		comparator = compare.NewMD5Comparator(cfg.Performance.BufferSize)
 
+	case models.CompareBLAKE3:
+		// Fast cryptographic hash: BLAKE3, large files are hashed on multiple cores
+		comparator = compare.NewBLAKE3Comparator(cfg.Performance.BufferSize)
+
+	case models.CompareXXH3:
+		// Fastest hash: 64-bit XXH3 (non-cryptographic, detects corruption not tampering)
+		comparator = compare.NewXXH3Comparator(cfg.Performance.BufferSize)
+
+	case models.CompareXXH128:
+		// Fast hash: 128-bit XXH3 (non-cryptographic, lower collision risk than xxh3)
+		comparator = compare.NewXXH128Comparator(cfg.Performance.BufferSize)
+
 	case models.CompareBinary:
 		// Thorough: byte-by-byte comparison
 		// Slowest but most precise (reports exact byte offset of difference)
@@ -143,10 +252,25 @@ func runSync(cmd *cobra.Command, args []string) error {
 	case models.CompareTimestamp:
 		// Fast: name+size+timestamp comparison
 		// Copies only if source is newer than destination
-		comparator = compare.NewTimestampComparator()
+		comparator = compare.NewTimestampComparatorWithTolerance(sync.TimeTolerance(operation))
+
+	case models.CompareChain:
+		// Configurable: stages from sync.comparison_chain or --compare-chain
+		comparator, err = compare.ParseChain(cfg.Sync.ComparisonChain, cfg.Performance.BufferSize)
+		if err != nil {
+			return fmt.Errorf("invalid comparison chain: %w", err)
+		}
 
 	default:
-		return fmt.Errorf("unsupported comparison method: %s (use: namesize, timestamp, md5, binary, hash)", operation.ComparisonMethod)
+		return fmt.Errorf("unsupported comparison method: %s (use: namesize, timestamp, md5, blake3, xxh3, xxh128, binary, hash, chain)", operation.ComparisonMethod)
+	}
+
+	// Compare files matching the text patterns as text, other files with the selected method
+	if patterns := cfg.Sync.TextComparison.Patterns; len(patterns) > 0 {
+		comparator = compare.NewTextComparator(comparator, patterns, compare.TextOptions{
+			IgnoreTrailingWhitespace: cfg.Sync.TextComparison.IgnoreTrailingWhitespace,
+			IgnoreBOM:                cfg.Sync.TextComparison.IgnoreBOM,
+		})
 	}
 
 	// Create output formatter
@@ -154,6 +278,8 @@ func runSync(cmd *cobra.Command, args []string) error {
 	switch syncFlags.Output {
 	case "json":
 		formatter = output.NewJSONFormatter()
+	case "ndjson":
+		formatter = output.NewNDJSONFormatter()
 	default:
 		if cfg.Output.Progress {
 			formatter = output.NewProgressFormatter()
@@ -172,9 +298,23 @@ func runSync(cmd *cobra.Command, args []string) error {
 	// Create sync engine
 	engine := sync.NewEngine(source, dest, comparator, formatter, logger, operation)
 
+	// Setup metrics export
+	collector := createMetrics(cfg, engine)
+
 	// Run sync
 	report, err := engine.Run(ctx)
+	closeHashCaches()
+
+	// Failed and cancelled runs are exported and notified too
+	if report != nil {
+		finishMetrics(cfg, collector, logger, report)
+		sendNotifications(cfg, logger, report)
+	}
 	if err != nil {
+		if report != nil && report.Status == models.StatusCancelled {
+			fmt.Fprintf(os.Stderr, "Sync interrupted: %v\n", err)
+			os.Exit(models.StatusCancelled.ExitCode())
+		}
 		return fmt.Errorf("sync failed: %w", err)
 	}
 
@@ -183,7 +323,7 @@ func runSync(cmd *cobra.Command, args []string) error {
 	// - --diff-report is specified (write to file)
 	// - --diff-format is explicitly set (write to stdout)
 	if syncFlags.DiffReport != "" || cmd.Flags().Changed("diff-format") {
-		if err := output.WriteDifferencesReport(report, syncFlags.DiffReport, syncFlags.DiffFormat); err != nil {
+		if err := output.WriteDifferencesReport(report, syncFlags.DiffReport, syncFlags.DiffFormat, false); err != nil {
 			return fmt.Errorf("failed to write differences report: %w", err)
 		}
 	}
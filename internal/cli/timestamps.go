@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/sdejongh/syncnorris/pkg/compare"
+	"github.com/sdejongh/syncnorris/pkg/config"
+	"github.com/sdejongh/syncnorris/pkg/models"
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+// resolveModifyWindow sets the operation's modify window when it is configured as "auto"
+// The window becomes the coarsest timestamp granularity of the probed backends (2s on FAT),
+// and never less than the default window
+// Probing writes a file, so read-only runs (compare, --dry-run) keep the default window instead
+func resolveModifyWindow(ctx context.Context, cfg *config.Config, operation *models.SyncOperation, backends ...storage.Backend) {
+	if cfg.Sync.ModifyWindow != config.ModifyWindowAuto {
+		return
+	}
+
+	window := compare.DefaultModifyWindow
+	if operation.DryRun {
+		operation.ModifyWindow = window
+		fmt.Fprintf(os.Stderr, "Notice: --modify-window auto is not probed without writing; using %s\n", window)
+		return
+	}
+	for _, backend := range backends {
+		granularity, err := storage.ProbeTimeGranularity(ctx, backend)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to detect timestamp granularity: %v\n", err)
+			continue
+		}
+		if granularity > window {
+			window = granularity
+		}
+	}
+	operation.ModifyWindow = window
+
+	if globalFlags.Verbose {
+		fmt.Fprintf(os.Stderr, "Modify window: %s (detected)\n", window)
+	}
+}
@@ -3,21 +3,34 @@ package cli
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/sdejongh/syncnorris/pkg/compare"
 	"github.com/sdejongh/syncnorris/pkg/config"
 	"github.com/sdejongh/syncnorris/pkg/models"
+	"github.com/sdejongh/syncnorris/pkg/ratelimit"
+	"github.com/sdejongh/syncnorris/pkg/storage"
 )
 
+// validDiffFormats lists the accepted --diff-format values
+var validDiffFormats = map[string]bool{
+	"human": true,
+	"json":  true,
+	"html":  true,
+	"junit": true,
+	"csv":   true,
+}
+
 // validateSyncFlags validates the sync command flags
 func validateSyncFlags() error {
 	// Validate source exists
-	if _, err := os.Stat(syncFlags.Source); os.IsNotExist(err) {
+	sourceInfo, err := os.Stat(syncFlags.Source)
+	if os.IsNotExist(err) {
 		return fmt.Errorf("source path does not exist: %s", syncFlags.Source)
 	}
 
@@ -58,7 +71,8 @@ func validateSyncFlags() error {
 	if strings.HasPrefix(destAbs, sourceAbs+string(filepath.Separator)) {
 		return fmt.Errorf("destination cannot be inside source directory")
 	}
-	if strings.HasPrefix(sourceAbs, destAbs+string(filepath.Separator)) {
+	// A manifest file used as source may live inside the destination it describes
+	if strings.HasPrefix(sourceAbs, destAbs+string(filepath.Separator)) && (sourceInfo == nil || sourceInfo.IsDir()) {
 		return fmt.Errorf("source cannot be inside destination directory")
 	}
 
@@ -78,9 +92,16 @@ func validateSyncFlags() error {
 		"binary":    true,
 		"hash":      true,
 		"md5":       true,
+		"blake3":    true,
+		"xxh3":      true,
+		"xxh128":    true,
+		"chain":     true,
 	}
-	if !validComparisons[syncFlags.Comparison] {
-		return fmt.Errorf("invalid comparison method: %s (valid: namesize, timestamp, binary, hash, md5)", syncFlags.Comparison)
+	if syncFlags.Comparison != "" && !validComparisons[syncFlags.Comparison] {
+		return fmt.Errorf("invalid comparison method: %s (valid: namesize, timestamp, binary, hash, md5, blake3, xxh3, xxh128, chain)", syncFlags.Comparison)
+	}
+	if syncFlags.CompareChain != "" && syncFlags.Comparison != "" && syncFlags.Comparison != "chain" {
+		return fmt.Errorf("--compare-chain cannot be combined with --comparison %s", syncFlags.Comparison)
 	}
 
 	// Validate conflict resolution
@@ -94,6 +115,113 @@ func validateSyncFlags() error {
 		return fmt.Errorf("invalid conflict resolution: %s (valid: source-wins, dest-wins, newer, both)", syncFlags.Conflict)
 	}
 
+	// Validate differences report format
+	if !validDiffFormats[syncFlags.DiffFormat] {
+		return fmt.Errorf("invalid differences report format: %s (valid: human, json, html, junit, csv)", syncFlags.DiffFormat)
+	}
+
+	// Validate verification retries (-1 = not set)
+	if syncFlags.VerifyRetries < -1 {
+		return fmt.Errorf("invalid verify retries: %d (must not be negative)", syncFlags.VerifyRetries)
+	}
+
+	// Validate modify window
+	if _, err := config.ParseModifyWindow(syncFlags.ModifyWindow); err != nil {
+		return err
+	}
+
+	// Validate symbolic link mode
+	if _, err := storage.ParseLinkMode(syncFlags.Links); err != nil {
+		return err
+	}
+
+	// Validate durability level
+	if _, err := storage.ParseDurability(syncFlags.Durability); err != nil {
+		return err
+	}
+
+	// Validate bandwidth schedule
+	if syncFlags.BandwidthSchedule != "" {
+		if syncFlags.Bandwidth != "" {
+			return fmt.Errorf("--bandwidth cannot be combined with --bandwidth-schedule")
+		}
+		if _, err := ratelimit.ParseSchedule(syncFlags.BandwidthSchedule); err != nil {
+			return err
+		}
+	}
+
+	// Validate independent read and write limits
+	limitFlags := []struct {
+		name  string
+		value string
+	}{
+		{"--source-read-limit", syncFlags.SourceReadLimit},
+		{"--dest-read-limit", syncFlags.DestReadLimit},
+		{"--write-limit", syncFlags.WriteLimit},
+	}
+	for _, flag := range limitFlags {
+		if _, err := ratelimit.ParseRate(flag.value); err != nil {
+			return fmt.Errorf("invalid %s: %w", flag.name, err)
+		}
+	}
+	if syncFlags.OpsLimit < 0 {
+		return fmt.Errorf("invalid ops limit: %d (must not be negative)", syncFlags.OpsLimit)
+	}
+
+	// Validate adaptive throttling bounds
+	if syncFlags.Adaptive || syncFlags.AdaptiveWorkers != "" || syncFlags.AdaptiveBandwidth != "" {
+		if syncFlags.Mode == string(models.ModeBidirectional) {
+			return fmt.Errorf("adaptive throttling is only supported in one-way mode")
+		}
+	}
+	if syncFlags.AdaptiveWorkers != "" {
+		minWorkers, _, err := parseBounds(syncFlags.AdaptiveWorkers, parseCount)
+		if err != nil {
+			return fmt.Errorf("invalid --adaptive-workers: %w", err)
+		}
+		if minWorkers < 1 {
+			return fmt.Errorf("invalid --adaptive-workers: at least 1 worker must stay active")
+		}
+	}
+	if syncFlags.AdaptiveBandwidth != "" {
+		if syncFlags.BandwidthSchedule != "" {
+			return fmt.Errorf("--adaptive-bandwidth cannot be combined with --bandwidth-schedule")
+		}
+		minBandwidth, _, err := parseBounds(syncFlags.AdaptiveBandwidth, ratelimit.ParseRate)
+		if err != nil {
+			return fmt.Errorf("invalid --adaptive-bandwidth: %w", err)
+		}
+		if minBandwidth < 1 {
+			return fmt.Errorf("invalid --adaptive-bandwidth: the minimum must not be unlimited")
+		}
+	}
+
+	// Validate text comparison patterns
+	for _, pattern := range syncFlags.TextPatterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid text pattern: %s", pattern)
+		}
+	}
+
+	// Validate hash cache mode
+	if syncFlags.HashCache != "" && !validHashCacheModes[syncFlags.HashCache] {
+		return fmt.Errorf("invalid hash cache mode: %s (valid: off, db, xattr)", syncFlags.HashCache)
+	}
+
+	// Validate notification status filters
+	validNotifyOn := map[string]bool{
+		"success":   true,
+		"partial":   true,
+		"failed":    true,
+		"cancelled": true,
+		"always":    true,
+	}
+	for _, on := range syncFlags.NotifyOn {
+		if !validNotifyOn[on] {
+			return fmt.Errorf("invalid notification filter: %s (valid: success, partial, failed, cancelled, always)", on)
+		}
+	}
+
 	return nil
 }
 
@@ -116,6 +244,10 @@ func applyFlagsToConfig(cfg *config.Config) {
 	if syncFlags.Comparison != "" {
 		cfg.Sync.Comparison = models.ComparisonMethod(syncFlags.Comparison)
 	}
+	if syncFlags.CompareChain != "" {
+		cfg.Sync.Comparison = models.CompareChain
+		cfg.Sync.ComparisonChain = compare.SplitChain(syncFlags.CompareChain)
+	}
 
 	// Conflict resolution
 	if syncFlags.Conflict != "" {
@@ -150,48 +282,158 @@ func applyFlagsToConfig(cfg *config.Config) {
 		cfg.Output.Progress = true
 	}
 
+	// Post-copy verification
+	if syncFlags.Verify {
+		cfg.Sync.Verify = true
+	}
+	if syncFlags.VerifyRetries >= 0 {
+		cfg.Sync.VerifyRetries = syncFlags.VerifyRetries
+	}
+
+	// Timestamp tolerance
+	if syncFlags.ModifyWindow != "" {
+		cfg.Sync.ModifyWindow = syncFlags.ModifyWindow
+	}
+	if syncFlags.IgnoreHourOffsets {
+		cfg.Sync.IgnoreHourOffsets = true
+	}
+
+	// Symbolic link handling
+	if syncFlags.Links != "" {
+		cfg.Sync.Links = syncFlags.Links
+	}
+	if syncFlags.NoHardLinks {
+		cfg.Sync.HardLinks = false
+	}
+
+	// Durability
+	if syncFlags.Durability != "" {
+		cfg.Sync.Durability = syncFlags.Durability
+	}
+
+	// Metadata preservation
+	if syncFlags.Owner || syncFlags.NumericIDs {
+		cfg.Sync.Preserve.Owner = true
+	}
+	if syncFlags.NumericIDs {
+		cfg.Sync.Preserve.NumericIDs = true
+	}
+	if syncFlags.SpecialBits {
+		cfg.Sync.Preserve.SpecialBits = true
+	}
+	if syncFlags.Xattrs {
+		cfg.Sync.Preserve.Xattrs = true
+	}
+	if syncFlags.ACLs {
+		cfg.Sync.Preserve.ACLs = true
+	}
+
+	// Text comparison
+	if len(syncFlags.TextPatterns) > 0 {
+		cfg.Sync.TextComparison.Patterns = syncFlags.TextPatterns
+	}
+	if syncFlags.IgnoreTrailingWhitespace {
+		cfg.Sync.TextComparison.IgnoreTrailingWhitespace = true
+	}
+	if syncFlags.IgnoreBOM {
+		cfg.Sync.TextComparison.IgnoreBOM = true
+	}
+
+	// Hash cache
+	if syncFlags.HashCache != "" {
+		cfg.HashCache.Mode = syncFlags.HashCache
+	}
+	if syncFlags.HashCacheDir != "" {
+		cfg.HashCache.Dir = syncFlags.HashCacheDir
+	}
+
+	// Metrics export
+	if syncFlags.MetricsTextfile != "" {
+		cfg.Metrics.Textfile = syncFlags.MetricsTextfile
+	}
+	if syncFlags.MetricsJob != "" {
+		cfg.Metrics.Job = syncFlags.MetricsJob
+	}
+
 	// Bandwidth limit
 	if syncFlags.Bandwidth != "" {
-		if bw, err := parseBandwidth(syncFlags.Bandwidth); err == nil {
+		if bw, err := ratelimit.ParseRate(syncFlags.Bandwidth); err == nil {
 			cfg.Performance.BandwidthLimit = bw
+			cfg.Performance.BandwidthSchedule = ""
 		}
 	}
-}
+	if syncFlags.BandwidthSchedule != "" {
+		cfg.Performance.BandwidthSchedule = syncFlags.BandwidthSchedule
+	}
 
-// parseBandwidth parses a bandwidth string like "10M", "1G", "500K" into bytes per second
-func parseBandwidth(s string) (int64, error) {
-	if s == "" {
-		return 0, nil
+	// Independent read, write and operation limits
+	if limit, err := ratelimit.ParseRate(syncFlags.SourceReadLimit); err == nil && syncFlags.SourceReadLimit != "" {
+		cfg.Performance.SourceReadLimit = limit
 	}
+	if limit, err := ratelimit.ParseRate(syncFlags.DestReadLimit); err == nil && syncFlags.DestReadLimit != "" {
+		cfg.Performance.DestReadLimit = limit
+	}
+	if limit, err := ratelimit.ParseRate(syncFlags.WriteLimit); err == nil && syncFlags.WriteLimit != "" {
+		cfg.Performance.WriteLimit = limit
+	}
+	if syncFlags.OpsLimit > 0 {
+		cfg.Performance.OpsLimit = int64(syncFlags.OpsLimit)
+	}
+
+	// Adaptive throttling (bounds imply --adaptive)
+	if syncFlags.Adaptive || syncFlags.AdaptiveWorkers != "" || syncFlags.AdaptiveBandwidth != "" {
+		cfg.Performance.Adaptive.Enabled = true
+	}
+	if minWorkers, maxWorkers, err := parseBounds(syncFlags.AdaptiveWorkers, parseCount); err == nil {
+		cfg.Performance.Adaptive.MinWorkers = int(minWorkers)
+		cfg.Performance.Adaptive.MaxWorkers = int(maxWorkers)
+	}
+	if minBandwidth, maxBandwidth, err := parseBounds(syncFlags.AdaptiveBandwidth, ratelimit.ParseRate); err == nil {
+		cfg.Performance.Adaptive.MinBandwidth = minBandwidth
+		cfg.Performance.Adaptive.MaxBandwidth = maxBandwidth
+	}
+}
 
-	// Pattern: number followed by optional unit (K, M, G, T)
-	re := regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([KMGT])?[Bb]?(?:/[Ss])?$`)
-	matches := re.FindStringSubmatch(strings.TrimSpace(s))
-	if matches == nil {
-		return 0, fmt.Errorf("invalid bandwidth format: %s (use: 10M, 1G, 500K)", s)
+// configureLocal applies the configured symbolic link handling, preserved metadata and durability
+// to local backends
+func configureLocal(cfg *config.Config, backends ...*storage.Local) {
+	// The mode and durability were validated with the configuration
+	mode, _ := storage.ParseLinkMode(cfg.Sync.Links)
+	durability, _ := storage.ParseDurability(cfg.Sync.Durability)
+	for _, backend := range backends {
+		backend.SetLinkMode(mode)
+		backend.SetMetadataOptions(cfg.Sync.Preserve.MetadataOptions())
+		backend.SetDurability(durability)
 	}
+}
 
-	value, err := strconv.ParseFloat(matches[1], 64)
+// parseBounds parses "MIN-MAX" bounds, parsing each value with parse
+func parseBounds(s string, parse func(string) (int64, error)) (int64, int64, error) {
+	minValue, maxValue, ok := strings.Cut(s, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid bounds: %s (use MIN-MAX)", s)
+	}
+	low, err := parse(strings.TrimSpace(minValue))
 	if err != nil {
-		return 0, fmt.Errorf("invalid bandwidth value: %s", matches[1])
+		return 0, 0, err
 	}
-
-	unit := strings.ToUpper(matches[2])
-	var multiplier int64 = 1
-	switch unit {
-	case "K":
-		multiplier = 1024
-	case "M":
-		multiplier = 1024 * 1024
-	case "G":
-		multiplier = 1024 * 1024 * 1024
-	case "T":
-		multiplier = 1024 * 1024 * 1024 * 1024
-	case "":
-		multiplier = 1 // bytes
+	high, err := parse(strings.TrimSpace(maxValue))
+	if err != nil {
+		return 0, 0, err
 	}
+	if low > high {
+		return 0, 0, fmt.Errorf("minimum exceeds maximum: %s", s)
+	}
+	return low, high, nil
+}
 
-	return int64(value * float64(multiplier)), nil
+// parseCount parses a non-negative count
+func parseCount(s string) (int64, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid count: %s", s)
+	}
+	return n, nil
 }
 
 // createSyncOperation creates a sync operation from configuration
@@ -202,6 +444,17 @@ func createSyncOperation(cfg *config.Config) (*models.SyncOperation, error) {
 		excludePatterns = append(excludePatterns, syncFlags.Exclude...)
 	}
 
+	// "auto" is resolved by probing once the storage backends are open
+	modifyWindow, err := config.ParseModifyWindow(cfg.Sync.ModifyWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	adaptive, err := cfg.Performance.Adaptive.Settings(cfg.Performance.MaxWorkers)
+	if err != nil {
+		return nil, err
+	}
+
 	operation := &models.SyncOperation{
 		ID:                 uuid.New().String(),
 		SourcePath:         syncFlags.Source,
@@ -214,8 +467,20 @@ func createSyncOperation(cfg *config.Config) (*models.SyncOperation, error) {
 		DeleteOrphans:      syncFlags.Delete,
 		MaxWorkers:         cfg.Performance.MaxWorkers,
 		BandwidthLimit:     cfg.Performance.BandwidthLimit,
+		BandwidthSchedule:  cfg.Performance.BandwidthSchedule,
+		SourceReadLimit:    cfg.Performance.SourceReadLimit,
+		DestReadLimit:      cfg.Performance.DestReadLimit,
+		WriteLimit:         cfg.Performance.WriteLimit,
+		OpsLimit:           cfg.Performance.OpsLimit,
 		BufferSize:         cfg.Performance.BufferSize,
 		Stateful:           syncFlags.Stateful,
+		Verify:             cfg.Sync.Verify,
+		VerifyRetries:      cfg.Sync.VerifyRetries,
+		ModifyWindow:       modifyWindow,
+		IgnoreHourOffsets:  cfg.Sync.IgnoreHourOffsets,
+		HardLinks:          cfg.Sync.HardLinks,
+		Durability:         cfg.Sync.Durability,
+		Adaptive:           adaptive,
 		CreatedAt:          time.Now(),
 	}
 
@@ -0,0 +1,53 @@
+// Package parallel runs indexed work on a bounded number of goroutines
+package parallel
+
+import (
+	"context"
+	"sync"
+)
+
+// ForEach calls fn for indexes 0..n-1 on up to workers goroutines and returns the first error
+// No new index is started once fn fails or ctx is cancelled
+func ForEach(ctx context.Context, n, workers int, fn func(i int) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+
+	indexes := make(chan int)
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := fn(i); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	var err error
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case indexes <- i:
+		case err = <-errs:
+			break feed
+		case <-ctx.Done():
+			err = ctx.Err()
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	close(errs)
+
+	if err == nil {
+		err = <-errs
+	}
+	return err
+}
@@ -0,0 +1,51 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestForEach(t *testing.T) {
+	t.Run("AllIndexes", func(t *testing.T) {
+		var sum atomic.Int64
+		err := ForEach(context.Background(), 100, 4, func(i int) error {
+			sum.Add(int64(i))
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("ForEach() error = %v", err)
+		}
+		if sum.Load() != 4950 {
+			t.Errorf("sum of indexes = %d, want 4950", sum.Load())
+		}
+	})
+
+	t.Run("FirstError", func(t *testing.T) {
+		failure := errors.New("failed")
+		var calls atomic.Int32
+		err := ForEach(context.Background(), 1000, 2, func(i int) error {
+			calls.Add(1)
+			if i == 3 {
+				return failure
+			}
+			return nil
+		})
+		if !errors.Is(err, failure) {
+			t.Errorf("ForEach() error = %v, want %v", err, failure)
+		}
+		if calls.Load() == 1000 {
+			t.Error("ForEach() kept starting indexes after an error")
+		}
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := ForEach(ctx, 1000, 2, func(i int) error { return nil })
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("ForEach() error = %v, want context.Canceled", err)
+		}
+	})
+}
@@ -218,11 +218,74 @@ func (c *BinaryComparator) Compare(ctx context.Context, source, dest storage.Bac
 
 	// Files are identical
 	return &Comparison{
-		Result: Same,
-		Reason: fmt.Sprintf("binary content matches (%d bytes)", bytesCompared),
+		Result:          Same,
+		Reason:          fmt.Sprintf("binary content matches (%d bytes)", bytesCompared),
+		ContentVerified: true,
 	}, nil
 }
 
+// FirstDifference returns the offset of the first byte where two files differ, or -1 if they are identical
+// When one file is a prefix of the other, the offset is the length of the shorter file
+func (c *BinaryComparator) FirstDifference(ctx context.Context, source, dest storage.Backend, sourcePath, destPath string) (int64, error) {
+	sourceReader, err := source.Read(ctx, sourcePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open source file: %w", err)
+	}
+	defer sourceReader.Close()
+
+	destReader, err := dest.Read(ctx, destPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open destination file: %w", err)
+	}
+	defer destReader.Close()
+
+	var sourceReaderWrapped io.Reader = sourceReader
+	var destReaderWrapped io.Reader = destReader
+	if c.readerWrapper != nil {
+		sourceReaderWrapped = c.readerWrapper(sourceReader)
+		destReaderWrapped = c.readerWrapper(destReader)
+	}
+
+	sourceBufPtr := c.bufferPool.Get().(*[]byte)
+	defer c.bufferPool.Put(sourceBufPtr)
+	sourceBuf := *sourceBufPtr
+
+	destBufPtr := c.bufferPool.Get().(*[]byte)
+	defer c.bufferPool.Put(destBufPtr)
+	destBuf := *destBufPtr
+
+	var offset int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+
+		// ReadFull keeps both buffers aligned even when the readers return short reads
+		sourceN, sourceErr := io.ReadFull(sourceReaderWrapped, sourceBuf)
+		if sourceErr != nil && sourceErr != io.EOF && sourceErr != io.ErrUnexpectedEOF {
+			return 0, fmt.Errorf("failed to read source: %w", sourceErr)
+		}
+		destN, destErr := io.ReadFull(destReaderWrapped, destBuf)
+		if destErr != nil && destErr != io.EOF && destErr != io.ErrUnexpectedEOF {
+			return 0, fmt.Errorf("failed to read destination: %w", destErr)
+		}
+
+		n := min(sourceN, destN)
+		for i := 0; i < n; i++ {
+			if sourceBuf[i] != destBuf[i] {
+				return offset + int64(i), nil
+			}
+		}
+		if sourceN != destN {
+			return offset + int64(n), nil
+		}
+		if sourceN < len(sourceBuf) {
+			return -1, nil
+		}
+		offset += int64(n)
+	}
+}
+
 // Name returns the comparator name
 func (c *BinaryComparator) Name() string {
 	return "binary"
@@ -0,0 +1,115 @@
+package compare
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/sdejongh/syncnorris/pkg/storage"
+	"github.com/zeebo/xxh3"
+	"lukechampine.com/blake3"
+)
+
+// Hash algorithm names recorded in hash caches and reports
+const (
+	AlgorithmSHA256 = "sha256"
+	AlgorithmMD5    = "md5"
+	AlgorithmBLAKE3 = "blake3"
+	AlgorithmXXH3   = "xxh3"
+	AlgorithmXXH128 = "xxh128"
+)
+
+// NewHasher returns a new hash.Hash for the named algorithm
+func NewHasher(algorithm string) (hash.Hash, error) {
+	switch algorithm {
+	case AlgorithmSHA256:
+		return sha256.New(), nil
+	case AlgorithmMD5:
+		return md5.New(), nil
+	case AlgorithmBLAKE3:
+		return blake3.New(32, nil), nil
+	case AlgorithmXXH3:
+		return xxh3.New(), nil
+	case AlgorithmXXH128:
+		return xxh3.New128(), nil
+	default:
+		return nil, fmt.Errorf("unsupported hash algorithm: %s", algorithm)
+	}
+}
+
+// cachedHash returns the cached hash of a file if its backend carries a hash cache
+func cachedHash(backend storage.Backend, info *storage.FileInfo, algorithm string) (string, bool) {
+	hc, ok := backend.(storage.HashCacher)
+	if !ok || hc.HashCache() == nil || info == nil {
+		return "", false
+	}
+	return hc.HashCache().Lookup(info.CacheKey(), algorithm)
+}
+
+// storeHash records a computed hash in the backend's hash cache
+// Failures are ignored: the cache is only an optimization and may be read-only
+func storeHash(backend storage.Backend, info *storage.FileInfo, algorithm, hash string) {
+	hc, ok := backend.(storage.HashCacher)
+	if !ok || hc.HashCache() == nil || info == nil {
+		return
+	}
+	hc.HashCache().Store(info.CacheKey(), algorithm, hash)
+}
+
+// HashFile returns the hex-encoded hash of a file, using and filling the backend's hash cache
+// The optional wrapper is applied to the file reader (e.g., for rate limiting)
+func HashFile(ctx context.Context, backend storage.Backend, info *storage.FileInfo, algorithm string, wrapper ReaderWrapper) (string, error) {
+	if cached, ok := cachedHash(backend, info, algorithm); ok {
+		return cached, nil
+	}
+
+	sum, err := HashContent(ctx, backend, info.RelativePath, algorithm, wrapper)
+	if err != nil {
+		return "", err
+	}
+	storeHash(backend, info, algorithm, sum)
+
+	return sum, nil
+}
+
+// HashContent returns the hex-encoded hash of a file read from the backend, bypassing the hash cache
+// Used where a cached hash would hide corruption that keeps the size and modification time
+func HashContent(ctx context.Context, backend storage.Backend, path, algorithm string, wrapper ReaderWrapper) (string, error) {
+	hasher, err := NewHasher(algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	reader, err := backend.Read(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer reader.Close()
+
+	if wrapper != nil {
+		reader = wrapper(reader)
+	}
+
+	if _, err := io.Copy(hasher, contextReader{ctx: ctx, r: reader}); err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return fmt.Sprintf("%x", hasher.Sum(nil)), nil
+}
+
+// contextReader stops reading once its context is cancelled
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// Read reads from the underlying reader unless the context is done
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
@@ -0,0 +1,324 @@
+package compare
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+// Stage is one step of a comparison chain
+// A stage returns Same or Different when it can decide, or Undecided to let the next stage decide
+type Stage interface {
+	// Evaluate compares two files whose metadata has already been read
+	Evaluate(ctx context.Context, source, dest storage.Backend, sourcePath, destPath string, sourceInfo, destInfo *storage.FileInfo) (*Comparison, error)
+
+	// Name returns the stage name shown in reports (e.g., "full-hash(blake3)")
+	Name() string
+}
+
+// chainStage is a stage with the file size range it runs for
+type chainStage struct {
+	stage   Stage
+	minSize int64 // Run only for files of at least minSize bytes (0 = no minimum)
+	maxSize int64 // Run only for files of at most maxSize bytes (0 = no maximum)
+}
+
+// applies reports whether the stage runs for a file of the given size
+func (s chainStage) applies(size int64) bool {
+	return size >= s.minSize && (s.maxSize == 0 || size <= s.maxSize)
+}
+
+// ChainComparator compares files with a configurable sequence of stages
+// Stages run in order until one decides; files no stage could decide are considered different
+type ChainComparator struct {
+	stages []chainStage
+}
+
+// ParseChain creates a comparison chain from stage specifications
+// Each specification is a stage name with optional arguments, e.g.:
+//
+//	size
+//	mtime(window=2s, ignore_hour_offsets=true, min_size=1G)
+//	partial-hash(xxh3, bytes=1M)
+//	full-hash(blake3, max_size=1G)
+//	binary
+//
+// Every stage accepts min_size and max_size to restrict it to a range of file sizes
+func ParseChain(specs []string, bufferSize int) (*ChainComparator, error) {
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("comparison chain has no stages")
+	}
+
+	c := &ChainComparator{}
+	for _, spec := range specs {
+		stage, err := parseStage(spec, bufferSize)
+		if err != nil {
+			return nil, err
+		}
+		c.stages = append(c.stages, stage)
+	}
+	return c, nil
+}
+
+// SplitChain splits a comma separated chain ("size, mtime(window=2s), full-hash") into stage
+// specifications, leaving the commas between a stage's arguments alone
+func SplitChain(s string) []string {
+	var specs []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				specs = append(specs, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" || len(specs) > 0 {
+		specs = append(specs, last)
+	}
+	return specs
+}
+
+// parseStage parses one stage specification
+func parseStage(spec string, bufferSize int) (chainStage, error) {
+	name, positional, options, err := parseStageSpec(spec)
+	if err != nil {
+		return chainStage{}, err
+	}
+
+	var cs chainStage
+	if v, ok := options["min_size"]; ok {
+		if cs.minSize, err = parseSize(v); err != nil {
+			return chainStage{}, fmt.Errorf("stage %s: invalid min_size: %w", name, err)
+		}
+		delete(options, "min_size")
+	}
+	if v, ok := options["max_size"]; ok {
+		if cs.maxSize, err = parseSize(v); err != nil {
+			return chainStage{}, fmt.Errorf("stage %s: invalid max_size: %w", name, err)
+		}
+		delete(options, "max_size")
+	}
+
+	switch name {
+	case "size":
+		cs.stage = &sizeStage{}
+
+	case "mtime":
+		stage := &mtimeStage{tolerance: TimeTolerance{Window: DefaultModifyWindow}}
+		if v, ok := options["window"]; ok {
+			if stage.tolerance.Window, err = time.ParseDuration(v); err != nil || stage.tolerance.Window < 0 {
+				return chainStage{}, fmt.Errorf("stage mtime: invalid window: %s", v)
+			}
+			delete(options, "window")
+		}
+		if v, ok := options["ignore_hour_offsets"]; ok {
+			if stage.tolerance.IgnoreHourOffsets, err = strconv.ParseBool(v); err != nil {
+				return chainStage{}, fmt.Errorf("stage mtime: invalid ignore_hour_offsets: %s", v)
+			}
+			delete(options, "ignore_hour_offsets")
+		}
+		cs.stage = stage
+
+	case "partial-hash":
+		algorithm := AlgorithmXXH3
+		if positional != "" {
+			algorithm = positional
+		}
+		if _, err := NewHasher(algorithm); err != nil {
+			return chainStage{}, fmt.Errorf("stage partial-hash: %w", err)
+		}
+		stage := &partialHashStage{digest: newDigestComparator(algorithm, bufferSize), bytes: partialHashSize}
+		if v, ok := options["bytes"]; ok {
+			if stage.bytes, err = parseSize(v); err != nil || stage.bytes <= 0 {
+				return chainStage{}, fmt.Errorf("stage partial-hash: invalid bytes: %s", v)
+			}
+			delete(options, "bytes")
+		}
+		cs.stage = stage
+
+	case "full-hash":
+		algorithm := AlgorithmSHA256
+		if positional != "" {
+			algorithm = positional
+		}
+		if _, err := NewHasher(algorithm); err != nil {
+			return chainStage{}, fmt.Errorf("stage full-hash: %w", err)
+		}
+		digest := newDigestComparator(algorithm, bufferSize)
+		digest.parallel = algorithm == AlgorithmBLAKE3
+		digest.SetPartialHashEnabled(false)
+		cs.stage = &fullHashStage{digest: digest}
+
+	case "binary":
+		cs.stage = &binaryStage{binary: NewBinaryComparator(bufferSize)}
+
+	default:
+		return chainStage{}, fmt.Errorf("unknown comparison stage: %s (valid: size, mtime, partial-hash, full-hash, binary)", name)
+	}
+
+	if positional != "" && name != "partial-hash" && name != "full-hash" {
+		return chainStage{}, fmt.Errorf("stage %s: unexpected argument: %s", name, positional)
+	}
+	if len(options) > 0 {
+		keys := make([]string, 0, len(options))
+		for key := range options {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return chainStage{}, fmt.Errorf("stage %s: unknown option: %s", name, strings.Join(keys, ", "))
+	}
+	return cs, nil
+}
+
+// parseStageSpec splits "name(arg, key=value, ...)" into its parts
+func parseStageSpec(spec string) (name, positional string, options map[string]string, err error) {
+	spec = strings.TrimSpace(spec)
+	options = make(map[string]string)
+
+	open := strings.IndexByte(spec, '(')
+	if open < 0 {
+		return strings.ToLower(spec), "", options, nil
+	}
+	if !strings.HasSuffix(spec, ")") {
+		return "", "", nil, fmt.Errorf("invalid comparison stage: %s (missing closing parenthesis)", spec)
+	}
+
+	name = strings.ToLower(strings.TrimSpace(spec[:open]))
+	for _, arg := range strings.Split(spec[open+1:len(spec)-1], ",") {
+		arg = strings.TrimSpace(arg)
+		if arg == "" {
+			continue
+		}
+		key, value, found := strings.Cut(arg, "=")
+		if !found {
+			if positional != "" {
+				return "", "", nil, fmt.Errorf("stage %s: too many arguments", name)
+			}
+			positional = strings.ToLower(arg)
+			continue
+		}
+		options[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+	return name, positional, options, nil
+}
+
+// parseSize parses a size such as "512", "256K", "1G" (binary units)
+func parseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSuffix(strings.TrimSpace(s), "B"))
+	multiplier := int64(1)
+	if n := len(s); n > 0 {
+		switch s[n-1] {
+		case 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		case 'T':
+			multiplier = 1 << 40
+		}
+		if multiplier > 1 {
+			s = s[:n-1]
+		}
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size: %s", s)
+	}
+	return int64(value * float64(multiplier)), nil
+}
+
+// Compare runs the stages in order until one decides
+func (c *ChainComparator) Compare(ctx context.Context, source, dest storage.Backend, sourcePath, destPath string) (*Comparison, error) {
+	sourceInfo, err := source.Stat(ctx, sourcePath)
+	if err != nil {
+		return &Comparison{
+			SourcePath: sourcePath,
+			DestPath:   destPath,
+			Result:     SourceOnly,
+			Reason:     "file exists only in source",
+		}, nil
+	}
+
+	destInfo, err := dest.Stat(ctx, destPath)
+	if err != nil {
+		return &Comparison{
+			SourcePath: sourcePath,
+			DestPath:   destPath,
+			Result:     DestOnly,
+			Reason:     "file exists only in destination",
+		}, nil
+	}
+
+	for _, cs := range c.stages {
+		if !cs.applies(sourceInfo.Size) {
+			continue
+		}
+
+		result, err := cs.stage.Evaluate(ctx, source, dest, sourcePath, destPath, sourceInfo, destInfo)
+		if err != nil {
+			return result, err
+		}
+		if result.Result == Undecided {
+			continue
+		}
+
+		result.SourcePath = sourcePath
+		result.DestPath = destPath
+		result.Stage = cs.stage.Name()
+		result.Reason = fmt.Sprintf("%s: %s", result.Stage, result.Reason)
+		return result, nil
+	}
+
+	return &Comparison{
+		SourcePath: sourcePath,
+		DestPath:   destPath,
+		Result:     Different,
+		Reason:     "no comparison stage could decide",
+	}, nil
+}
+
+// SetProgressCallback sets the progress reporting callback of the stages that read content
+func (c *ChainComparator) SetProgressCallback(callback func(path string, current, total int64)) {
+	for _, cs := range c.stages {
+		if s, ok := cs.stage.(interface {
+			SetProgressCallback(func(path string, current, total int64))
+		}); ok {
+			s.SetProgressCallback(callback)
+		}
+	}
+}
+
+// SetReaderWrapper sets a function to wrap readers (e.g., for rate limiting)
+func (c *ChainComparator) SetReaderWrapper(wrapper ReaderWrapper) {
+	for _, cs := range c.stages {
+		if s, ok := cs.stage.(interface{ SetReaderWrapper(ReaderWrapper) }); ok {
+			s.SetReaderWrapper(wrapper)
+		}
+	}
+}
+
+// Stages returns the names of the chain's stages, in order
+func (c *ChainComparator) Stages() []string {
+	names := make([]string, len(c.stages))
+	for i, cs := range c.stages {
+		names[i] = cs.stage.Name()
+	}
+	return names
+}
+
+// Name returns the comparator name
+func (c *ChainComparator) Name() string {
+	return "chain"
+}
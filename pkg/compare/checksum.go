@@ -0,0 +1,104 @@
+package compare
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+// ChecksumComparator compares destination files against hashes recorded by the source backend
+// The source must implement storage.Checksummer (e.g., a checksum manifest); its content is never read
+type ChecksumComparator struct {
+	progressReport func(path string, current, total int64) // Optional progress callback
+	readerWrapper  ReaderWrapper                           // Optional reader wrapper (e.g., for rate limiting)
+}
+
+// NewChecksumComparator creates a comparator that checks destination files against recorded hashes
+func NewChecksumComparator() *ChecksumComparator {
+	return &ChecksumComparator{}
+}
+
+// SetProgressCallback sets the progress reporting callback
+func (c *ChecksumComparator) SetProgressCallback(callback func(path string, current, total int64)) {
+	c.progressReport = callback
+}
+
+// SetReaderWrapper sets a function to wrap readers (e.g., for rate limiting)
+func (c *ChecksumComparator) SetReaderWrapper(wrapper ReaderWrapper) {
+	c.readerWrapper = wrapper
+}
+
+// Compare hashes the destination file with the recorded algorithm and compares it with the recorded hash
+func (c *ChecksumComparator) Compare(ctx context.Context, source, dest storage.Backend, sourcePath, destPath string) (*Comparison, error) {
+	checksummer, ok := source.(storage.Checksummer)
+	if !ok {
+		return nil, fmt.Errorf("source does not provide checksums")
+	}
+
+	expected, ok := checksummer.Checksum(sourcePath)
+	if !ok {
+		return &Comparison{
+			SourcePath: sourcePath,
+			DestPath:   destPath,
+			Result:     DestOnly,
+			Reason:     "file exists only in destination",
+		}, nil
+	}
+
+	destInfo, err := dest.Stat(ctx, destPath)
+	if err != nil {
+		return &Comparison{
+			SourcePath: sourcePath,
+			DestPath:   destPath,
+			Result:     SourceOnly,
+			Reason:     "file exists only in source",
+		}, nil
+	}
+
+	// Sizes are only recorded by some checksum formats
+	if expected.Size >= 0 && expected.Size != destInfo.Size {
+		return &Comparison{
+			SourcePath: sourcePath,
+			DestPath:   destPath,
+			Result:     Different,
+			Reason:     "file sizes differ",
+		}, nil
+	}
+
+	actual, err := HashFile(ctx, dest, destInfo, expected.Algorithm, c.readerWrapper)
+	if err != nil {
+		return &Comparison{
+			SourcePath: sourcePath,
+			DestPath:   destPath,
+			Result:     Error,
+			Reason:     "failed to compute destination hash",
+			Error:      err,
+		}, err
+	}
+	if c.progressReport != nil {
+		c.progressReport(destPath, destInfo.Size, destInfo.Size)
+	}
+
+	result := &Comparison{
+		SourcePath:    sourcePath,
+		DestPath:      destPath,
+		Result:        Same,
+		Reason:        fmt.Sprintf("%s hash matches checksum", expected.Algorithm),
+		HashAlgorithm: expected.Algorithm,
+		SourceHash:    expected.Hash,
+		DestHash:      actual,
+
+		ContentVerified: true,
+	}
+	if actual != expected.Hash {
+		result.Result = Different
+		result.Reason = fmt.Sprintf("%s hash differs from checksum", expected.Algorithm)
+	}
+	return result, nil
+}
+
+// Name returns the comparator name
+func (c *ChecksumComparator) Name() string {
+	return "checksum"
+}
@@ -15,12 +15,16 @@ const (
 	Same Result = "same"
 	// Different indicates files differ
 	Different Result = "different"
+	// MetadataDiffers indicates files have identical content but different metadata
+	MetadataDiffers Result = "metadata_differs"
 	// SourceOnly indicates file exists only in source
 	SourceOnly Result = "source_only"
 	// DestOnly indicates file exists only in destination
 	DestOnly Result = "dest_only"
 	// Error indicates comparison failed
 	Error Result = "error"
+	// Undecided indicates a comparison chain stage could not tell whether files are identical
+	Undecided Result = "undecided"
 )
 
 // Comparison holds the result of comparing two files
@@ -30,6 +34,24 @@ type Comparison struct {
 	Result     Result
 	Reason     string
 	Error      error
+
+	// Full content hashes, set by hash-based comparators when both files were hashed
+	HashAlgorithm string
+	SourceHash    string
+	DestHash      string
+
+	// Stage is the comparison chain stage that decided the result (empty for other comparators)
+	Stage string
+
+	// Details describes a difference in more depth (e.g., a unified diff excerpt of text files)
+	Details string
+
+	// MetadataDiffs lists the metadata differing when Result is MetadataDiffers (e.g. "permissions", "mtime")
+	MetadataDiffs []string
+
+	// ContentVerified is set when the result was decided by reading the content of both files (or
+	// the targets of both links), rather than trusted from their size and modification time
+	ContentVerified bool
 }
 
 // Comparator defines the interface for file comparison algorithms
@@ -1,12 +1,16 @@
 package compare
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/sdejongh/syncnorris/pkg/hashcache"
 	"github.com/sdejongh/syncnorris/pkg/storage"
 )
 
@@ -314,6 +318,79 @@ func TestTimestampComparator(t *testing.T) {
 	})
 }
 
+// TestTimestampComparatorWithTolerance tests FAT-style timestamps against a wider modify window
+func TestTimestampComparatorWithTolerance(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	ctx := context.Background()
+	content := []byte("content")
+	h.CreateSourceFile("fat.txt", content)
+	h.CreateDestFile("fat.txt", content)
+
+	// The FAT destination rounded the time up to an even second, then DST shifted it by an hour
+	modTime := time.Date(2025, 3, 1, 10, 0, 5, 700000000, time.UTC)
+	h.SetFileModTime(true, "fat.txt", modTime.Add(time.Hour))
+	h.SetFileModTime(false, "fat.txt", time.Date(2025, 3, 1, 10, 0, 4, 0, time.UTC))
+
+	tests := []struct {
+		name      string
+		tolerance TimeTolerance
+		want      Result
+	}{
+		{"default window", TimeTolerance{Window: DefaultModifyWindow}, Different},
+		{"window only", TimeTolerance{Window: 2 * time.Second}, Different},
+		{"window and hour offsets", TimeTolerance{Window: 2 * time.Second, IgnoreHourOffsets: true}, Same},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comparator := NewTimestampComparatorWithTolerance(tt.tolerance)
+			result, err := comparator.Compare(ctx, h.source, h.dest, "fat.txt", "fat.txt")
+			if err != nil {
+				t.Fatalf("Compare() error = %v", err)
+			}
+			if result.Result != tt.want {
+				t.Errorf("Result = %s, want %s", result.Result, tt.want)
+			}
+		})
+	}
+}
+
+// TestTimeTolerance tests modify windows and hour offsets
+func TestTimeTolerance(t *testing.T) {
+	base := time.Date(2025, 10, 26, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		tolerance TimeTolerance
+		diff      time.Duration
+		equal     bool
+	}{
+		{"exact", TimeTolerance{}, 0, true},
+		{"exact rejects nanoseconds", TimeTolerance{}, time.Nanosecond, false},
+		{"within window", TimeTolerance{Window: 2 * time.Second}, 2 * time.Second, true},
+		{"outside window", TimeTolerance{Window: 2 * time.Second}, 3 * time.Second, false},
+		{"hour offset not ignored", TimeTolerance{Window: time.Second}, time.Hour, false},
+		{"hour offset", TimeTolerance{Window: time.Second, IgnoreHourOffsets: true}, time.Hour, true},
+		{"negative hour offset", TimeTolerance{Window: time.Second, IgnoreHourOffsets: true}, -2 * time.Hour, true},
+		{"hour offset within window", TimeTolerance{Window: 2 * time.Second, IgnoreHourOffsets: true}, time.Hour - 2*time.Second, true},
+		{"half hour offset", TimeTolerance{Window: time.Second, IgnoreHourOffsets: true}, 30 * time.Minute, false},
+		{"beyond timezone offsets", TimeTolerance{Window: time.Second, IgnoreHourOffsets: true}, 27 * time.Hour, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tolerance.Equal(base.Add(tt.diff), base); got != tt.equal {
+				t.Errorf("Equal() = %v, want %v", got, tt.equal)
+			}
+			if got := tt.tolerance.After(base.Add(tt.diff), base); got != (tt.diff > 0 && !tt.equal) {
+				t.Errorf("After() = %v, want %v", got, tt.diff > 0 && !tt.equal)
+			}
+		})
+	}
+}
+
 // TestHashComparator tests the SHA-256 hash comparator
 func TestHashComparator(t *testing.T) {
 	h := NewTestHelper(t)
@@ -425,6 +502,161 @@ func TestHashComparator(t *testing.T) {
 	})
 }
 
+// TestDigestComparator tests the BLAKE3 and xxHash comparators
+func TestDigestComparator(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	ctx := context.Background()
+
+	// Digests of "abc" from the reference implementations
+	tests := []struct {
+		comparator *DigestComparator
+		algorithm  string
+		digest     string
+	}{
+		{NewBLAKE3Comparator(4096), AlgorithmBLAKE3, "6437b3ac38465133ffb63b75273a8db548c558465d79db03fd359c6cd5bd9d85"},
+		{NewXXH3Comparator(4096), AlgorithmXXH3, "78af5f94892f3950"},
+		{NewXXH128Comparator(4096), AlgorithmXXH128, "06b05ab6733a618578af5f94892f3950"},
+	}
+
+	for _, tt := range tests {
+		comparator := tt.comparator
+		t.Run(tt.algorithm, func(t *testing.T) {
+			if comparator.Name() != tt.algorithm {
+				t.Errorf("Name() = %s, want %s", comparator.Name(), tt.algorithm)
+			}
+
+			name := tt.algorithm + "_identical.txt"
+			h.CreateSourceFile(name, []byte("abc"))
+			h.CreateDestFile(name, []byte("abc"))
+
+			result, err := comparator.Compare(ctx, h.source, h.dest, name, name)
+			if err != nil {
+				t.Fatalf("Compare() error = %v", err)
+			}
+			if result.Result != Same {
+				t.Errorf("Result = %s, want %s", result.Result, Same)
+			}
+			if result.HashAlgorithm != tt.algorithm {
+				t.Errorf("HashAlgorithm = %s, want %s", result.HashAlgorithm, tt.algorithm)
+			}
+			if result.SourceHash != tt.digest || result.DestHash != tt.digest {
+				t.Errorf("hashes = %s/%s, want %s", result.SourceHash, result.DestHash, tt.digest)
+			}
+
+			name = tt.algorithm + "_diff.txt"
+			h.CreateSourceFile(name, []byte("abcdefgh"))
+			h.CreateDestFile(name, []byte("12345678"))
+
+			result, err = comparator.Compare(ctx, h.source, h.dest, name, name)
+			if err != nil {
+				t.Fatalf("Compare() error = %v", err)
+			}
+			if result.Result != Different {
+				t.Errorf("Result = %s, want %s", result.Result, Different)
+			}
+		})
+	}
+
+	t.Run("LargeFile", func(t *testing.T) {
+		// Large enough for parallel BLAKE3 reads, differing only in the last byte
+		// so the partial hash matches and the full hash decides
+		content := make([]byte, parallelHashThreshold+123)
+		for i := range content {
+			content[i] = byte(i % 251)
+		}
+		h.CreateSourceFile("large.bin", content)
+		content[len(content)-1] ^= 0xff
+		h.CreateDestFile("large.bin", content)
+
+		var progressCalls int
+		var lastCurrent int64
+		comparator := NewBLAKE3Comparator(4096)
+		comparator.SetProgressCallback(func(path string, current, total int64) {
+			progressCalls++
+			lastCurrent = current
+		})
+
+		result, err := comparator.Compare(ctx, h.source, h.dest, "large.bin", "large.bin")
+		if err != nil {
+			t.Fatalf("Compare() error = %v", err)
+		}
+		if result.Result != Different {
+			t.Errorf("Result = %s, want %s", result.Result, Different)
+		}
+		if result.Reason != "blake3 hashes differ" {
+			t.Errorf("Reason = %s, want full hash mismatch", result.Reason)
+		}
+
+		hasher, _ := NewHasher(AlgorithmBLAKE3)
+		hasher.Write(content)
+		if want := fmt.Sprintf("%x", hasher.Sum(nil)); result.DestHash != want {
+			t.Errorf("DestHash = %s, want %s", result.DestHash, want)
+		}
+		if progressCalls == 0 || lastCurrent != int64(len(content)) {
+			t.Errorf("progress calls = %d, last = %d, want final report of %d", progressCalls, lastCurrent, len(content))
+		}
+	})
+}
+
+// TestHashComparatorHashCache tests that cached hashes are filled in and reused
+func TestHashComparatorHashCache(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	ctx := context.Background()
+	content := []byte("content hashed once")
+	h.CreateSourceFile("cached.txt", content)
+	h.CreateDestFile("cached.txt", content)
+
+	comparators := []Comparator{
+		NewHashComparator(4096),
+		NewMD5Comparator(4096),
+		NewBLAKE3Comparator(4096),
+		NewXXH3Comparator(4096),
+		NewXXH128Comparator(4096),
+	}
+	for _, comparator := range comparators {
+		algorithm := comparator.Name()
+		if comparator.Name() == "hash" {
+			algorithm = AlgorithmSHA256
+		}
+
+		t.Run(comparator.Name(), func(t *testing.T) {
+			sourceCache, _ := hashcache.OpenDB(filepath.Join(h.tempDir, comparator.Name()+"-source.db"), "source")
+			destCache, _ := hashcache.OpenDB(filepath.Join(h.tempDir, comparator.Name()+"-dest.db"), "dest")
+			h.source.SetHashCache(sourceCache)
+			h.dest.SetHashCache(destCache)
+			defer h.source.SetHashCache(nil)
+			defer h.dest.SetHashCache(nil)
+
+			result, err := comparator.Compare(ctx, h.source, h.dest, "cached.txt", "cached.txt")
+			if err != nil {
+				t.Fatalf("Compare() error = %v", err)
+			}
+			if result.Result != Same {
+				t.Errorf("Result = %s, want %s", result.Result, Same)
+			}
+
+			destInfo, _ := h.dest.Stat(ctx, "cached.txt")
+			if _, ok := destCache.Lookup(destInfo.CacheKey(), algorithm); !ok {
+				t.Fatal("hash should have been stored in the destination cache")
+			}
+
+			// A poisoned cache entry proves the cached value is used instead of rehashing
+			destCache.Store(destInfo.CacheKey(), algorithm, "poisoned")
+			result, err = comparator.Compare(ctx, h.source, h.dest, "cached.txt", "cached.txt")
+			if err != nil {
+				t.Fatalf("Compare() error = %v", err)
+			}
+			if result.Result != Different {
+				t.Errorf("Result = %s, want %s (cached hash not used)", result.Result, Different)
+			}
+		})
+	}
+}
+
 // TestBinaryComparator tests the byte-by-byte comparator
 func TestBinaryComparator(t *testing.T) {
 	h := NewTestHelper(t)
@@ -509,13 +741,368 @@ func TestBinaryComparator(t *testing.T) {
 	})
 }
 
+// TestBinaryComparatorFirstDifference tests locating the first differing byte
+func TestBinaryComparatorFirstDifference(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	ctx := context.Background()
+
+	// Larger than the buffer so the difference is found in a later read
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	changed := append([]byte(nil), data...)
+	changed[5000] = 'x'
+
+	h.CreateSourceFile("same.bin", data)
+	h.CreateDestFile("same.bin", data)
+	h.CreateSourceFile("changed.bin", data)
+	h.CreateDestFile("changed.bin", changed)
+	h.CreateSourceFile("truncated.bin", data)
+	h.CreateDestFile("truncated.bin", data[:4096])
+
+	tests := []struct {
+		path string
+		want int64
+	}{
+		{"same.bin", -1},
+		{"changed.bin", 5000},
+		{"truncated.bin", 4096},
+	}
+
+	comparator := NewBinaryComparator(4096)
+	for _, tt := range tests {
+		got, err := comparator.FirstDifference(ctx, h.source, h.dest, tt.path, tt.path)
+		if err != nil {
+			t.Fatalf("FirstDifference(%s) error = %v", tt.path, err)
+		}
+		if got != tt.want {
+			t.Errorf("FirstDifference(%s) = %d, want %d", tt.path, got, tt.want)
+		}
+	}
+}
+
+// TestDescribe tests describing differences of text and binary files
+func TestDescribe(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	ctx := context.Background()
+
+	h.CreateSourceFile("app.conf", []byte("a = 1\nb = 2\n"))
+	h.CreateDestFile("app.conf", []byte("a = 1\nb = 3\n"))
+	h.CreateSourceFile("image.bin", []byte("\x00\x01\x02\x03"))
+	h.CreateDestFile("image.bin", []byte("\x00\x01\xff\x03"))
+
+	description, err := Describe(ctx, h.source, h.dest, "app.conf", "app.conf", DefaultMaxDiffSize, 4096, nil)
+	if err != nil {
+		t.Fatalf("Describe() error = %v", err)
+	}
+	want := "--- dest/app.conf\n+++ source/app.conf\n@@ -1,2 +1,2 @@\n a = 1\n-b = 3\n+b = 2\n"
+	if description.Diff != want || description.Offset != -1 {
+		t.Errorf("Describe(app.conf) = %q, offset %d; want %q", description.Diff, description.Offset, want)
+	}
+
+	description, err = Describe(ctx, h.source, h.dest, "image.bin", "image.bin", DefaultMaxDiffSize, 4096, nil)
+	if err != nil {
+		t.Fatalf("Describe() error = %v", err)
+	}
+	if description.Diff != "" || description.Offset != 2 {
+		t.Errorf("Describe(image.bin) = %q, offset %d; want offset 2", description.Diff, description.Offset)
+	}
+
+	// Text files over the size limit get an offset instead of a diff
+	description, err = Describe(ctx, h.source, h.dest, "app.conf", "app.conf", 4, 4096, nil)
+	if err != nil {
+		t.Fatalf("Describe() error = %v", err)
+	}
+	if description.Diff != "" || description.Offset != 10 {
+		t.Errorf("Describe(app.conf, maxSize 4) = %q, offset %d; want offset 10", description.Diff, description.Offset)
+	}
+
+	// Every reader goes through the wrapper, for diffs and offsets alike
+	wrapped := 0
+	wrapper := func(rc io.ReadCloser) io.ReadCloser {
+		wrapped++
+		return rc
+	}
+	if _, err := Describe(ctx, h.source, h.dest, "app.conf", "app.conf", DefaultMaxDiffSize, 4096, wrapper); err != nil {
+		t.Fatalf("Describe() error = %v", err)
+	}
+	if _, err := Describe(ctx, h.source, h.dest, "image.bin", "image.bin", DefaultMaxDiffSize, 4096, wrapper); err != nil {
+		t.Fatalf("Describe() error = %v", err)
+	}
+	// image.bin is read twice per side: once to test for text, once to find the offset
+	if wrapped != 6 {
+		t.Errorf("Describe() wrapped %d readers, want 6", wrapped)
+	}
+}
+
+// TestChainComparator tests stage ordering, undecided stages and size thresholds
+func TestChainComparator(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	ctx := context.Background()
+	modTime := time.Now().Add(-time.Hour)
+
+	// Same size and time but different content: only content stages notice
+	h.CreateSourceFile("touched.txt", []byte("content A"))
+	h.CreateDestFile("touched.txt", []byte("content B"))
+	h.SetFileModTime(true, "touched.txt", modTime)
+	h.SetFileModTime(false, "touched.txt", modTime.Add(1500*time.Millisecond))
+
+	// Identical content, different times
+	h.CreateSourceFile("copied.txt", []byte("identical"))
+	h.CreateDestFile("copied.txt", []byte("identical"))
+	h.SetFileModTime(true, "copied.txt", modTime)
+	h.SetFileModTime(false, "copied.txt", modTime.Add(time.Minute))
+
+	h.CreateSourceFile("grown.txt", []byte("short"))
+	h.CreateDestFile("grown.txt", []byte("longer content"))
+
+	tests := []struct {
+		name      string
+		chain     string
+		path      string
+		want      Result
+		wantStage string
+	}{
+		{"size decides", "size, full-hash", "grown.txt", Different, "size"},
+		{"mtime trusted", "size, mtime(window=2s), full-hash(blake3)", "touched.txt", Same, "mtime"},
+		{"mtime window too small", "size, mtime(window=1s), full-hash(blake3)", "touched.txt", Different, "full-hash(blake3)"},
+		{"mtime only above threshold", "size, mtime(window=2s, min_size=1K), full-hash(xxh128)", "touched.txt", Different, "full-hash(xxh128)"},
+		{"hash skipped above threshold", "size, full-hash(max_size=4)", "copied.txt", Different, ""},
+		{"partial hash decides small files", "size, partial-hash(xxh3), binary", "touched.txt", Different, "partial-hash(xxh3)"},
+		{"partial hash covers whole file", "size, partial-hash", "copied.txt", Same, "partial-hash(xxh3)"},
+		{"binary", "size, mtime, binary", "copied.txt", Same, "binary"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comparator, err := ParseChain(SplitChain(tt.chain), 4096)
+			if err != nil {
+				t.Fatalf("ParseChain() error = %v", err)
+			}
+			result, err := comparator.Compare(ctx, h.source, h.dest, tt.path, tt.path)
+			if err != nil {
+				t.Fatalf("Compare() error = %v", err)
+			}
+			if result.Result != tt.want || result.Stage != tt.wantStage {
+				t.Errorf("Compare() = %s by %q (%s), want %s by %q", result.Result, result.Stage, result.Reason, tt.want, tt.wantStage)
+			}
+		})
+	}
+}
+
+// TestParseChain tests comparison chain specifications
+func TestParseChain(t *testing.T) {
+	if got := SplitChain("size, mtime(window=2s, min_size=1G), full-hash(blake3)"); len(got) != 3 || got[1] != "mtime(window=2s, min_size=1G)" {
+		t.Errorf("SplitChain() = %q", got)
+	}
+
+	valid, err := ParseChain([]string{"size", "MTIME(window=500ms, ignore_hour_offsets=true)", "partial-hash(md5, bytes=1M)", "full-hash(sha256, max_size=1.5G)", "binary"}, 4096)
+	if err != nil {
+		t.Fatalf("ParseChain() error = %v", err)
+	}
+	want := []string{"size", "mtime", "partial-hash(md5)", "full-hash(sha256)", "binary"}
+	if got := valid.Stages(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Stages() = %v, want %v", got, want)
+	}
+
+	invalid := [][]string{
+		nil,
+		{"checksum"},
+		{"full-hash(crc32)"},
+		{"size(xxh3)"},
+		{"mtime(window=soon)"},
+		{"mtime(precision=1s)"},
+		{"full-hash(max_size=big)"},
+		{"partial-hash(xxh3, md5)"},
+		{"mtime(window=2s"},
+	}
+	for _, specs := range invalid {
+		if _, err := ParseChain(specs, 4096); err == nil {
+			t.Errorf("ParseChain(%q) should fail", specs)
+		}
+	}
+}
+
+// TestTextComparator tests text comparison with line ending normalization
+func TestTextComparator(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	ctx := context.Background()
+
+	h.CreateSourceFile("crlf.yaml", []byte("key: value\r\nlist:\r\n  - a\r\n"))
+	h.CreateDestFile("crlf.yaml", []byte("key: value\nlist:\n  - a\n"))
+
+	h.CreateSourceFile("spaces.conf", []byte("\xEF\xBB\xBFname = x  \r\n"))
+	h.CreateDestFile("spaces.conf", []byte("name = x\n"))
+
+	h.CreateSourceFile("edited.yaml", []byte("a: 1\r\nb: 2\r\nc: 3\r\n"))
+	h.CreateDestFile("edited.yaml", []byte("a: 1\nb: 20\nc: 3\n"))
+
+	h.CreateSourceFile("image.yaml", []byte("\x00\x01\r\n"))
+	h.CreateDestFile("image.yaml", []byte("\x00\x01\n"))
+
+	h.CreateSourceFile("data.bin", []byte("line\r\n"))
+	h.CreateDestFile("data.bin", []byte("line\n"))
+
+	tests := []struct {
+		name    string
+		path    string
+		options TextOptions
+		want    Result
+	}{
+		{"line endings ignored", "crlf.yaml", TextOptions{}, Same},
+		{"trailing whitespace and BOM differ", "spaces.conf", TextOptions{}, Different},
+		{"trailing whitespace and BOM ignored", "spaces.conf", TextOptions{IgnoreTrailingWhitespace: true, IgnoreBOM: true}, Same},
+		{"content differs", "edited.yaml", TextOptions{}, Different},
+		{"binary content uses fallback", "image.yaml", TextOptions{}, Different},
+		{"unmatched path uses fallback", "data.bin", TextOptions{}, Different},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comparator := NewTextComparator(NewBinaryComparator(4096), []string{"*.yaml", "*.conf"}, tt.options)
+			result, err := comparator.Compare(ctx, h.source, h.dest, tt.path, tt.path)
+			if err != nil {
+				t.Fatalf("Compare() error = %v", err)
+			}
+			if result.Result != tt.want {
+				t.Errorf("Compare() = %s (%s), want %s", result.Result, result.Reason, tt.want)
+			}
+		})
+	}
+
+	t.Run("diff excerpt", func(t *testing.T) {
+		comparator := NewTextComparator(NewBinaryComparator(4096), []string{"*.yaml"}, TextOptions{})
+		result, err := comparator.Compare(ctx, h.source, h.dest, "edited.yaml", "edited.yaml")
+		if err != nil {
+			t.Fatalf("Compare() error = %v", err)
+		}
+		want := "--- dest/edited.yaml\n+++ source/edited.yaml\n@@ -1,3 +1,3 @@\n a: 1\n-b: 20\n+b: 2\n c: 3\n"
+		if result.Details != want {
+			t.Errorf("Details =\n%s\nwant\n%s", result.Details, want)
+		}
+	})
+}
+
+// TestMatchTextPattern tests text comparison path globs
+func TestMatchTextPattern(t *testing.T) {
+	tests := []struct {
+		path     string
+		patterns []string
+		want     bool
+	}{
+		{"app.yaml", []string{"*.yaml"}, true},
+		{"conf/app.yaml", []string{"*.yaml"}, true},
+		{"conf/app.ini", []string{"conf/*.ini"}, true},
+		{"other/app.ini", []string{"conf/*.ini"}, false},
+		{"a/b/run.sh", []string{"**/*.sh"}, true},
+		{"run.sh", []string{"**/*.sh"}, true},
+		{"scripts/a/b/run.sh", []string{"scripts/**/*.sh"}, true},
+		{"tools/run.sh", []string{"scripts/**/*.sh"}, false},
+		{"app.json", []string{"*.yaml", "*.conf"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := matchTextPattern(tt.path, tt.patterns); got != tt.want {
+			t.Errorf("matchTextPattern(%q, %v) = %v, want %v", tt.path, tt.patterns, got, tt.want)
+		}
+	}
+}
+
+// TestCompareLinks tests the comparison of symbolic links by target
+func TestCompareLinks(t *testing.T) {
+	link := func(target string) *storage.FileInfo {
+		return &storage.FileInfo{RelativePath: "link", IsSymlink: true, LinkTarget: target}
+	}
+	file := &storage.FileInfo{RelativePath: "link", Size: 4}
+
+	tests := []struct {
+		name   string
+		source *storage.FileInfo
+		dest   *storage.FileInfo
+		want   Result
+		reason string
+	}{
+		{"SameTarget", link("a.txt"), link("a.txt"), Same, "symlink targets match"},
+		{"DifferentTarget", link("b.txt"), link("a.txt"), Different, "symlink target differs (a.txt → b.txt)"},
+		{"DestIsFile", link("a.txt"), file, Different, "destination is not a symlink"},
+		{"SourceIsFile", file, link("a.txt"), Different, "destination is a symlink"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CompareLinks(tt.source, tt.dest)
+			if got.Result != tt.want {
+				t.Errorf("CompareLinks() result = %v, want %v", got.Result, tt.want)
+			}
+			if got.Reason != tt.reason {
+				t.Errorf("CompareLinks() reason = %q, want %q", got.Reason, tt.reason)
+			}
+		})
+	}
+}
+
+// TestWithMetadata tests the detection of metadata-only differences
+func TestWithMetadata(t *testing.T) {
+	now := time.Now()
+	file := func(perm uint32, modTime time.Time) *storage.FileInfo {
+		return &storage.FileInfo{RelativePath: "f", Size: 4, Permissions: perm, ModTime: modTime}
+	}
+	tolerance := TimeTolerance{Window: DefaultModifyWindow}
+
+	tests := []struct {
+		name   string
+		result Result
+		source *storage.FileInfo
+		dest   *storage.FileInfo
+		want   Result
+		reason string
+	}{
+		{"Identical", Same, file(0644, now), file(0644, now), Same, ""},
+		{"WithinWindow", Same, file(0644, now), file(0644, now.Add(500*time.Millisecond)), Same, ""},
+		{"Permissions", Same, file(0755, now), file(0644, now), MetadataDiffers, "metadata differs (permissions)"},
+		{"ModTime", Same, file(0644, now), file(0644, now.Add(time.Hour)), MetadataDiffers, "metadata differs (mtime)"},
+		{"Both", Same, file(0600, now), file(0644, now.Add(-time.Hour)), MetadataDiffers, "metadata differs (permissions, mtime)"},
+		{"UnknownPermissions", Same, file(0, now), file(0644, now), Same, ""},
+		{"ContentDiffers", Different, file(0755, now), file(0644, now), Different, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WithMetadata(&Comparison{Result: tt.result, ContentVerified: true}, tt.source, tt.dest, tolerance)
+			if got.Result != tt.want {
+				t.Errorf("Result = %v, want %v", got.Result, tt.want)
+			}
+			if got.Reason != tt.reason {
+				t.Errorf("Reason = %q, want %q", got.Reason, tt.reason)
+			}
+		})
+	}
+
+	t.Run("ContentNotVerified", func(t *testing.T) {
+		got := WithMetadata(&Comparison{Result: Same}, file(0644, now), file(0644, now.Add(time.Hour)), tolerance)
+		if got.Result != Same {
+			t.Errorf("Result = %v, want %v", got.Result, Same)
+		}
+	})
+}
+
 // TestComparatorInterface verifies all comparators implement the interface
 func TestComparatorInterface(t *testing.T) {
 	comparators := []Comparator{
 		NewNameSizeComparator(),
 		NewTimestampComparator(),
 		NewHashComparator(4096),
+		NewBLAKE3Comparator(4096),
+		NewXXH3Comparator(4096),
+		NewXXH128Comparator(4096),
 		NewBinaryComparator(4096),
+		NewTextComparator(NewBinaryComparator(4096), []string{"*.txt"}, TextOptions{}),
 	}
 
 	for _, c := range comparators {
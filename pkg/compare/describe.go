@@ -0,0 +1,78 @@
+package compare
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/sdejongh/syncnorris/pkg/diff"
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+// DefaultMaxDiffSize is the largest file a unified diff is produced for
+const DefaultMaxDiffSize = 1024 * 1024
+
+// Description explains how two differing files differ
+type Description struct {
+	// Diff is a unified diff turning the destination file into the source file (text files)
+	Diff string
+
+	// Offset is the first byte where the files differ (binary and large files, -1 when not computed)
+	Offset int64
+}
+
+// Describe explains how two differing files differ
+// Text files up to maxSize bytes get a unified diff, other files the offset of their first differing byte
+// A non-nil wrapper is applied to every reader (e.g. for rate limiting)
+func Describe(ctx context.Context, source, dest storage.Backend, sourcePath, destPath string, maxSize int64, bufferSize int, wrapper ReaderWrapper) (*Description, error) {
+	sourceInfo, err := source.Stat(ctx, sourcePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat source: %w", err)
+	}
+	destInfo, err := dest.Stat(ctx, destPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat destination: %w", err)
+	}
+
+	if sourceInfo.Size <= maxSize && destInfo.Size <= maxSize {
+		sourceData, err := readFile(ctx, source, sourcePath, maxSize, wrapper)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read source file: %w", err)
+		}
+		destData, err := readFile(ctx, dest, destPath, maxSize, wrapper)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read destination file: %w", err)
+		}
+
+		if diff.IsText(sourceData) && diff.IsText(destData) {
+			return &Description{
+				Diff:   diff.Unified("dest/"+destPath, "source/"+sourcePath, diff.SplitLines(destData), diff.SplitLines(sourceData), diff.DefaultContext),
+				Offset: -1,
+			}, nil
+		}
+	}
+
+	comparator := NewBinaryComparator(bufferSize)
+	if wrapper != nil {
+		comparator.SetReaderWrapper(wrapper)
+	}
+	offset, err := comparator.FirstDifference(ctx, source, dest, sourcePath, destPath)
+	if err != nil {
+		return nil, err
+	}
+	return &Description{Offset: offset}, nil
+}
+
+// readFile reads at most maxSize bytes of a file
+func readFile(ctx context.Context, backend storage.Backend, path string, maxSize int64, wrapper ReaderWrapper) ([]byte, error) {
+	reader, err := backend.Read(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if wrapper != nil {
+		reader = wrapper(reader)
+	}
+	defer reader.Close()
+
+	return io.ReadAll(io.LimitReader(reader, maxSize))
+}
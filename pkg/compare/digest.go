@@ -0,0 +1,382 @@
+package compare
+
+import (
+	"context"
+	"fmt"
+	"hash"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+// Parallel hashing configuration for algorithms that hash large writes on multiple cores
+const (
+	// Minimum file size to switch to large reads (16MB)
+	parallelHashThreshold = 16 * 1024 * 1024
+	// Read size used for large files so each write spans many BLAKE3 chunks (4MB)
+	parallelHashBufferSize = 4 * 1024 * 1024
+)
+
+// DigestComparator compares files using a streaming hash algorithm
+// It backs the BLAKE3, XXH3 and XXH128 comparison methods and supports the
+// same partial hashing, progress reporting, reader wrapping and hash caching
+// as HashComparator
+type DigestComparator struct {
+	algorithm         string
+	parallel          bool // Use large reads on big files so the hasher can fan out across cores
+	bufferSize        int
+	bufferPool        *sync.Pool
+	largeBufferPool   *sync.Pool
+	progressReport    func(path string, current, total int64) // Optional progress callback
+	enablePartialHash bool                                    // Enable partial hashing optimization
+	readerWrapper     ReaderWrapper                           // Optional reader wrapper (e.g., for rate limiting)
+}
+
+// NewBLAKE3Comparator creates a comparator using BLAKE3 (256-bit)
+// Files larger than 16MB are hashed on multiple cores
+func NewBLAKE3Comparator(bufferSize int) *DigestComparator {
+	c := newDigestComparator(AlgorithmBLAKE3, bufferSize)
+	c.parallel = true
+	return c
+}
+
+// NewXXH3Comparator creates a comparator using XXH3 (64-bit, non-cryptographic)
+func NewXXH3Comparator(bufferSize int) *DigestComparator {
+	return newDigestComparator(AlgorithmXXH3, bufferSize)
+}
+
+// NewXXH128Comparator creates a comparator using XXH128 (128-bit, non-cryptographic)
+func NewXXH128Comparator(bufferSize int) *DigestComparator {
+	return newDigestComparator(AlgorithmXXH128, bufferSize)
+}
+
+// newDigestComparator creates a digest comparator for a hash algorithm known to NewHasher
+func newDigestComparator(algorithm string, bufferSize int) *DigestComparator {
+	if bufferSize < 4096 {
+		bufferSize = 4096
+	}
+	return &DigestComparator{
+		algorithm:         algorithm,
+		bufferSize:        bufferSize,
+		enablePartialHash: true, // Enabled by default
+		bufferPool: &sync.Pool{
+			New: func() interface{} {
+				buf := make([]byte, bufferSize)
+				return &buf
+			},
+		},
+		largeBufferPool: &sync.Pool{
+			New: func() interface{} {
+				buf := make([]byte, parallelHashBufferSize)
+				return &buf
+			},
+		},
+	}
+}
+
+// Algorithm returns the name of the hash algorithm
+func (c *DigestComparator) Algorithm() string {
+	return c.algorithm
+}
+
+// SetPartialHashEnabled enables or disables partial hashing optimization
+func (c *DigestComparator) SetPartialHashEnabled(enabled bool) {
+	c.enablePartialHash = enabled
+}
+
+// SetProgressCallback sets the progress reporting callback
+func (c *DigestComparator) SetProgressCallback(callback func(path string, current, total int64)) {
+	c.progressReport = callback
+}
+
+// SetReaderWrapper sets a function to wrap readers (e.g., for rate limiting)
+func (c *DigestComparator) SetReaderWrapper(wrapper ReaderWrapper) {
+	c.readerWrapper = wrapper
+}
+
+// Compare compares two files using the comparator's hash algorithm
+func (c *DigestComparator) Compare(ctx context.Context, source, dest storage.Backend, sourcePath, destPath string) (*Comparison, error) {
+	// Check if source exists
+	sourceExists, err := source.Exists(ctx, sourcePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check source existence: %w", err)
+	}
+	if !sourceExists {
+		return &Comparison{
+			SourcePath: sourcePath,
+			DestPath:   destPath,
+			Result:     DestOnly,
+			Reason:     "file exists only in destination",
+		}, nil
+	}
+
+	// Check if destination exists
+	destExists, err := dest.Exists(ctx, destPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check destination existence: %w", err)
+	}
+	if !destExists {
+		return &Comparison{
+			SourcePath: sourcePath,
+			DestPath:   destPath,
+			Result:     SourceOnly,
+			Reason:     "file exists only in source",
+		}, nil
+	}
+
+	// Get file info to check sizes first (quick check)
+	sourceInfo, err := source.Stat(ctx, sourcePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat source file: %w", err)
+	}
+
+	destInfo, err := dest.Stat(ctx, destPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat destination file: %w", err)
+	}
+
+	// If sizes differ, files are different
+	if sourceInfo.Size != destInfo.Size {
+		return &Comparison{
+			SourcePath: sourcePath,
+			DestPath:   destPath,
+			Result:     Different,
+			Reason:     "file sizes differ",
+		}, nil
+	}
+
+	// Partial hash optimization for large files, skipped when both full hashes are cached
+	_, sourceCached := cachedHash(source, sourceInfo, c.algorithm)
+	_, destCached := cachedHash(dest, destInfo, c.algorithm)
+	if c.enablePartialHash && sourceInfo.Size >= partialHashThreshold && !(sourceCached && destCached) {
+		var sourcePartialHash, destPartialHash string
+		var sourcePartialErr, destPartialErr error
+		var wg sync.WaitGroup
+
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			sourcePartialHash, sourcePartialErr = c.computePartialHash(ctx, source, sourcePath)
+		}()
+		go func() {
+			defer wg.Done()
+			destPartialHash, destPartialErr = c.computePartialHash(ctx, dest, destPath)
+		}()
+		wg.Wait()
+
+		// Fall back to the full hash if either partial hash fails
+		if sourcePartialErr == nil && destPartialErr == nil && sourcePartialHash != destPartialHash {
+			return &Comparison{
+				SourcePath: sourcePath,
+				DestPath:   destPath,
+				Result:     Different,
+				Reason:     "file partial hashes differ",
+			}, nil
+		}
+	}
+
+	// Compute full hashes in parallel
+	var sourceHash, destHash string
+	var sourceHashErr, destHashErr error
+	var wg sync.WaitGroup
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		sourceHash, sourceHashErr = c.computeHash(ctx, source, sourcePath, sourceInfo)
+	}()
+	go func() {
+		defer wg.Done()
+		destHash, destHashErr = c.computeHash(ctx, dest, destPath, destInfo)
+	}()
+	wg.Wait()
+
+	if sourceHashErr != nil {
+		return &Comparison{
+			SourcePath: sourcePath,
+			DestPath:   destPath,
+			Result:     Error,
+			Reason:     "failed to compute source hash",
+			Error:      sourceHashErr,
+		}, sourceHashErr
+	}
+	if destHashErr != nil {
+		return &Comparison{
+			SourcePath: sourcePath,
+			DestPath:   destPath,
+			Result:     Error,
+			Reason:     "failed to compute destination hash",
+			Error:      destHashErr,
+		}, destHashErr
+	}
+
+	result := &Comparison{
+		SourcePath:    sourcePath,
+		DestPath:      destPath,
+		Result:        Same,
+		Reason:        fmt.Sprintf("%s hashes match", c.algorithm),
+		HashAlgorithm: c.algorithm,
+		SourceHash:    sourceHash,
+		DestHash:      destHash,
+
+		ContentVerified: true,
+	}
+	if sourceHash != destHash {
+		result.Result = Different
+		result.Reason = fmt.Sprintf("%s hashes differ", c.algorithm)
+	}
+	return result, nil
+}
+
+// computeHash computes the hash of an entire file with progress reporting
+func (c *DigestComparator) computeHash(ctx context.Context, backend storage.Backend, path string, info *storage.FileInfo) (string, error) {
+	fileSize := info.Size
+
+	// Reuse the cached hash if this version of the file was hashed before
+	if cached, ok := cachedHash(backend, info, c.algorithm); ok {
+		if c.progressReport != nil {
+			c.progressReport(path, fileSize, fileSize)
+		}
+		return cached, nil
+	}
+
+	reader, err := backend.Read(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer reader.Close()
+
+	// Apply reader wrapper if set (e.g., for rate limiting)
+	if c.readerWrapper != nil {
+		reader = c.readerWrapper(reader)
+	}
+
+	hasher, err := NewHasher(c.algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	// Large reads let BLAKE3 hash each write on multiple goroutines
+	pool := c.bufferPool
+	if c.parallel && fileSize >= parallelHashThreshold {
+		pool = c.largeBufferPool
+	}
+	bufPtr := pool.Get().(*[]byte)
+	defer pool.Put(bufPtr)
+	buffer := *bufPtr
+
+	// Progress throttling variables
+	const (
+		progressReportInterval = 50 * time.Millisecond // Minimum time between reports
+		progressReportBytes    = 64 * 1024             // Minimum bytes between reports (64KB)
+	)
+	var totalRead int64
+	var lastReported int64
+	lastReportTime := time.Now()
+
+	for {
+		// Check context cancellation
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		default:
+		}
+
+		n, err := io.ReadFull(reader, buffer)
+		if n > 0 {
+			hasher.Write(buffer[:n])
+			totalRead += int64(n)
+
+			// Report progress if callback is set (with throttling)
+			if c.progressReport != nil {
+				shouldReport := totalRead-lastReported >= progressReportBytes ||
+					time.Since(lastReportTime) >= progressReportInterval
+
+				if shouldReport {
+					c.progressReport(path, totalRead, fileSize)
+					lastReported = totalRead
+					lastReportTime = time.Now()
+				}
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read file: %w", err)
+		}
+	}
+
+	// Ensure final progress report shows 100% completion
+	if c.progressReport != nil && totalRead > lastReported {
+		c.progressReport(path, totalRead, fileSize)
+	}
+
+	sum := fmt.Sprintf("%x", hasher.Sum(nil))
+	storeHash(backend, info, c.algorithm, sum)
+
+	return sum, nil
+}
+
+// computePartialHash computes the hash of the first partialHashSize bytes of a file
+func (c *DigestComparator) computePartialHash(ctx context.Context, backend storage.Backend, path string) (string, error) {
+	return c.computePrefixHash(ctx, backend, path, partialHashSize)
+}
+
+// computePrefixHash computes the hash of the first n bytes of a file
+func (c *DigestComparator) computePrefixHash(ctx context.Context, backend storage.Backend, path string, n int64) (string, error) {
+	reader, err := backend.Read(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer reader.Close()
+
+	// Apply reader wrapper if set (e.g., for rate limiting)
+	if c.readerWrapper != nil {
+		reader = c.readerWrapper(reader)
+	}
+
+	hasher, err := NewHasher(c.algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	if err := copyWithContext(ctx, hasher, io.LimitReader(reader, n), c.bufferPool); err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return fmt.Sprintf("%x", hasher.Sum(nil)), nil
+}
+
+// copyWithContext copies src into a hash using a pooled buffer, checking for cancellation between reads
+func copyWithContext(ctx context.Context, dst hash.Hash, src io.Reader, pool *sync.Pool) error {
+	bufPtr := pool.Get().(*[]byte)
+	defer pool.Put(bufPtr)
+	buffer := *bufPtr
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		n, err := src.Read(buffer)
+		if n > 0 {
+			dst.Write(buffer[:n])
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Name returns the comparator name
+func (c *DigestComparator) Name() string {
+	return c.algorithm
+}
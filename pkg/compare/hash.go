@@ -104,7 +104,10 @@ func (c *HashComparator) Compare(ctx context.Context, source, dest storage.Backe
 	// Partial hash optimization for large files (parallel execution)
 	// If files are large enough and partial hashing is enabled,
 	// compute partial hashes first for quick rejection
-	if c.enablePartialHash && sourceInfo.Size >= partialHashThreshold {
+	// Skipped when both full hashes are already cached
+	_, sourceCached := cachedHash(source, sourceInfo, AlgorithmSHA256)
+	_, destCached := cachedHash(dest, destInfo, AlgorithmSHA256)
+	if c.enablePartialHash && sourceInfo.Size >= partialHashThreshold && !(sourceCached && destCached) {
 		var sourcePartialHash, destPartialHash string
 		var sourcePartialErr, destPartialErr error
 		var wg sync.WaitGroup
@@ -180,6 +183,10 @@ func (c *HashComparator) Compare(ctx context.Context, source, dest storage.Backe
 			DestPath:   destPath,
 			Result:     Different,
 			Reason:     "file hashes differ",
+
+			HashAlgorithm: AlgorithmSHA256,
+			SourceHash:    sourceHash,
+			DestHash:      destHash,
 		}, nil
 	}
 
@@ -188,6 +195,12 @@ func (c *HashComparator) Compare(ctx context.Context, source, dest storage.Backe
 		DestPath:   destPath,
 		Result:     Same,
 		Reason:     "file hashes match",
+
+		HashAlgorithm: AlgorithmSHA256,
+		SourceHash:    sourceHash,
+		DestHash:      destHash,
+
+		ContentVerified: true,
 	}, nil
 }
 
@@ -200,6 +213,14 @@ func (c *HashComparator) computeHash(ctx context.Context, backend storage.Backen
 	}
 	fileSize := fileInfo.Size
 
+	// Reuse the cached hash if this version of the file was hashed before
+	if hash, ok := cachedHash(backend, fileInfo, AlgorithmSHA256); ok {
+		if c.progressReport != nil {
+			c.progressReport(path, fileSize, fileSize)
+		}
+		return hash, nil
+	}
+
 	reader, err := backend.Read(ctx, path)
 	if err != nil {
 		return "", fmt.Errorf("failed to open file: %w", err)
@@ -268,7 +289,10 @@ func (c *HashComparator) computeHash(ctx context.Context, backend storage.Backen
 		c.progressReport(path, totalRead, fileSize)
 	}
 
-	return fmt.Sprintf("%x", hasher.Sum(nil)), nil
+	hash := fmt.Sprintf("%x", hasher.Sum(nil))
+	storeHash(backend, fileInfo, AlgorithmSHA256, hash)
+
+	return hash, nil
 }
 
 // computePartialHash computes SHA-256 hash of the first partialHashSize bytes of a file
@@ -0,0 +1,32 @@
+package compare
+
+import (
+	"fmt"
+
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+// CompareLinks compares two entries of which at least one is a symbolic link
+// Links are equal when both are links with the same target; their content is never read
+func CompareLinks(source, dest *storage.FileInfo) *Comparison {
+	comparison := &Comparison{
+		SourcePath: source.RelativePath,
+		DestPath:   dest.RelativePath,
+		Result:     Different,
+	}
+
+	switch {
+	case source.IsSymlink && !dest.IsSymlink:
+		comparison.Reason = "destination is not a symlink"
+	case !source.IsSymlink && dest.IsSymlink:
+		comparison.Reason = "destination is a symlink"
+	case source.LinkTarget != dest.LinkTarget:
+		comparison.Reason = fmt.Sprintf("symlink target differs (%s → %s)", dest.LinkTarget, source.LinkTarget)
+	default:
+		comparison.Result = Same
+		comparison.Reason = "symlink targets match"
+		comparison.ContentVerified = true
+	}
+
+	return comparison
+}
@@ -99,7 +99,10 @@ func (c *MD5Comparator) Compare(ctx context.Context, source, dest storage.Backen
 	}
 
 	// Try partial hash first for large files (same as SHA-256 comparator)
-	if c.enablePartialHash && sourceInfo.Size >= partialHashThreshold {
+	// Skipped when both full hashes are already cached
+	_, sourceCached := cachedHash(source, sourceInfo, AlgorithmMD5)
+	_, destCached := cachedHash(dest, destInfo, AlgorithmMD5)
+	if c.enablePartialHash && sourceInfo.Size >= partialHashThreshold && !(sourceCached && destCached) {
 		// Compute partial hashes in parallel
 		var sourcePartialHash, destPartialHash string
 		var sourcePartialErr, destPartialErr error
@@ -140,11 +143,11 @@ func (c *MD5Comparator) Compare(ctx context.Context, source, dest storage.Backen
 	wg.Add(2)
 	go func() {
 		defer wg.Done()
-		sourceHash, sourceErr = c.computeHash(ctx, source, sourcePath, sourceInfo.Size)
+		sourceHash, sourceErr = c.computeHash(ctx, source, sourcePath, sourceInfo)
 	}()
 	go func() {
 		defer wg.Done()
-		destHash, destErr = c.computeHash(ctx, dest, destPath, destInfo.Size)
+		destHash, destErr = c.computeHash(ctx, dest, destPath, destInfo)
 	}()
 	wg.Wait()
 
@@ -160,12 +163,22 @@ func (c *MD5Comparator) Compare(ctx context.Context, source, dest storage.Backen
 		return &Comparison{
 			Result: Same,
 			Reason: "MD5 hashes match",
+
+			HashAlgorithm: AlgorithmMD5,
+			SourceHash:    sourceHash,
+			DestHash:      destHash,
+
+			ContentVerified: true,
 		}, nil
 	}
 
 	return &Comparison{
 		Result: Different,
 		Reason: "MD5 hash mismatch",
+
+		HashAlgorithm: AlgorithmMD5,
+		SourceHash:    sourceHash,
+		DestHash:      destHash,
 	}, nil
 }
 
@@ -210,7 +223,17 @@ func (c *MD5Comparator) computePartialHash(ctx context.Context, backend storage.
 }
 
 // computeHash computes MD5 hash of entire file with progress reporting
-func (c *MD5Comparator) computeHash(ctx context.Context, backend storage.Backend, path string, fileSize int64) (string, error) {
+func (c *MD5Comparator) computeHash(ctx context.Context, backend storage.Backend, path string, info *storage.FileInfo) (string, error) {
+	fileSize := info.Size
+
+	// Reuse the cached hash if this version of the file was hashed before
+	if cached, ok := cachedHash(backend, info, AlgorithmMD5); ok {
+		if c.progressReport != nil {
+			c.progressReport(path, fileSize, fileSize)
+		}
+		return cached, nil
+	}
+
 	reader, err := backend.Read(ctx, path)
 	if err != nil {
 		return "", fmt.Errorf("failed to open file: %w", err)
@@ -276,7 +299,10 @@ func (c *MD5Comparator) computeHash(ctx context.Context, backend storage.Backend
 		}
 	}
 
-	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+	sum := fmt.Sprintf("%x", hash.Sum(nil))
+	storeHash(backend, info, AlgorithmMD5, sum)
+
+	return sum, nil
 }
 
 // Name returns the comparator name
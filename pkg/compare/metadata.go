@@ -0,0 +1,92 @@
+package compare
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+// MetadataDiff returns the metadata differing between a source and destination entry:
+// permissions, modification time and the preserved metadata (owner, special bits, xattrs, acls)
+// Symbolic links are only compared by preserved metadata, and unset values are not compared
+func MetadataDiff(source, dest *storage.FileInfo, tolerance TimeTolerance) []string {
+	var diffs []string
+
+	if !source.IsSymlink && !dest.IsSymlink {
+		sourcePerm := fs.FileMode(source.Permissions).Perm()
+		destPerm := fs.FileMode(dest.Permissions).Perm()
+		if source.Permissions != 0 && dest.Permissions != 0 && sourcePerm != destPerm {
+			diffs = append(diffs, "permissions")
+		}
+
+		if !source.ModTime.IsZero() && !dest.ModTime.IsZero() && !tolerance.Equal(source.ModTime, dest.ModTime) {
+			diffs = append(diffs, "mtime")
+		}
+	}
+
+	return append(diffs, storage.MetadataDiff(source, dest)...)
+}
+
+// WithMetadata refines a comparison of identical content with the metadata of both entries
+// A Same result becomes MetadataDiffers when the metadata differs; other results are unchanged
+// Results trusted from size and modification time (namesize, timestamp, the mtime stage) are only
+// refined after ConfirmContent: the content may differ, and stamping the source metadata would hide it
+func WithMetadata(comparison *Comparison, source, dest *storage.FileInfo, tolerance TimeTolerance) *Comparison {
+	if comparison.Result != Same || !comparison.ContentVerified || source == nil || dest == nil {
+		return comparison
+	}
+
+	diffs := MetadataDiff(source, dest, tolerance)
+	if len(diffs) == 0 {
+		return comparison
+	}
+
+	comparison.Result = MetadataDiffers
+	comparison.MetadataDiffs = diffs
+	comparison.Reason = fmt.Sprintf("metadata differs (%s)", strings.Join(diffs, ", "))
+	return comparison
+}
+
+// ConfirmContent hashes both files of a comparison decided by size and modification time
+// (namesize, timestamp, the mtime stage) when they have the same size but differing metadata,
+// so that WithMetadata can turn a touched but unchanged file into a metadata-only update
+// The comparison is returned unchanged unless the hashes match; comparisons that read the content
+// already know whether it matches, so callers only confirm comparisons decided by metadata
+func ConfirmContent(ctx context.Context, comparison *Comparison, source, dest storage.Backend, sourceInfo, destInfo *storage.FileInfo, tolerance TimeTolerance, wrapper ReaderWrapper) (*Comparison, error) {
+	if comparison.ContentVerified || (comparison.Result != Same && comparison.Result != Different) {
+		return comparison, nil
+	}
+	if sourceInfo == nil || destInfo == nil || sourceInfo.IsSymlink || destInfo.IsSymlink || sourceInfo.Size != destInfo.Size {
+		return comparison, nil
+	}
+	if len(MetadataDiff(sourceInfo, destInfo, tolerance)) == 0 {
+		return comparison, nil
+	}
+
+	sourceHash, err := HashFile(ctx, source, sourceInfo, AlgorithmXXH3, wrapper)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash source file: %w", err)
+	}
+	destHash, err := HashFile(ctx, dest, destInfo, AlgorithmXXH3, wrapper)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash destination file: %w", err)
+	}
+	if sourceHash != destHash {
+		return comparison, nil
+	}
+
+	return &Comparison{
+		SourcePath:      comparison.SourcePath,
+		DestPath:        comparison.DestPath,
+		Result:          Same,
+		Reason:          "content hashes match",
+		SourceHash:      sourceHash,
+		DestHash:        destHash,
+		HashAlgorithm:   AlgorithmXXH3,
+		Stage:           comparison.Stage,
+		ContentVerified: true,
+	}, nil
+}
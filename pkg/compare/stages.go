@@ -0,0 +1,143 @@
+package compare
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+// sizeStage decides files with different sizes are different
+type sizeStage struct{}
+
+func (s *sizeStage) Evaluate(ctx context.Context, source, dest storage.Backend, sourcePath, destPath string, sourceInfo, destInfo *storage.FileInfo) (*Comparison, error) {
+	if sourceInfo.Size != destInfo.Size {
+		return &Comparison{
+			Result: Different,
+			Reason: fmt.Sprintf("file sizes differ (source: %d, dest: %d)", sourceInfo.Size, destInfo.Size),
+		}, nil
+	}
+	return &Comparison{Result: Undecided}, nil
+}
+
+func (s *sizeStage) Name() string {
+	return "size"
+}
+
+// mtimeStage trusts files with the same size and modification time to be identical
+type mtimeStage struct {
+	tolerance TimeTolerance
+}
+
+func (s *mtimeStage) Evaluate(ctx context.Context, source, dest storage.Backend, sourcePath, destPath string, sourceInfo, destInfo *storage.FileInfo) (*Comparison, error) {
+	if sourceInfo.Size == destInfo.Size && s.tolerance.Equal(sourceInfo.ModTime, destInfo.ModTime) {
+		return &Comparison{
+			Result: Same,
+			Reason: fmt.Sprintf("size and modification time match (window %s)", s.tolerance.Window),
+		}, nil
+	}
+	return &Comparison{Result: Undecided}, nil
+}
+
+func (s *mtimeStage) Name() string {
+	return "mtime"
+}
+
+// partialHashStage decides files whose leading bytes hash differently are different
+// Files no larger than the hashed prefix are hashed entirely, so matching hashes decide they are the same
+type partialHashStage struct {
+	digest *DigestComparator
+	bytes  int64
+}
+
+func (s *partialHashStage) Evaluate(ctx context.Context, source, dest storage.Backend, sourcePath, destPath string, sourceInfo, destInfo *storage.FileInfo) (*Comparison, error) {
+	var sourceHash, destHash string
+	var sourceErr, destErr error
+	var wg sync.WaitGroup
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		sourceHash, sourceErr = s.digest.computePrefixHash(ctx, source, sourcePath, s.bytes)
+	}()
+	go func() {
+		defer wg.Done()
+		destHash, destErr = s.digest.computePrefixHash(ctx, dest, destPath, s.bytes)
+	}()
+	wg.Wait()
+
+	// A later stage reads the files again and reports the error if it persists
+	if sourceErr != nil || destErr != nil {
+		return &Comparison{Result: Undecided}, nil
+	}
+
+	if sourceHash != destHash {
+		return &Comparison{
+			Result: Different,
+			Reason: fmt.Sprintf("%s hashes of the first %d bytes differ", s.digest.Algorithm(), s.bytes),
+		}, nil
+	}
+	if sourceInfo.Size == destInfo.Size && sourceInfo.Size <= s.bytes {
+		return &Comparison{
+			Result:        Same,
+			Reason:        fmt.Sprintf("%s hashes match (whole file hashed)", s.digest.Algorithm()),
+			HashAlgorithm: s.digest.Algorithm(),
+			SourceHash:    sourceHash,
+			DestHash:      destHash,
+
+			ContentVerified: true,
+		}, nil
+	}
+	return &Comparison{Result: Undecided}, nil
+}
+
+func (s *partialHashStage) SetReaderWrapper(wrapper ReaderWrapper) {
+	s.digest.SetReaderWrapper(wrapper)
+}
+
+func (s *partialHashStage) Name() string {
+	return fmt.Sprintf("partial-hash(%s)", s.digest.Algorithm())
+}
+
+// fullHashStage decides by hashing both files entirely
+type fullHashStage struct {
+	digest *DigestComparator
+}
+
+func (s *fullHashStage) Evaluate(ctx context.Context, source, dest storage.Backend, sourcePath, destPath string, sourceInfo, destInfo *storage.FileInfo) (*Comparison, error) {
+	return s.digest.Compare(ctx, source, dest, sourcePath, destPath)
+}
+
+func (s *fullHashStage) SetProgressCallback(callback func(path string, current, total int64)) {
+	s.digest.SetProgressCallback(callback)
+}
+
+func (s *fullHashStage) SetReaderWrapper(wrapper ReaderWrapper) {
+	s.digest.SetReaderWrapper(wrapper)
+}
+
+func (s *fullHashStage) Name() string {
+	return fmt.Sprintf("full-hash(%s)", s.digest.Algorithm())
+}
+
+// binaryStage decides by comparing both files byte by byte
+type binaryStage struct {
+	binary *BinaryComparator
+}
+
+func (s *binaryStage) Evaluate(ctx context.Context, source, dest storage.Backend, sourcePath, destPath string, sourceInfo, destInfo *storage.FileInfo) (*Comparison, error) {
+	return s.binary.Compare(ctx, source, dest, sourcePath, destPath)
+}
+
+func (s *binaryStage) SetProgressCallback(callback func(path string, current, total int64)) {
+	s.binary.SetProgressCallback(callback)
+}
+
+func (s *binaryStage) SetReaderWrapper(wrapper ReaderWrapper) {
+	s.binary.SetReaderWrapper(wrapper)
+}
+
+func (s *binaryStage) Name() string {
+	return "binary"
+}
@@ -0,0 +1,239 @@
+package compare
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/sdejongh/syncnorris/pkg/diff"
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+const (
+	// maxTextCompareSize is the largest file compared as text; larger files use the fallback comparator
+	maxTextCompareSize = 8 * 1024 * 1024
+
+	// textDiffExcerptLines is the number of unified diff lines kept in Comparison.Details
+	textDiffExcerptLines = 20
+)
+
+// utf8BOM is the byte order mark some Windows editors write at the start of UTF-8 files
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// TextOptions controls which differences the text comparator ignores
+// Line endings (CRLF, CR, LF) are always normalized
+type TextOptions struct {
+	IgnoreTrailingWhitespace bool // Ignore spaces and tabs at the end of lines
+	IgnoreBOM                bool // Ignore a leading UTF-8 byte order mark
+}
+
+// TextComparator compares files matching path globs as text, ignoring line ending differences
+// Other files, files larger than 8MB and files that turn out to be binary are compared by the fallback comparator
+type TextComparator struct {
+	fallback      Comparator
+	patterns      []string
+	options       TextOptions
+	readerWrapper ReaderWrapper // Optional reader wrapper (e.g., for rate limiting)
+}
+
+// NewTextComparator creates a comparator comparing files matching patterns as text
+// Patterns without a slash match file names (*.yaml), others match relative paths (conf/*.ini, **/*.cfg)
+func NewTextComparator(fallback Comparator, patterns []string, options TextOptions) *TextComparator {
+	return &TextComparator{
+		fallback: fallback,
+		patterns: patterns,
+		options:  options,
+	}
+}
+
+// SetProgressCallback sets the progress reporting callback of the fallback comparator
+func (c *TextComparator) SetProgressCallback(callback func(path string, current, total int64)) {
+	if comp, ok := c.fallback.(interface {
+		SetProgressCallback(func(path string, current, total int64))
+	}); ok {
+		comp.SetProgressCallback(callback)
+	}
+}
+
+// SetReaderWrapper sets a function to wrap readers (e.g., for rate limiting)
+func (c *TextComparator) SetReaderWrapper(wrapper ReaderWrapper) {
+	c.readerWrapper = wrapper
+	if comp, ok := c.fallback.(RateLimitedComparator); ok {
+		comp.SetReaderWrapper(wrapper)
+	}
+}
+
+// Compare compares files matching the text patterns as normalized text
+func (c *TextComparator) Compare(ctx context.Context, source, dest storage.Backend, sourcePath, destPath string) (*Comparison, error) {
+	if !matchTextPattern(sourcePath, c.patterns) {
+		return c.fallback.Compare(ctx, source, dest, sourcePath, destPath)
+	}
+
+	sourceInfo, err := source.Stat(ctx, sourcePath)
+	if err != nil {
+		return &Comparison{
+			SourcePath: sourcePath,
+			DestPath:   destPath,
+			Result:     SourceOnly,
+			Reason:     "file exists only in source",
+		}, nil
+	}
+
+	destInfo, err := dest.Stat(ctx, destPath)
+	if err != nil {
+		return &Comparison{
+			SourcePath: sourcePath,
+			DestPath:   destPath,
+			Result:     DestOnly,
+			Reason:     "file exists only in destination",
+		}, nil
+	}
+
+	if sourceInfo.Size > maxTextCompareSize || destInfo.Size > maxTextCompareSize {
+		return c.fallback.Compare(ctx, source, dest, sourcePath, destPath)
+	}
+
+	sourceData, err := c.readAll(ctx, source, sourcePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source file: %w", err)
+	}
+	destData, err := c.readAll(ctx, dest, destPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read destination file: %w", err)
+	}
+
+	if !diff.IsText(sourceData) || !diff.IsText(destData) {
+		return c.fallback.Compare(ctx, source, dest, sourcePath, destPath)
+	}
+
+	sourceLines := diff.SplitLines(c.normalize(sourceData))
+	destLines := diff.SplitLines(c.normalize(destData))
+
+	unified := diff.Unified("dest/"+destPath, "source/"+sourcePath, destLines, sourceLines, diff.DefaultContext)
+	if unified == "" {
+		return &Comparison{
+			SourcePath: sourcePath,
+			DestPath:   destPath,
+			Result:     Same,
+			Reason:     fmt.Sprintf("text content matches (%s)", c.describeNormalization()),
+
+			ContentVerified: true,
+		}, nil
+	}
+
+	return &Comparison{
+		SourcePath: sourcePath,
+		DestPath:   destPath,
+		Result:     Different,
+		Reason:     "text content differs",
+		Details:    diff.Excerpt(unified, textDiffExcerptLines),
+	}, nil
+}
+
+// matchTextPattern reports whether a relative path matches one of the given globs
+// Patterns without a slash match the file name, "**/" matches any number of directories
+func matchTextPattern(relativePath string, patterns []string) bool {
+	relativePath = filepath.ToSlash(relativePath)
+	name := path.Base(relativePath)
+
+	for _, pattern := range patterns {
+		pattern = filepath.ToSlash(pattern)
+		if pattern == "" {
+			continue
+		}
+
+		if !strings.Contains(pattern, "/") {
+			if matched, _ := path.Match(pattern, name); matched {
+				return true
+			}
+			continue
+		}
+
+		if matchSegments(strings.Split(pattern, "/"), strings.Split(relativePath, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches path segments against pattern segments, "**" matching any number of segments
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(pattern[0], parts[0]); !matched {
+		return false
+	}
+	return matchSegments(pattern[1:], parts[1:])
+}
+
+// readAll reads a whole file through the reader wrapper
+func (c *TextComparator) readAll(ctx context.Context, backend storage.Backend, filePath string) ([]byte, error) {
+	reader, err := backend.Read(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+	if c.readerWrapper != nil {
+		reader = c.readerWrapper(reader)
+	}
+	defer reader.Close()
+
+	return io.ReadAll(io.LimitReader(reader, maxTextCompareSize+1))
+}
+
+// normalize converts line endings to LF and removes the differences the options ignore
+func (c *TextComparator) normalize(data []byte) []byte {
+	if c.options.IgnoreBOM {
+		data = bytes.TrimPrefix(data, utf8BOM)
+	}
+
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	data = bytes.ReplaceAll(data, []byte("\r"), []byte("\n"))
+
+	if c.options.IgnoreTrailingWhitespace {
+		var buf bytes.Buffer
+		buf.Grow(len(data))
+		for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+			content, newline := bytes.CutSuffix(line, []byte("\n"))
+			buf.Write(bytes.TrimRight(content, " \t"))
+			if newline {
+				buf.WriteByte('\n')
+			}
+		}
+		data = buf.Bytes()
+	}
+
+	return data
+}
+
+// describeNormalization lists the differences ignored by the comparator
+func (c *TextComparator) describeNormalization() string {
+	ignored := []string{"line endings"}
+	if c.options.IgnoreTrailingWhitespace {
+		ignored = append(ignored, "trailing whitespace")
+	}
+	if c.options.IgnoreBOM {
+		ignored = append(ignored, "BOM")
+	}
+	return "ignoring " + strings.Join(ignored, ", ")
+}
+
+// Name returns the comparator name
+func (c *TextComparator) Name() string {
+	return "text"
+}
@@ -4,16 +4,63 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"time"
 
 	"github.com/sdejongh/syncnorris/pkg/storage"
 )
 
+// DefaultModifyWindow is the default tolerance between modification times considered equal
+// It absorbs the precision differences between most filesystems
+const DefaultModifyWindow = time.Second
+
+// maxHourOffset is the largest whole-hour shift ignored by TimeTolerance.IgnoreHourOffsets
+// (timezone offsets range from UTC-12 to UTC+14)
+const maxHourOffset = 26 * time.Hour
+
+// TimeTolerance decides whether two modification times are the same
+type TimeTolerance struct {
+	// Window is the largest difference still considered the same time (0 = exact)
+	Window time.Duration
+
+	// IgnoreHourOffsets also treats times differing by a whole number of hours (within Window)
+	// as the same. FAT/exFAT store local time, so timestamps shift with DST and timezone changes
+	IgnoreHourOffsets bool
+}
+
+// Equal reports whether a and b are the same time within the tolerance
+func (t TimeTolerance) Equal(a, b time.Time) bool {
+	diff := a.Sub(b)
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff <= t.Window {
+		return true
+	}
+	if !t.IgnoreHourOffsets || diff > maxHourOffset+t.Window {
+		return false
+	}
+	rem := diff % time.Hour
+	return rem <= t.Window || time.Hour-rem <= t.Window
+}
+
+// After reports whether a is newer than b beyond the tolerance
+func (t TimeTolerance) After(a, b time.Time) bool {
+	return a.After(b) && !t.Equal(a, b)
+}
+
 // TimestampComparator compares files by name, size, and modification time
-type TimestampComparator struct{}
+type TimestampComparator struct {
+	tolerance TimeTolerance
+}
 
-// NewTimestampComparator creates a new timestamp comparator
+// NewTimestampComparator creates a new timestamp comparator with the default modify window
 func NewTimestampComparator() *TimestampComparator {
-	return &TimestampComparator{}
+	return NewTimestampComparatorWithTolerance(TimeTolerance{Window: DefaultModifyWindow})
+}
+
+// NewTimestampComparatorWithTolerance creates a timestamp comparator with a custom time tolerance
+func NewTimestampComparatorWithTolerance(tolerance TimeTolerance) *TimestampComparator {
+	return &TimestampComparator{tolerance: tolerance}
 }
 
 // Compare compares two files by name, size, and modification time
@@ -64,9 +111,8 @@ func (c *TimestampComparator) Compare(ctx context.Context, source, dest storage.
 
 	// Compare modification times
 	// Source is considered newer if its ModTime is after the destination's ModTime
-	// We use a 1-second tolerance to account for filesystem timestamp precision differences
-	timeDiff := sourceInfo.ModTime.Sub(destInfo.ModTime)
-	if timeDiff > 1e9 { // More than 1 second newer
+	// The tolerance accounts for filesystem timestamp precision differences (1 second by default)
+	if c.tolerance.After(sourceInfo.ModTime, destInfo.ModTime) {
 		return &Comparison{
 			SourcePath: sourcePath,
 			DestPath:   destPath,
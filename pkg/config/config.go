@@ -1,23 +1,88 @@
 package config
 
 import (
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/compare"
 	"github.com/sdejongh/syncnorris/pkg/models"
+	"github.com/sdejongh/syncnorris/pkg/ratelimit"
+	"github.com/sdejongh/syncnorris/pkg/storage"
 )
 
 // Config represents the application configuration
 type Config struct {
-	Sync        SyncConfig        `yaml:"sync"`
-	Performance PerformanceConfig `yaml:"performance"`
-	Output      OutputConfig      `yaml:"output"`
-	Logging     LoggingConfig     `yaml:"logging"`
-	Exclude     []string          `yaml:"exclude"`
+	Sync          SyncConfig          `yaml:"sync"`
+	Performance   PerformanceConfig   `yaml:"performance"`
+	HashCache     HashCacheConfig     `yaml:"hash_cache"`
+	Output        OutputConfig        `yaml:"output"`
+	Logging       LoggingConfig       `yaml:"logging"`
+	Notifications NotificationsConfig `yaml:"notifications"`
+	Metrics       MetricsConfig       `yaml:"metrics"`
+	Exclude       []string            `yaml:"exclude"`
 }
 
 // SyncConfig holds sync-related settings
 type SyncConfig struct {
-	Mode               models.SyncMode               `yaml:"mode"`
-	Comparison         models.ComparisonMethod       `yaml:"comparison"`
-	ConflictResolution models.ConflictResolution     `yaml:"conflict_resolution"`
+	Mode               models.SyncMode           `yaml:"mode"`
+	Comparison         models.ComparisonMethod   `yaml:"comparison"`
+	ConflictResolution models.ConflictResolution `yaml:"conflict_resolution"`
+	Verify             bool                      `yaml:"verify"`              // Read back and hash copied files
+	VerifyRetries      int                       `yaml:"verify_retries"`      // Copies attempted again after a verification failure
+	ComparisonChain    []string                  `yaml:"comparison_chain"`    // Stages of the "chain" comparison method
+	ModifyWindow       string                    `yaml:"modify_window"`       // Duration, or "auto" to probe timestamp granularity
+	IgnoreHourOffsets  bool                      `yaml:"ignore_hour_offsets"` // Treat times differing by whole hours as equal
+	TextComparison     TextComparisonConfig      `yaml:"text_comparison"`
+	Links              string                    `yaml:"links"`      // Symbolic links: preserve, follow, skip or safe-follow
+	HardLinks          bool                      `yaml:"hard_links"` // Recreate files sharing an inode as hard links
+	Preserve           PreserveConfig            `yaml:"preserve"`
+	Durability         string                    `yaml:"durability"` // Destination fsync level: none, file or full
+}
+
+// PreserveConfig selects the metadata preserved besides modification times and permissions
+// Every setting is opt-in; ownership usually requires running as root
+type PreserveConfig struct {
+	Owner       bool `yaml:"owner"`        // Owner and group, mapped by name
+	NumericIDs  bool `yaml:"numeric_ids"`  // Map owners by numeric ID instead of name
+	SpecialBits bool `yaml:"special_bits"` // setuid, setgid and sticky bits
+	Xattrs      bool `yaml:"xattrs"`       // user, trusted and security extended attributes
+	ACLs        bool `yaml:"acls"`         // POSIX access and default ACLs
+}
+
+// MetadataOptions returns the storage options of the preserved metadata
+func (p PreserveConfig) MetadataOptions() storage.MetadataOptions {
+	return storage.MetadataOptions{
+		Owner:       p.Owner,
+		NumericIDs:  p.NumericIDs,
+		SpecialBits: p.SpecialBits,
+		Xattrs:      p.Xattrs,
+		ACLs:        p.ACLs,
+	}
+}
+
+// TextComparisonConfig holds settings of the text-aware comparison
+// Files matching the patterns are compared as text, ignoring line ending differences
+type TextComparisonConfig struct {
+	Patterns                 []string `yaml:"patterns"`                   // Globs of files compared as text (empty = disabled)
+	IgnoreTrailingWhitespace bool     `yaml:"ignore_trailing_whitespace"` // Ignore spaces and tabs at the end of lines
+	IgnoreBOM                bool     `yaml:"ignore_bom"`                 // Ignore a leading UTF-8 byte order mark
+}
+
+// ModifyWindowAuto probes the timestamp granularity of the synced filesystems
+const ModifyWindowAuto = "auto"
+
+// ParseModifyWindow parses a modify window setting
+// Returns 0 for "auto" and for an empty setting (default window)
+func ParseModifyWindow(s string) (time.Duration, error) {
+	if s == "" || s == ModifyWindowAuto {
+		return 0, nil
+	}
+	window, err := time.ParseDuration(s)
+	if err != nil || window <= 0 {
+		return 0, fmt.Errorf("invalid modify window: %s (use a positive duration such as 2s, or auto)", s)
+	}
+	return window, nil
 }
 
 // PerformanceConfig holds performance-related settings
@@ -25,11 +90,91 @@ type PerformanceConfig struct {
 	MaxWorkers     int   `yaml:"max_workers"`
 	BufferSize     int   `yaml:"buffer_size"`
 	BandwidthLimit int64 `yaml:"bandwidth_limit"`
+
+	// BandwidthSchedule sets time-of-day limits instead of BandwidthLimit,
+	// e.g. "Mon-Fri 08:00-18:00 2M; * unlimited"
+	BandwidthSchedule string `yaml:"bandwidth_schedule"`
+
+	// Independent limits applied on top of the bandwidth limit (0 = unlimited)
+	SourceReadLimit int64 `yaml:"source_read_limit"` // Bytes per second read from the source
+	DestReadLimit   int64 `yaml:"dest_read_limit"`   // Bytes per second read from the destination
+	WriteLimit      int64 `yaml:"write_limit"`       // Bytes per second written
+	OpsLimit        int64 `yaml:"ops_limit"`         // Storage operations (list, stat, open, write, delete) per second
+
+	Adaptive AdaptiveConfig `yaml:"adaptive"`
+}
+
+// AdaptiveConfig holds adaptive throttling settings
+// The active workers and the bandwidth limit shrink when writes are slow, files fail
+// or the host is loaded, and grow back within the bounds while everything is healthy
+type AdaptiveConfig struct {
+	Enabled       bool    `yaml:"enabled"`
+	MinWorkers    int     `yaml:"min_workers"`    // 0 = 1
+	MaxWorkers    int     `yaml:"max_workers"`    // 0 = performance.max_workers
+	MinBandwidth  int64   `yaml:"min_bandwidth"`  // Bytes per second, 0 = a tenth of max_bandwidth
+	MaxBandwidth  int64   `yaml:"max_bandwidth"`  // Bytes per second, 0 = bandwidth is not adapted
+	TargetLatency string  `yaml:"target_latency"` // Average destination write latency per chunk considered healthy
+	MaxErrorRate  float64 `yaml:"max_error_rate"` // Largest healthy fraction of failed files
+	MaxLoad       float64 `yaml:"max_load"`       // Largest healthy load average per CPU, 0 = ignore load
+	Interval      string  `yaml:"interval"`       // Time between adjustments
+}
+
+// Settings returns the controller bounds, or nil when adaptive throttling is disabled
+// maxWorkers is the configured worker count, used when no upper bound is set
+func (c AdaptiveConfig) Settings(maxWorkers int) (*models.AdaptiveThrottling, error) {
+	if !c.Enabled {
+		return nil, nil
+	}
+
+	settings := &models.AdaptiveThrottling{
+		MinWorkers:   max(c.MinWorkers, 1),
+		MaxWorkers:   c.MaxWorkers,
+		MinBandwidth: c.MinBandwidth,
+		MaxBandwidth: c.MaxBandwidth,
+		MaxErrorRate: c.MaxErrorRate,
+		MaxLoad:      c.MaxLoad,
+	}
+	if settings.MaxWorkers == 0 {
+		settings.MaxWorkers = max(maxWorkers, settings.MinWorkers)
+	}
+	if settings.MinBandwidth == 0 {
+		settings.MinBandwidth = settings.MaxBandwidth / 10
+	}
+
+	var err error
+	if settings.TargetLatency, err = parsePositiveDuration(c.TargetLatency, "100ms"); err != nil {
+		return nil, fmt.Errorf("invalid target latency: %w", err)
+	}
+	if settings.Interval, err = parsePositiveDuration(c.Interval, "2s"); err != nil {
+		return nil, fmt.Errorf("invalid interval: %w", err)
+	}
+	return settings, nil
+}
+
+// parsePositiveDuration parses a duration greater than zero, using fallback when s is empty
+func parsePositiveDuration(s, fallback string) (time.Duration, error) {
+	if s == "" {
+		s = fallback
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("must be greater than zero: %s", s)
+	}
+	return d, nil
+}
+
+// HashCacheConfig holds persistent hash cache settings
+type HashCacheConfig struct {
+	Mode string `yaml:"mode"` // "off", "db" or "xattr"
+	Dir  string `yaml:"dir"`  // Database directory for "db" mode (empty = user cache directory)
 }
 
 // OutputConfig holds output-related settings
 type OutputConfig struct {
-	Format   string `yaml:"format"`   // "human" or "json"
+	Format   string `yaml:"format"`   // "human", "json" or "ndjson"
 	Progress bool   `yaml:"progress"` // Show progress bars
 	Quiet    bool   `yaml:"quiet"`    // Suppress non-error output
 }
@@ -42,6 +187,30 @@ type LoggingConfig struct {
 	File    string `yaml:"file"`   // Log file path (empty = stderr)
 }
 
+// MetricsConfig holds Prometheus metrics export settings
+type MetricsConfig struct {
+	Textfile string `yaml:"textfile"` // Textfile collector output path (empty = disabled)
+	Job      string `yaml:"job"`      // Value of the job label
+}
+
+// NotificationsConfig holds notification targets fired on sync completion
+type NotificationsConfig struct {
+	Webhooks []WebhookConfig `yaml:"webhooks"`
+}
+
+// WebhookConfig holds settings for a single webhook target
+type WebhookConfig struct {
+	URL            string            `yaml:"url"`
+	Headers        map[string]string `yaml:"headers,omitempty"`
+	Secret         string            `yaml:"secret,omitempty"`        // HMAC-SHA256 signing key
+	BodyTemplate   string            `yaml:"body_template,omitempty"` // Go text/template (empty = JSON summary)
+	ContentType    string            `yaml:"content_type,omitempty"`  // Default: application/json
+	On             []string          `yaml:"on,omitempty"`            // success, partial, failed, cancelled, always
+	MaxRetries     int               `yaml:"max_retries"`
+	InitialBackoff time.Duration     `yaml:"initial_backoff"`
+	Timeout        time.Duration     `yaml:"timeout"`
+}
+
 // Default returns the default configuration
 func Default() *Config {
 	return &Config{
@@ -49,11 +218,25 @@ func Default() *Config {
 			Mode:               models.ModeOneWay,
 			Comparison:         models.CompareHash,
 			ConflictResolution: models.ConflictAsk,
+			VerifyRetries:      1,
+			ModifyWindow:       "1s",
+			Links:              string(storage.LinkPreserve),
+			HardLinks:          true,
+			Durability:         string(storage.DurabilityNone),
 		},
 		Performance: PerformanceConfig{
 			MaxWorkers:     5,
 			BufferSize:     65536,
 			BandwidthLimit: 0,
+			Adaptive: AdaptiveConfig{
+				TargetLatency: "100ms",
+				MaxErrorRate:  0.05,
+				MaxLoad:       1.0,
+				Interval:      "2s",
+			},
+		},
+		HashCache: HashCacheConfig{
+			Mode: "off",
 		},
 		Output: OutputConfig{
 			Format:   "human",
@@ -66,6 +249,9 @@ func Default() *Config {
 			Level:   "info",
 			File:    "",
 		},
+		Metrics: MetricsConfig{
+			Job: "syncnorris",
+		},
 		Exclude: []string{
 			"*.tmp",
 			".git/",
@@ -90,11 +276,114 @@ func (c *Config) Validate() error {
 		}
 	}
 
-	validFormats := map[string]bool{"human": true, "json": true}
+	if c.Performance.BandwidthSchedule != "" {
+		if _, err := ratelimit.ParseSchedule(c.Performance.BandwidthSchedule); err != nil {
+			return &models.ValidationError{
+				Field:   "performance.bandwidth_schedule",
+				Message: err.Error(),
+			}
+		}
+	}
+
+	limits := []struct {
+		field string
+		value int64
+	}{
+		{"performance.source_read_limit", c.Performance.SourceReadLimit},
+		{"performance.dest_read_limit", c.Performance.DestReadLimit},
+		{"performance.write_limit", c.Performance.WriteLimit},
+		{"performance.ops_limit", c.Performance.OpsLimit},
+	}
+	for _, limit := range limits {
+		if limit.value < 0 {
+			return &models.ValidationError{
+				Field:   limit.field,
+				Message: "must not be negative (0 = unlimited)",
+			}
+		}
+	}
+
+	if err := c.Performance.Adaptive.validate(c.Performance.BandwidthSchedule); err != nil {
+		return err
+	}
+
+	validComparisons := map[models.ComparisonMethod]bool{
+		models.CompareNameSize:  true,
+		models.CompareTimestamp: true,
+		models.CompareBinary:    true,
+		models.CompareHash:      true,
+		models.CompareMD5:       true,
+		models.CompareBLAKE3:    true,
+		models.CompareXXH3:      true,
+		models.CompareXXH128:    true,
+		models.CompareChain:     true,
+	}
+	if !validComparisons[c.Sync.Comparison] {
+		return &models.ValidationError{
+			Field:   "sync.comparison",
+			Message: "must be 'namesize', 'timestamp', 'binary', 'hash', 'md5', 'blake3', 'xxh3', 'xxh128', or 'chain'",
+		}
+	}
+
+	if c.Sync.Comparison == models.CompareChain {
+		if _, err := compare.ParseChain(c.Sync.ComparisonChain, c.Performance.BufferSize); err != nil {
+			return &models.ValidationError{
+				Field:   "sync.comparison_chain",
+				Message: err.Error(),
+			}
+		}
+	}
+
+	if c.Sync.VerifyRetries < 0 {
+		return &models.ValidationError{
+			Field:   "sync.verify_retries",
+			Message: "must not be negative",
+		}
+	}
+
+	for _, pattern := range c.Sync.TextComparison.Patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return &models.ValidationError{
+				Field:   "sync.text_comparison.patterns",
+				Message: fmt.Sprintf("invalid pattern: %s", pattern),
+			}
+		}
+	}
+
+	if _, err := ParseModifyWindow(c.Sync.ModifyWindow); err != nil {
+		return &models.ValidationError{
+			Field:   "sync.modify_window",
+			Message: "must be a positive duration (e.g. '2s') or 'auto'",
+		}
+	}
+
+	if _, err := storage.ParseLinkMode(c.Sync.Links); err != nil {
+		return &models.ValidationError{
+			Field:   "sync.links",
+			Message: "must be 'preserve', 'follow', 'skip' or 'safe-follow'",
+		}
+	}
+
+	if _, err := storage.ParseDurability(c.Sync.Durability); err != nil {
+		return &models.ValidationError{
+			Field:   "sync.durability",
+			Message: "must be 'none', 'file' or 'full'",
+		}
+	}
+
+	validCacheModes := map[string]bool{"": true, "off": true, "db": true, "xattr": true}
+	if !validCacheModes[c.HashCache.Mode] {
+		return &models.ValidationError{
+			Field:   "hash_cache.mode",
+			Message: "must be 'off', 'db', or 'xattr'",
+		}
+	}
+
+	validFormats := map[string]bool{"human": true, "json": true, "ndjson": true}
 	if !validFormats[c.Output.Format] {
 		return &models.ValidationError{
 			Field:   "output.format",
-			Message: "must be 'human' or 'json'",
+			Message: "must be 'human', 'json' or 'ndjson'",
 		}
 	}
 
@@ -114,5 +403,74 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	validStatuses := map[string]bool{"success": true, "partial": true, "failed": true, "cancelled": true, "always": true}
+	for i, wh := range c.Notifications.Webhooks {
+		if wh.URL == "" {
+			return &models.ValidationError{
+				Field:   fmt.Sprintf("notifications.webhooks[%d].url", i),
+				Message: "is required",
+			}
+		}
+		for _, on := range wh.On {
+			if !validStatuses[on] {
+				return &models.ValidationError{
+					Field:   fmt.Sprintf("notifications.webhooks[%d].on", i),
+					Message: "must be 'success', 'partial', 'failed', 'cancelled', or 'always'",
+				}
+			}
+		}
+		if wh.MaxRetries < 0 {
+			return &models.ValidationError{
+				Field:   fmt.Sprintf("notifications.webhooks[%d].max_retries", i),
+				Message: "must not be negative",
+			}
+		}
+	}
+
+	return nil
+}
+
+// validate checks the adaptive throttling bounds
+func (c AdaptiveConfig) validate(bandwidthSchedule string) error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.MinWorkers < 0 || c.MaxWorkers < 0 || (c.MaxWorkers > 0 && c.MinWorkers > c.MaxWorkers) {
+		return &models.ValidationError{
+			Field:   "performance.adaptive.min_workers",
+			Message: "worker bounds must not be negative and min_workers must not exceed max_workers",
+		}
+	}
+	if c.MinBandwidth < 0 || c.MaxBandwidth < 0 || (c.MaxBandwidth > 0 && c.MinBandwidth > c.MaxBandwidth) {
+		return &models.ValidationError{
+			Field:   "performance.adaptive.min_bandwidth",
+			Message: "bandwidth bounds must not be negative and min_bandwidth must not exceed max_bandwidth",
+		}
+	}
+	if c.MaxBandwidth > 0 && bandwidthSchedule != "" {
+		return &models.ValidationError{
+			Field:   "performance.adaptive.max_bandwidth",
+			Message: "cannot adapt the bandwidth while a bandwidth schedule is set",
+		}
+	}
+	if c.MaxErrorRate < 0 || c.MaxErrorRate > 1 {
+		return &models.ValidationError{
+			Field:   "performance.adaptive.max_error_rate",
+			Message: "must be between 0 and 1",
+		}
+	}
+	if c.MaxLoad < 0 {
+		return &models.ValidationError{
+			Field:   "performance.adaptive.max_load",
+			Message: "must not be negative (0 = ignore load)",
+		}
+	}
+	if _, err := c.Settings(1); err != nil {
+		return &models.ValidationError{
+			Field:   "performance.adaptive",
+			Message: err.Error(),
+		}
+	}
 	return nil
 }
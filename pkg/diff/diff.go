@@ -0,0 +1,225 @@
+// Package diff produces unified diffs of text files
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// DefaultContext is the number of unchanged lines shown around each change
+const DefaultContext = 3
+
+// maxTableCells bounds the memory used to align the changed region of two files
+// Larger regions are shown as a single replacement instead of a minimal diff
+const maxTableCells = 4 * 1024 * 1024
+
+// textSniffSize is the number of leading bytes inspected to decide whether data is text
+const textSniffSize = 8000
+
+// IsText reports whether data looks like text (no NUL byte in its first bytes, like git)
+func IsText(data []byte) bool {
+	if len(data) > textSniffSize {
+		data = data[:textSniffSize]
+	}
+	return bytes.IndexByte(data, 0) < 0
+}
+
+// SplitLines splits text into lines, each keeping its "\n" terminator
+// The last line has no terminator when the text does not end with a newline
+func SplitLines(data []byte) []string {
+	var lines []string
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			lines = append(lines, string(data))
+			break
+		}
+		lines = append(lines, string(data[:i+1]))
+		data = data[i+1:]
+	}
+	return lines
+}
+
+// op is one line of an edit script
+type op struct {
+	kind byte // ' ' unchanged, '-' removed, '+' added
+	line string
+}
+
+// Unified returns a unified diff turning lines a into lines b, or "" when they are equal
+// Lines are expected to come from SplitLines
+func Unified(oldName, newName string, a, b []string, context int) string {
+	ops := editScript(a, b)
+
+	changed := false
+	for _, o := range ops {
+		if o.kind != ' ' {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+
+	// Group changes closer than 2*context lines into the same hunk
+	for start := 0; start < len(ops); {
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+
+		last := first
+		for i := first; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				last = i
+			} else if i-last > 2*context {
+				break
+			}
+		}
+
+		hunkStart := max(first-context, start)
+		hunkEnd := min(last+context+1, len(ops))
+		writeHunk(&sb, ops, hunkStart, hunkEnd)
+		start = hunkEnd
+	}
+
+	return sb.String()
+}
+
+// writeHunk writes ops[from:to] as one hunk
+func writeHunk(sb *strings.Builder, ops []op, from, to int) {
+	// Line numbers where the hunk starts in each file
+	oldLine, newLine := 1, 1
+	for _, o := range ops[:from] {
+		if o.kind != '+' {
+			oldLine++
+		}
+		if o.kind != '-' {
+			newLine++
+		}
+	}
+
+	oldCount, newCount := 0, 0
+	for _, o := range ops[from:to] {
+		if o.kind != '+' {
+			oldCount++
+		}
+		if o.kind != '-' {
+			newCount++
+		}
+	}
+
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(oldLine, oldCount), hunkRange(newLine, newCount))
+	for _, o := range ops[from:to] {
+		sb.WriteByte(o.kind)
+		sb.WriteString(o.line)
+		if !strings.HasSuffix(o.line, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats the start,count part of a hunk header
+func hunkRange(start, count int) string {
+	if count == 0 {
+		// An empty range refers to the line before the hunk
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// editScript returns the operations turning a into b
+// Common leading and trailing lines are matched directly; the region in between is aligned
+// with a longest common subsequence when it is small enough
+func editScript(a, b []string) []op {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]op, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, op{' ', line})
+	}
+
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if len(midA)*len(midB) <= maxTableCells {
+		ops = append(ops, lcsScript(midA, midB)...)
+	} else {
+		for _, line := range midA {
+			ops = append(ops, op{'-', line})
+		}
+		for _, line := range midB {
+			ops = append(ops, op{'+', line})
+		}
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, op{' ', line})
+	}
+	return ops
+}
+
+// lcsScript aligns a and b with a longest common subsequence table
+func lcsScript(a, b []string) []op {
+	n, m := len(a), len(b)
+	// lcs[i*(m+1)+j] is the length of the LCS of a[i:] and b[j:]
+	lcs := make([]int32, (n+1)*(m+1))
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i*(m+1)+j] = lcs[(i+1)*(m+1)+j+1] + 1
+			} else {
+				lcs[i*(m+1)+j] = max(lcs[(i+1)*(m+1)+j], lcs[i*(m+1)+j+1])
+			}
+		}
+	}
+
+	ops := make([]op, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, op{' ', a[i]})
+			i++
+			j++
+		case lcs[(i+1)*(m+1)+j] >= lcs[i*(m+1)+j+1]:
+			ops = append(ops, op{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, op{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, op{'-', a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, op{'+', b[j]})
+	}
+	return ops
+}
+
+// Excerpt returns the first maxLines lines of a diff, noting how many lines were left out
+func Excerpt(diff string, maxLines int) string {
+	lines := strings.SplitAfter(strings.TrimSuffix(diff, "\n"), "\n")
+	if len(lines) <= maxLines {
+		return diff
+	}
+	return strings.Join(lines[:maxLines], "") + fmt.Sprintf("... (%d more lines)\n", len(lines)-maxLines)
+}
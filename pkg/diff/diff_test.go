@@ -0,0 +1,77 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestUnified tests unified diff generation
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{
+			name: "Equal",
+			a:    "one\ntwo\n",
+			b:    "one\ntwo\n",
+			want: "",
+		},
+		{
+			name: "ChangedLine",
+			a:    "one\ntwo\nthree\n",
+			b:    "one\nTWO\nthree\n",
+			want: "--- a\n+++ b\n@@ -1,3 +1,3 @@\n one\n-two\n+TWO\n three\n",
+		},
+		{
+			name: "AddedToEmpty",
+			a:    "",
+			b:    "one\n",
+			want: "--- a\n+++ b\n@@ -0,0 +1 @@\n+one\n",
+		},
+		{
+			name: "MissingFinalNewline",
+			a:    "one\n",
+			b:    "one",
+			want: "--- a\n+++ b\n@@ -1 +1 @@\n-one\n+one\n\\ No newline at end of file\n",
+		},
+		{
+			name: "SeparateHunks",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			b:    "x\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ny\n",
+			want: "--- a\n+++ b\n@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n 4\n@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+y\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Unified("a", "b", SplitLines([]byte(tt.a)), SplitLines([]byte(tt.b)), DefaultContext)
+			if got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestIsText tests text detection
+func TestIsText(t *testing.T) {
+	if !IsText([]byte("key: value\r\n")) {
+		t.Error("IsText() = false for text")
+	}
+	if IsText([]byte("PK\x03\x04\x00\x00")) {
+		t.Error("IsText() = true for binary data")
+	}
+}
+
+// TestExcerpt tests diff truncation
+func TestExcerpt(t *testing.T) {
+	diff := "--- a\n+++ b\n@@ -1 +1 @@\n-x\n+y\n"
+	if got := Excerpt(diff, 10); got != diff {
+		t.Errorf("Excerpt() = %q, want unchanged", got)
+	}
+	got := Excerpt(diff, 3)
+	if !strings.HasPrefix(got, "--- a\n+++ b\n@@ -1 +1 @@\n") || !strings.HasSuffix(got, "... (2 more lines)\n") {
+		t.Errorf("Excerpt() = %q", got)
+	}
+}
@@ -0,0 +1,55 @@
+package hashcache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrUnsupported is returned when a cache store is not available on this platform
+var ErrUnsupported = errors.New("hash cache store not supported on this platform")
+
+// Key identifies one version of a file
+// A cached hash is only reused when every field matches the current file
+type Key struct {
+	Path    string // Path relative to the backend root
+	Size    int64
+	ModTime time.Time
+	Inode   uint64 // Zero when the platform does not expose inodes
+	Device  uint64
+}
+
+// Matches reports whether two keys describe the same file version
+func (k Key) Matches(other Key) bool {
+	return k.Path == other.Path &&
+		k.Size == other.Size &&
+		k.ModTime.UnixNano() == other.ModTime.UnixNano() &&
+		k.Inode == other.Inode &&
+		k.Device == other.Device
+}
+
+// Entry is a cached file version with its hashes by algorithm
+type Entry struct {
+	Key
+	Hashes map[string]string
+}
+
+// Cache stores file hashes for a single backend
+// Implementations must be safe for concurrent use
+type Cache interface {
+	// Lookup returns the cached hash for the file version identified by key
+	Lookup(key Key, algorithm string) (string, bool)
+
+	// Store records the hash of the file version identified by key
+	// Hashes recorded for an older version of the file are discarded
+	Store(key Key, algorithm, hash string) error
+
+	// Entries returns all cached entries
+	Entries(ctx context.Context) ([]Entry, error)
+
+	// Remove deletes the cached entry for a path
+	Remove(path string) error
+
+	// Close flushes pending changes and releases resources
+	Close() error
+}
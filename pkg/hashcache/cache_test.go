@@ -0,0 +1,175 @@
+package hashcache
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func newTestKey(path string) Key {
+	return Key{
+		Path:    path,
+		Size:    1024,
+		ModTime: time.Unix(1700000000, 123456789),
+		Inode:   42,
+		Device:  7,
+	}
+}
+
+// TestKeyMatches tests file version matching
+func TestKeyMatches(t *testing.T) {
+	base := newTestKey("a.txt")
+
+	tests := []struct {
+		name   string
+		modify func(k *Key)
+		want   bool
+	}{
+		{"Identical", func(k *Key) {}, true},
+		{"Size", func(k *Key) { k.Size++ }, false},
+		{"ModTime", func(k *Key) { k.ModTime = k.ModTime.Add(time.Nanosecond) }, false},
+		{"Inode", func(k *Key) { k.Inode++ }, false},
+		{"Device", func(k *Key) { k.Device++ }, false},
+		{"Path", func(k *Key) { k.Path = "b.txt" }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := base
+			tt.modify(&other)
+			if got := base.Matches(other); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDB tests the database-backed hash cache
+func TestDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "hashes.db")
+	key := newTestKey("dir/a.txt")
+
+	t.Run("StoreAndReload", func(t *testing.T) {
+		db, err := OpenDB(path, "/data")
+		if err != nil {
+			t.Fatalf("OpenDB() error = %v", err)
+		}
+		db.Store(key, "sha256", "abc")
+		db.Store(key, "md5", "def")
+		if err := db.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+
+		db, err = OpenDB(path, "/data")
+		if err != nil {
+			t.Fatalf("OpenDB() error = %v", err)
+		}
+		defer db.Close()
+
+		if hash, ok := db.Lookup(key, "sha256"); !ok || hash != "abc" {
+			t.Errorf("Lookup(sha256) = %q, %v, want abc, true", hash, ok)
+		}
+		if hash, ok := db.Lookup(key, "md5"); !ok || hash != "def" {
+			t.Errorf("Lookup(md5) = %q, %v, want def, true", hash, ok)
+		}
+	})
+
+	t.Run("ChangedFileMisses", func(t *testing.T) {
+		db, _ := OpenDB(path, "/data")
+		defer db.Close()
+
+		changed := key
+		changed.ModTime = changed.ModTime.Add(time.Second)
+		if _, ok := db.Lookup(changed, "sha256"); ok {
+			t.Error("Lookup() should miss for a changed file")
+		}
+
+		// Storing the new version discards hashes of the old one
+		db.Store(changed, "sha256", "new")
+		if _, ok := db.Lookup(changed, "md5"); ok {
+			t.Error("hashes of the previous version should be discarded")
+		}
+	})
+
+	t.Run("EntriesAndRemove", func(t *testing.T) {
+		db, _ := OpenDB(filepath.Join(t.TempDir(), "hashes.db"), "/data")
+		db.Store(newTestKey("b.txt"), "sha256", "b")
+		db.Store(newTestKey("a.txt"), "sha256", "a")
+
+		entries, err := db.Entries(context.Background())
+		if err != nil {
+			t.Fatalf("Entries() error = %v", err)
+		}
+		if len(entries) != 2 || entries[0].Path != "a.txt" {
+			t.Fatalf("Entries() = %+v, want a.txt and b.txt sorted", entries)
+		}
+
+		db.Remove("a.txt")
+		entries, _ = db.Entries(context.Background())
+		if len(entries) != 1 || entries[0].Path != "b.txt" {
+			t.Errorf("Entries() after Remove = %+v, want only b.txt", entries)
+		}
+	})
+
+	t.Run("DefaultPathIsPerRoot", func(t *testing.T) {
+		if DBFileName("/data/a") == DBFileName("/data/b") {
+			t.Error("different roots should use different database files")
+		}
+		if DBFileName("/data/a/") != DBFileName("/data/a") {
+			t.Error("equivalent roots should use the same database file")
+		}
+	})
+}
+
+// TestXattr tests the extended attribute hash cache
+func TestXattr(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.txt"), []byte("data"), 0644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	cache, err := NewXattr(root)
+	if errors.Is(err, ErrUnsupported) {
+		t.Skip("extended attributes not supported on this platform")
+	}
+	if err != nil {
+		t.Fatalf("NewXattr() error = %v", err)
+	}
+
+	key := newTestKey("a.txt")
+	if err := cache.Store(key, "sha256", "abc"); err != nil {
+		if errors.Is(err, syscall.ENOTSUP) || errors.Is(err, syscall.EPERM) {
+			t.Skipf("filesystem does not support user extended attributes: %v", err)
+		}
+		t.Fatalf("Store() error = %v", err)
+	}
+
+	if hash, ok := cache.Lookup(key, "sha256"); !ok || hash != "abc" {
+		t.Errorf("Lookup() = %q, %v, want abc, true", hash, ok)
+	}
+
+	changed := key
+	changed.Size++
+	if _, ok := cache.Lookup(changed, "sha256"); ok {
+		t.Error("Lookup() should miss for a changed file")
+	}
+
+	entries, err := cache.Entries(context.Background())
+	if err != nil {
+		t.Fatalf("Entries() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].Hashes["sha256"] != "abc" {
+		t.Errorf("Entries() = %+v, want one entry with sha256 abc", entries)
+	}
+
+	if err := cache.Remove("a.txt"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, ok := cache.Lookup(key, "sha256"); ok {
+		t.Error("Lookup() should miss after Remove()")
+	}
+}
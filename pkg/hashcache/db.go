@@ -0,0 +1,214 @@
+package hashcache
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const dbFileVersion = 1
+
+// dbFile is the on-disk layout of a hash cache database
+// gob is used rather than JSON because caches can hold millions of entries
+type dbFile struct {
+	Version int
+	Root    string
+	Entries map[string]*dbRecord
+}
+
+// dbRecord is a single cached file version
+type dbRecord struct {
+	Size    int64
+	ModTime int64 // Unix nanoseconds
+	Inode   uint64
+	Device  uint64
+	Hashes  map[string]string
+}
+
+// DB is a hash cache persisted in a local database file
+// Entries are held in memory and written back atomically on Close
+type DB struct {
+	path string
+	root string
+
+	mu      sync.RWMutex
+	entries map[string]*dbRecord
+	dirty   bool
+}
+
+// DefaultDBPath returns the default database location for a backend root
+func DefaultDBPath(root string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine cache directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "syncnorris", "hashcache", DBFileName(root)), nil
+}
+
+// DBFileName returns a deterministic database file name for a backend root
+func DBFileName(root string) string {
+	h := fnv.New64a()
+	h.Write([]byte(filepath.Clean(root)))
+	return fmt.Sprintf("%016x.db", h.Sum64())
+}
+
+// OpenDB opens the hash cache database at path for the given backend root
+// A missing file yields an empty cache that is created on Close
+func OpenDB(path, root string) (*DB, error) {
+	db := &DB{
+		path:    path,
+		root:    root,
+		entries: make(map[string]*dbRecord),
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return db, nil
+		}
+		return nil, fmt.Errorf("failed to open hash cache: %w", err)
+	}
+	defer f.Close()
+
+	var data dbFile
+	if err := gob.NewDecoder(f).Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to parse hash cache %s: %w", path, err)
+	}
+	if data.Version > dbFileVersion {
+		return nil, fmt.Errorf("hash cache version %d is newer than supported version %d", data.Version, dbFileVersion)
+	}
+	if data.Entries != nil {
+		db.entries = data.Entries
+	}
+
+	return db, nil
+}
+
+// Path returns the database file path
+func (d *DB) Path() string {
+	return d.path
+}
+
+// Lookup returns the cached hash for the file version identified by key
+func (d *DB) Lookup(key Key, algorithm string) (string, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	rec, ok := d.entries[key.Path]
+	if !ok || !rec.key(key.Path).Matches(key) {
+		return "", false
+	}
+	hash, ok := rec.Hashes[algorithm]
+	return hash, ok
+}
+
+// Store records the hash of the file version identified by key
+func (d *DB) Store(key Key, algorithm, hash string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	rec, ok := d.entries[key.Path]
+	if !ok || !rec.key(key.Path).Matches(key) {
+		rec = &dbRecord{
+			Size:    key.Size,
+			ModTime: key.ModTime.UnixNano(),
+			Inode:   key.Inode,
+			Device:  key.Device,
+			Hashes:  make(map[string]string),
+		}
+		d.entries[key.Path] = rec
+	}
+	rec.Hashes[algorithm] = hash
+	d.dirty = true
+
+	return nil
+}
+
+// Entries returns all cached entries sorted by path
+func (d *DB) Entries(ctx context.Context) ([]Entry, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	entries := make([]Entry, 0, len(d.entries))
+	for path, rec := range d.entries {
+		hashes := make(map[string]string, len(rec.Hashes))
+		for algo, h := range rec.Hashes {
+			hashes[algo] = h
+		}
+		entries = append(entries, Entry{Key: rec.key(path), Hashes: hashes})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+
+	return entries, nil
+}
+
+// Remove deletes the cached entry for a path
+func (d *DB) Remove(path string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.entries[path]; ok {
+		delete(d.entries, path)
+		d.dirty = true
+	}
+	return nil
+}
+
+// Close writes the database back to disk if it changed
+func (d *DB) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.dirty {
+		return nil
+	}
+
+	dir := filepath.Dir(d.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create hash cache directory: %w", err)
+	}
+
+	// Write atomically using temp file
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(d.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create hash cache file: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	data := dbFile{Version: dbFileVersion, Root: d.root, Entries: d.entries}
+	if err := gob.NewEncoder(tmp).Encode(&data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write hash cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write hash cache: %w", err)
+	}
+	if err := os.Rename(tmpPath, d.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to finalize hash cache: %w", err)
+	}
+
+	d.dirty = false
+	return nil
+}
+
+// key rebuilds the lookup key of a record
+func (r *dbRecord) key(path string) Key {
+	return Key{
+		Path:    path,
+		Size:    r.Size,
+		ModTime: time.Unix(0, r.ModTime),
+		Inode:   r.Inode,
+		Device:  r.Device,
+	}
+}
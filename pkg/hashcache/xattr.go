@@ -0,0 +1,194 @@
+package hashcache
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// xattrPrefix is the extended attribute namespace used for cached hashes
+// The algorithm name is appended, e.g. "user.syncnorris.hash.sha256"
+const xattrPrefix = "user.syncnorris.hash."
+
+// xattrValueVersion is the format version of stored attribute values
+const xattrValueVersion = "1"
+
+// Xattr is a hash cache stored in user extended attributes on the files themselves
+// The cache travels with the file and needs no separate database, but requires
+// write access to the backend and a filesystem with user xattr support
+type Xattr struct {
+	root string
+}
+
+// NewXattr creates an extended attribute hash cache for a backend root
+func NewXattr(root string) (*Xattr, error) {
+	if !xattrSupported {
+		return nil, ErrUnsupported
+	}
+	return &Xattr{root: root}, nil
+}
+
+// Lookup returns the cached hash for the file version identified by key
+func (x *Xattr) Lookup(key Key, algorithm string) (string, bool) {
+	value, err := getxattr(x.fullPath(key.Path), xattrPrefix+algorithm)
+	if err != nil {
+		return "", false
+	}
+	stored, hash, err := decodeXattrValue(key.Path, value)
+	if err != nil || !stored.Matches(key) {
+		return "", false
+	}
+	return hash, true
+}
+
+// Store records the hash of the file version identified by key
+func (x *Xattr) Store(key Key, algorithm, hash string) error {
+	if err := setxattr(x.fullPath(key.Path), xattrPrefix+algorithm, encodeXattrValue(key, hash)); err != nil {
+		return fmt.Errorf("failed to store hash attribute: %w", err)
+	}
+	return nil
+}
+
+// Entries walks the backend root and returns the cached entries of every file
+// Only attributes matching the first recorded file version are grouped into an entry
+func (x *Xattr) Entries(ctx context.Context) ([]Entry, error) {
+	var entries []Entry
+
+	err := filepath.WalkDir(x.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Skip inaccessible files and directories
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		names, err := listxattr(path)
+		if err != nil {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(x.root, path)
+		if err != nil {
+			return nil
+		}
+
+		var entry *Entry
+		for _, name := range names {
+			if !strings.HasPrefix(name, xattrPrefix) {
+				continue
+			}
+			value, err := getxattr(path, name)
+			if err != nil {
+				continue
+			}
+			key, hash, err := decodeXattrValue(relPath, value)
+			if err != nil {
+				continue
+			}
+			if entry == nil {
+				entry = &Entry{Key: key, Hashes: make(map[string]string)}
+			} else if !entry.Key.Matches(key) {
+				// Attribute recorded for another version of the file, always stale
+				continue
+			}
+			entry.Hashes[strings.TrimPrefix(name, xattrPrefix)] = hash
+		}
+		if entry != nil {
+			entries = append(entries, *entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+	return entries, nil
+}
+
+// Remove deletes all cached hash attributes of a file
+func (x *Xattr) Remove(path string) error {
+	fullPath := x.fullPath(path)
+
+	names, err := listxattr(fullPath)
+	if err != nil {
+		return fmt.Errorf("failed to list attributes: %w", err)
+	}
+	for _, name := range names {
+		if !strings.HasPrefix(name, xattrPrefix) {
+			continue
+		}
+		if err := removexattr(fullPath, name); err != nil {
+			return fmt.Errorf("failed to remove hash attribute: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close is a no-op; attributes are written immediately
+func (x *Xattr) Close() error {
+	return nil
+}
+
+// fullPath resolves a relative path against the backend root
+func (x *Xattr) fullPath(path string) string {
+	return filepath.Join(x.root, path)
+}
+
+// encodeXattrValue serializes a key and hash as "version size mtime inode device hash"
+func encodeXattrValue(key Key, hash string) []byte {
+	return []byte(strings.Join([]string{
+		xattrValueVersion,
+		strconv.FormatInt(key.Size, 10),
+		strconv.FormatInt(key.ModTime.UnixNano(), 10),
+		strconv.FormatUint(key.Inode, 10),
+		strconv.FormatUint(key.Device, 10),
+		hash,
+	}, " "))
+}
+
+// decodeXattrValue parses a value written by encodeXattrValue
+func decodeXattrValue(path string, value []byte) (Key, string, error) {
+	fields := strings.Fields(string(value))
+	if len(fields) != 6 || fields[0] != xattrValueVersion {
+		return Key{}, "", fmt.Errorf("invalid hash attribute value")
+	}
+
+	size, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return Key{}, "", fmt.Errorf("invalid size: %w", err)
+	}
+	modTime, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return Key{}, "", fmt.Errorf("invalid modification time: %w", err)
+	}
+	inode, err := strconv.ParseUint(fields[3], 10, 64)
+	if err != nil {
+		return Key{}, "", fmt.Errorf("invalid inode: %w", err)
+	}
+	device, err := strconv.ParseUint(fields[4], 10, 64)
+	if err != nil {
+		return Key{}, "", fmt.Errorf("invalid device: %w", err)
+	}
+
+	return Key{
+		Path:    path,
+		Size:    size,
+		ModTime: time.Unix(0, modTime),
+		Inode:   inode,
+		Device:  device,
+	}, fields[5], nil
+}
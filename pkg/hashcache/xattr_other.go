@@ -0,0 +1,21 @@
+//go:build !(linux || darwin || freebsd || netbsd)
+
+package hashcache
+
+const xattrSupported = false
+
+func getxattr(path, name string) ([]byte, error) {
+	return nil, ErrUnsupported
+}
+
+func setxattr(path, name string, value []byte) error {
+	return ErrUnsupported
+}
+
+func listxattr(path string) ([]string, error) {
+	return nil, ErrUnsupported
+}
+
+func removexattr(path, name string) error {
+	return ErrUnsupported
+}
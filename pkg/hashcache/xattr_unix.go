@@ -0,0 +1,59 @@
+//go:build linux || darwin || freebsd || netbsd
+
+package hashcache
+
+import (
+	"bytes"
+
+	"golang.org/x/sys/unix"
+)
+
+const xattrSupported = true
+
+// getxattr reads an extended attribute, growing the buffer as needed
+func getxattr(path, name string) ([]byte, error) {
+	size, err := unix.Getxattr(path, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	n, err := unix.Getxattr(path, name, buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// setxattr writes an extended attribute
+func setxattr(path, name string, value []byte) error {
+	return unix.Setxattr(path, name, value, 0)
+}
+
+// listxattr returns the names of all extended attributes of a file
+func listxattr(path string) ([]string, error) {
+	size, err := unix.Listxattr(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if size == 0 {
+		return nil, nil
+	}
+	buf := make([]byte, size)
+	n, err := unix.Listxattr(path, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, name := range bytes.Split(buf[:n], []byte{0}) {
+		if len(name) > 0 {
+			names = append(names, string(name))
+		}
+	}
+	return names, nil
+}
+
+// removexattr deletes an extended attribute
+func removexattr(path, name string) error {
+	return unix.Removexattr(path, name)
+}
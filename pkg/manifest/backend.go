@@ -0,0 +1,146 @@
+package manifest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+// ErrReadOnly is returned when writing to or reading content from a manifest backend
+var ErrReadOnly = errors.New("manifest backend only provides checksums")
+
+// Backend exposes a manifest as a read-only storage backend
+// It lists the manifest's files and implements storage.Checksummer, so it can be the
+// source side of a comparison using compare.ChecksumComparator. File content is not available
+type Backend struct {
+	manifest *Manifest
+	dirs     map[string]bool
+}
+
+// NewBackend creates a backend listing the files of a manifest
+func NewBackend(m *Manifest) *Backend {
+	dirs := make(map[string]bool)
+	for _, e := range m.Entries {
+		for dir := path.Dir(e.Path); dir != "." && dir != "/" && !dirs[dir]; dir = path.Dir(dir) {
+			dirs[dir] = true
+		}
+	}
+	return &Backend{manifest: m, dirs: dirs}
+}
+
+// Manifest returns the manifest behind the backend
+func (b *Backend) Manifest() *Manifest {
+	return b.manifest
+}
+
+// List returns the manifest's files and their parent directories
+func (b *Backend) List(ctx context.Context, dir string) ([]storage.FileInfo, error) {
+	prefix := filepath.ToSlash(dir)
+	if prefix == "." {
+		prefix = ""
+	}
+	within := func(p string) bool {
+		return prefix == "" || p == prefix || (len(p) > len(prefix) && p[:len(prefix)] == prefix && p[len(prefix)] == '/')
+	}
+
+	var files []storage.FileInfo
+	for d := range b.dirs {
+		if within(d) {
+			files = append(files, storage.FileInfo{
+				Path:         d,
+				IsDir:        true,
+				RelativePath: filepath.FromSlash(d),
+			})
+		}
+	}
+	for _, e := range b.manifest.Entries {
+		if within(e.Path) {
+			files = append(files, b.fileInfo(e))
+		}
+	}
+	return files, nil
+}
+
+// Read is not supported: a manifest holds hashes, not content
+func (b *Backend) Read(ctx context.Context, path string) (io.ReadCloser, error) {
+	return nil, ErrReadOnly
+}
+
+// Write is not supported
+func (b *Backend) Write(ctx context.Context, path string, reader io.Reader, size int64, metadata *storage.FileInfo) error {
+	return ErrReadOnly
+}
+
+// Delete is not supported
+func (b *Backend) Delete(ctx context.Context, path string) error {
+	return ErrReadOnly
+}
+
+// Exists checks if the manifest lists a file or directory
+func (b *Backend) Exists(ctx context.Context, p string) (bool, error) {
+	p = filepath.ToSlash(p)
+	if _, ok := b.manifest.Lookup(p); ok {
+		return true, nil
+	}
+	return b.dirs[p], nil
+}
+
+// Stat returns the recorded metadata of a file
+func (b *Backend) Stat(ctx context.Context, p string) (*storage.FileInfo, error) {
+	p = filepath.ToSlash(p)
+	if e, ok := b.manifest.Lookup(p); ok {
+		info := b.fileInfo(e)
+		return &info, nil
+	}
+	if b.dirs[p] {
+		return &storage.FileInfo{Path: p, IsDir: true, RelativePath: filepath.FromSlash(p)}, nil
+	}
+	return nil, fmt.Errorf("file not in manifest: %s", p)
+}
+
+// MkdirAll is not supported
+func (b *Backend) MkdirAll(ctx context.Context, path string) error {
+	return ErrReadOnly
+}
+
+// Symlink is not supported
+func (b *Backend) Symlink(ctx context.Context, path string, target string) error {
+	return ErrReadOnly
+}
+
+// Close releases any resources held by the backend
+func (b *Backend) Close() error {
+	return nil
+}
+
+// Checksum returns the recorded hash of a file
+func (b *Backend) Checksum(p string) (storage.Checksum, bool) {
+	e, ok := b.manifest.Lookup(p)
+	if !ok {
+		return storage.Checksum{}, false
+	}
+	return storage.Checksum{
+		Algorithm: b.manifest.Algorithm,
+		Hash:      e.Hash,
+		Size:      e.Size,
+	}, true
+}
+
+// fileInfo converts an entry to file metadata; unknown sizes are reported as 0
+func (b *Backend) fileInfo(e Entry) storage.FileInfo {
+	size := e.Size
+	if size == SizeUnknown {
+		size = 0
+	}
+	return storage.FileInfo{
+		Path:         e.Path,
+		Size:         size,
+		ModTime:      e.ModTime,
+		RelativePath: filepath.FromSlash(e.Path),
+	}
+}
@@ -0,0 +1,270 @@
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sdejongh/syncnorris/pkg/compare"
+)
+
+// Manifest file formats
+const (
+	// FormatSum is the GNU coreutils format: "<hash>  <path>" (sha256sum, md5sum, b3sum)
+	FormatSum = "sum"
+	// FormatBSD is the BSD tagged format: "SHA256 (<path>) = <hash>" (shasum --tag, xxhsum --tag)
+	FormatBSD = "bsd"
+	// FormatJSON is a JSON document that also records sizes and modification times
+	FormatJSON = "json"
+)
+
+// bsdTags maps algorithms to the tags used by the BSD format
+var bsdTags = map[string]string{
+	compare.AlgorithmSHA256: "SHA256",
+	compare.AlgorithmMD5:    "MD5",
+	compare.AlgorithmBLAKE3: "BLAKE3",
+	compare.AlgorithmXXH3:   "XXH3",
+	compare.AlgorithmXXH128: "XXH128",
+}
+
+// FormatForPath returns the format implied by a manifest file name (json for *.json, sum otherwise)
+func FormatForPath(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return FormatJSON
+	}
+	return FormatSum
+}
+
+// Write writes a manifest in the given format
+func Write(w io.Writer, m *Manifest, format string) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(m)
+
+	case FormatSum, FormatBSD:
+		tag, ok := bsdTags[m.Algorithm]
+		if !ok {
+			return fmt.Errorf("unsupported hash algorithm: %s", m.Algorithm)
+		}
+		bw := bufio.NewWriter(w)
+		for _, e := range m.Entries {
+			path, escaped := escapePath(e.Path)
+			prefix := ""
+			if escaped {
+				prefix = `\`
+			}
+			if format == FormatBSD {
+				fmt.Fprintf(bw, "%s%s (%s) = %s\n", prefix, tag, path, e.Hash)
+			} else {
+				fmt.Fprintf(bw, "%s%s  %s\n", prefix, e.Hash, path)
+			}
+		}
+		return bw.Flush()
+
+	default:
+		return fmt.Errorf("unsupported manifest format: %s (valid: sum, bsd, json)", format)
+	}
+}
+
+// Save writes a manifest to a file
+func Save(path string, m *Manifest, format string) error {
+	var buf bytes.Buffer
+	if err := Write(&buf, m, format); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// Load reads a manifest file, detecting its format
+// See Parse for the meaning of algorithm
+func Load(path, algorithm string) (*Manifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest: %w", err)
+	}
+	defer f.Close()
+
+	m, err := Parse(f, algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// Parse reads a manifest in any supported format
+// algorithm names the hash algorithm of sum-format manifests, which don't record it;
+// when empty it is guessed from the hash length (sha256, md5 or xxh3). For the BSD and
+// JSON formats a non-empty algorithm must match the one recorded in the manifest
+func Parse(r io.Reader, algorithm string) (*Manifest, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var m *Manifest
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		m = &Manifest{}
+		if err := json.Unmarshal(trimmed, m); err != nil {
+			return nil, err
+		}
+		if m.Version > Version {
+			return nil, fmt.Errorf("manifest version %d is newer than supported version %d", m.Version, Version)
+		}
+	} else if m, err = parseLines(data); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case m.Algorithm == "" && algorithm != "":
+		m.Algorithm = algorithm
+	case m.Algorithm == "":
+		m.Algorithm = guessAlgorithm(m.Entries)
+		if m.Algorithm == "" {
+			return nil, fmt.Errorf("cannot determine the hash algorithm, specify it explicitly")
+		}
+	case algorithm != "" && algorithm != m.Algorithm:
+		return nil, fmt.Errorf("manifest uses %s, not %s", m.Algorithm, algorithm)
+	}
+	if _, err := compare.NewHasher(m.Algorithm); err != nil {
+		return nil, err
+	}
+
+	for i := range m.Entries {
+		m.Entries[i].Hash = strings.ToLower(m.Entries[i].Hash)
+		if _, err := hex.DecodeString(m.Entries[i].Hash); err != nil {
+			return nil, fmt.Errorf("invalid hash for %s", m.Entries[i].Path)
+		}
+	}
+	m.Version = Version
+	m.sortEntries()
+
+	return m, nil
+}
+
+// parseLines parses the sum and BSD formats
+// The algorithm is left empty for the sum format
+func parseLines(data []byte) (*Manifest, error) {
+	m := &Manifest{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		escaped := strings.HasPrefix(line, `\`)
+		if escaped {
+			line = line[1:]
+		}
+
+		var algorithm, path, hash string
+		if open := strings.Index(line, " ("); open > 0 && strings.Contains(line, ") = ") && !strings.Contains(line[:open], " ") {
+			// BSD: TAG (path) = hash
+			sep := strings.LastIndex(line, ") = ")
+			algorithm = algorithmForTag(line[:open])
+			if algorithm == "" {
+				return nil, fmt.Errorf("line %d: unsupported hash algorithm %q", lineNo, line[:open])
+			}
+			path = line[open+2 : sep]
+			hash = line[sep+4:]
+		} else {
+			// GNU: hash  path (text mode) or hash *path (binary mode)
+			sep := strings.Index(line, " ")
+			if sep < 0 || sep+1 >= len(line) || (line[sep+1] != ' ' && line[sep+1] != '*') {
+				return nil, fmt.Errorf("line %d: unrecognized checksum line", lineNo)
+			}
+			hash = line[:sep]
+			path = line[sep+2:]
+		}
+
+		if escaped {
+			path = unescapePath(path)
+		}
+		path = strings.TrimPrefix(filepath.ToSlash(path), "./")
+
+		if algorithm != "" {
+			if m.Algorithm != "" && m.Algorithm != algorithm {
+				return nil, fmt.Errorf("line %d: manifest mixes %s and %s hashes", lineNo, m.Algorithm, algorithm)
+			}
+			m.Algorithm = algorithm
+		}
+		m.Entries = append(m.Entries, Entry{Path: path, Hash: hash, Size: SizeUnknown})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// algorithmForTag returns the algorithm of a BSD tag
+func algorithmForTag(tag string) string {
+	for algorithm, t := range bsdTags {
+		if strings.EqualFold(t, tag) {
+			return algorithm
+		}
+	}
+	return ""
+}
+
+// guessAlgorithm infers the algorithm of sum-format hashes from their length
+// 64 hex digits are assumed to be SHA-256 and 32 to be MD5; BLAKE3 and XXH128
+// manifests share those lengths and must be named explicitly
+func guessAlgorithm(entries []Entry) string {
+	if len(entries) == 0 {
+		return compare.AlgorithmSHA256
+	}
+	switch len(entries[0].Hash) {
+	case 64:
+		return compare.AlgorithmSHA256
+	case 32:
+		return compare.AlgorithmMD5
+	case 16:
+		return compare.AlgorithmXXH3
+	}
+	return ""
+}
+
+// escapePath escapes backslashes and newlines the way GNU coreutils does
+// The boolean reports whether the line must be prefixed with a backslash
+func escapePath(path string) (string, bool) {
+	if !strings.ContainsAny(path, "\\\n\r") {
+		return path, false
+	}
+	r := strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`)
+	return r.Replace(path), true
+}
+
+// unescapePath reverses escapePath
+func unescapePath(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '\\' && i+1 < len(path) {
+			i++
+			switch path[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			default:
+				b.WriteByte(path[i])
+			}
+			continue
+		}
+		b.WriteByte(path[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,272 @@
+// Package manifest creates and verifies checksum manifests of directory trees
+// Manifests can be written in the sha256sum (GNU), BSD tagged and JSON formats
+package manifest
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sdejongh/syncnorris/internal/parallel"
+	"github.com/sdejongh/syncnorris/pkg/compare"
+	"github.com/sdejongh/syncnorris/pkg/models"
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+// Version is the version of the JSON manifest format
+const Version = 1
+
+// SizeUnknown is the size of entries read from formats that don't record sizes
+const SizeUnknown = -1
+
+// Entry is the recorded hash of one file
+type Entry struct {
+	Path    string    `json:"path"` // Relative path with forward slashes
+	Hash    string    `json:"hash"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time,omitempty"`
+}
+
+// Manifest lists the hashes of all files in a tree
+type Manifest struct {
+	Version   int       `json:"version"`
+	Algorithm string    `json:"algorithm"`
+	Root      string    `json:"root,omitempty"`
+	Created   time.Time `json:"created,omitempty"`
+	Entries   []Entry   `json:"entries"`
+}
+
+// Options configures manifest creation and verification
+type Options struct {
+	// Workers is the number of files hashed in parallel (default: 1)
+	Workers int
+
+	// Skip lists relative paths to leave out (e.g., the manifest file itself)
+	Skip []string
+
+	// Progress is called after each file is hashed (optional, may be called concurrently)
+	Progress func(path string)
+}
+
+// Lookup returns the entry for a relative path
+func (m *Manifest) Lookup(path string) (Entry, bool) {
+	path = filepath.ToSlash(path)
+	i := sort.Search(len(m.Entries), func(i int) bool { return m.Entries[i].Path >= path })
+	if i < len(m.Entries) && m.Entries[i].Path == path {
+		return m.Entries[i], true
+	}
+	return Entry{}, false
+}
+
+// sortEntries sorts entries by path so Lookup can binary search
+func (m *Manifest) sortEntries() {
+	sort.Slice(m.Entries, func(i, j int) bool { return m.Entries[i].Path < m.Entries[j].Path })
+}
+
+// Create hashes every file of a backend and returns the manifest
+func Create(ctx context.Context, backend storage.Backend, algorithm string, opts Options) (*Manifest, error) {
+	if _, err := compare.NewHasher(algorithm); err != nil {
+		return nil, err
+	}
+
+	files, err := listFiles(ctx, backend, opts.Skip)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{
+		Version:   Version,
+		Algorithm: algorithm,
+		Created:   time.Now().UTC(),
+		Entries:   make([]Entry, len(files)),
+	}
+	if local, ok := backend.(*storage.Local); ok {
+		m.Root = local.RootPath()
+	}
+
+	err = parallel.ForEach(ctx, len(files), opts.Workers, func(i int) error {
+		f := &files[i]
+		hash, err := compare.HashFile(ctx, backend, f, algorithm, nil)
+		if err != nil {
+			return fmt.Errorf("failed to hash %s: %w", f.RelativePath, err)
+		}
+		m.Entries[i] = Entry{
+			Path:    filepath.ToSlash(f.RelativePath),
+			Hash:    hash,
+			Size:    f.Size,
+			ModTime: f.ModTime.UTC(),
+		}
+		if opts.Progress != nil {
+			opts.Progress(f.RelativePath)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m.sortEntries()
+	return m, nil
+}
+
+// Verify checks a backend against a manifest
+// Missing files are reported as only_in_source, files not listed in the manifest as
+// only_in_dest, and changed files as size_different or hash_different. Files that
+// cannot be read are reported as errors. The report status is partial when anything
+// differs or failed
+func Verify(ctx context.Context, m *Manifest, backend storage.Backend, opts Options) (*models.SyncReport, error) {
+	report := &models.SyncReport{
+		StartTime: time.Now(),
+		Mode:      models.ModeOneWay,
+		DryRun:    true,
+	}
+
+	files, err := listFiles(ctx, backend, opts.Skip)
+	if err != nil {
+		return nil, err
+	}
+
+	present := make(map[string]*storage.FileInfo, len(files))
+	for i := range files {
+		present[filepath.ToSlash(files[i].RelativePath)] = &files[i]
+	}
+
+	var mu sync.Mutex
+	addDifference := func(diff models.FileDifference) {
+		mu.Lock()
+		report.Differences = append(report.Differences, diff)
+		mu.Unlock()
+	}
+
+	err = parallel.ForEach(ctx, len(m.Entries), opts.Workers, func(i int) error {
+		entry := m.Entries[i]
+		expected := &models.FileInfo{
+			Hash:          entry.Hash,
+			HashAlgorithm: m.Algorithm,
+			ModTime:       entry.ModTime,
+		}
+		if entry.Size != SizeUnknown {
+			expected.Size = entry.Size
+		}
+
+		f, ok := present[entry.Path]
+		if !ok {
+			addDifference(models.FileDifference{
+				RelativePath: entry.Path,
+				Reason:       models.ReasonOnlyInSource,
+				Details:      "listed in manifest but missing",
+				SourceInfo:   expected,
+			})
+			return nil
+		}
+
+		actual := &models.FileInfo{Size: f.Size, ModTime: f.ModTime}
+		if entry.Size != SizeUnknown && entry.Size != f.Size {
+			addDifference(models.FileDifference{
+				RelativePath: entry.Path,
+				Reason:       models.ReasonSizeDiff,
+				Details:      fmt.Sprintf("size is %d, manifest has %d", f.Size, entry.Size),
+				SourceInfo:   expected,
+				DestInfo:     actual,
+			})
+			return nil
+		}
+
+		// The content is always read: a cached hash would hide corruption keeping the size and mtime
+		hash, err := compare.HashContent(ctx, backend, f.RelativePath, m.Algorithm, nil)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			mu.Lock()
+			report.Errors = append(report.Errors, models.SyncError{
+				FilePath:  entry.Path,
+				Operation: models.ActionSkip,
+				Error:     err.Error(),
+				Timestamp: time.Now(),
+			})
+			mu.Unlock()
+			report.Stats.FilesErrored.Add(1)
+			return nil
+		}
+		if opts.Progress != nil {
+			opts.Progress(f.RelativePath)
+		}
+
+		if hash != entry.Hash {
+			actual.Hash = hash
+			actual.HashAlgorithm = m.Algorithm
+			addDifference(models.FileDifference{
+				RelativePath: entry.Path,
+				Reason:       models.ReasonHashDiff,
+				Details:      fmt.Sprintf("%s hash does not match manifest", m.Algorithm),
+				SourceInfo:   expected,
+				DestInfo:     actual,
+			})
+			return nil
+		}
+
+		report.Stats.FilesSynchronized.Add(1)
+		report.Stats.BytesScanned.Add(f.Size)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Files on disk that the manifest doesn't know about
+	var extra int
+	for path, f := range present {
+		if _, ok := m.Lookup(path); ok {
+			continue
+		}
+		extra++
+		report.Differences = append(report.Differences, models.FileDifference{
+			RelativePath: path,
+			Reason:       models.ReasonOnlyInDest,
+			Details:      "not listed in manifest",
+			DestInfo:     &models.FileInfo{Size: f.Size, ModTime: f.ModTime},
+		})
+	}
+
+	sort.Slice(report.Differences, func(i, j int) bool {
+		return report.Differences[i].RelativePath < report.Differences[j].RelativePath
+	})
+
+	report.Stats.FilesScanned.Store(int32(len(m.Entries) + extra))
+	report.EndTime = time.Now()
+	report.Duration = report.EndTime.Sub(report.StartTime)
+	report.Status = models.StatusSuccess
+	if len(report.Differences) > 0 || len(report.Errors) > 0 {
+		report.Status = models.StatusPartial
+	}
+
+	return report, nil
+}
+
+// listFiles returns the regular files of a backend, sorted by path
+func listFiles(ctx context.Context, backend storage.Backend, skip []string) ([]storage.FileInfo, error) {
+	all, err := backend.List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	skipped := make(map[string]bool, len(skip))
+	for _, path := range skip {
+		skipped[filepath.ToSlash(path)] = true
+	}
+
+	files := make([]storage.FileInfo, 0, len(all))
+	for _, f := range all {
+		if f.IsDir || f.IsSymlink || skipped[filepath.ToSlash(f.RelativePath)] {
+			continue
+		}
+		files = append(files, f)
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].RelativePath < files[j].RelativePath })
+	return files, nil
+}
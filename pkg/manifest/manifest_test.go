@@ -0,0 +1,282 @@
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sdejongh/syncnorris/pkg/compare"
+	"github.com/sdejongh/syncnorris/pkg/hashcache"
+	"github.com/sdejongh/syncnorris/pkg/models"
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+// newTestTree creates a directory with a few files and returns its backend
+func newTestTree(t *testing.T, files map[string]string) (string, *storage.Local) {
+	t.Helper()
+
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create parent dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+	}
+
+	backend, err := storage.NewLocal(root)
+	if err != nil {
+		t.Fatalf("failed to create backend: %v", err)
+	}
+	return root, backend
+}
+
+// TestCreateAndRoundTrip tests that manifests survive every format
+func TestCreateAndRoundTrip(t *testing.T) {
+	_, backend := newTestTree(t, map[string]string{
+		"a.txt":           "one\n",
+		"sub/b.txt":       "two\n",
+		"back\\slash.txt": "three\n",
+	})
+
+	m, err := Create(context.Background(), backend, compare.AlgorithmSHA256, Options{Workers: 2})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if len(m.Entries) != 3 {
+		t.Fatalf("entries = %d, want 3", len(m.Entries))
+	}
+	if e, ok := m.Lookup("a.txt"); !ok || e.Hash != "2c8b08da5ce60398e1f19af0e5dccc744df274b826abe585eaba68c525434806" || e.Size != 4 {
+		t.Errorf("Lookup(a.txt) = %+v, %v", e, ok)
+	}
+
+	for _, format := range []string{FormatSum, FormatBSD, FormatJSON} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, m, format); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			parsed, err := Parse(&buf, "")
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if parsed.Algorithm != compare.AlgorithmSHA256 {
+				t.Errorf("Algorithm = %s, want sha256", parsed.Algorithm)
+			}
+			if len(parsed.Entries) != len(m.Entries) {
+				t.Fatalf("entries = %d, want %d", len(parsed.Entries), len(m.Entries))
+			}
+			for i, e := range parsed.Entries {
+				if e.Path != m.Entries[i].Path || e.Hash != m.Entries[i].Hash {
+					t.Errorf("entry %d = %s %s, want %s %s", i, e.Path, e.Hash, m.Entries[i].Path, m.Entries[i].Hash)
+				}
+			}
+		})
+	}
+}
+
+// TestParse tests reading manifests written by other tools
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		algorithm string
+		want      string // algorithm
+		wantPath  string
+		wantErr   bool
+	}{
+		{"GNUText", "2c8b08da5ce60398e1f19af0e5dccc744df274b826abe585eaba68c525434806  ./a.txt\n", "", compare.AlgorithmSHA256, "a.txt", false},
+		{"GNUBinary", "d41d8cd98f00b204e9800998ecf8427e *dir/empty\n", "", compare.AlgorithmMD5, "dir/empty", false},
+		{"GNUEscaped", "\\d41d8cd98f00b204e9800998ecf8427e  new\\nline\n", "", compare.AlgorithmMD5, "new\nline", false},
+		{"GNUExplicit", "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262  empty\n", compare.AlgorithmBLAKE3, compare.AlgorithmBLAKE3, "empty", false},
+		{"BSD", "XXH128 (with (parens) = x.txt) = 99AA06D3014798D86001C324468D497F\n", "", compare.AlgorithmXXH128, "with (parens) = x.txt", false},
+		{"BSDMismatch", "MD5 (a) = d41d8cd98f00b204e9800998ecf8427e\n", compare.AlgorithmSHA256, "", "", true},
+		{"BSDMixed", "MD5 (a) = d41d8cd98f00b204e9800998ecf8427e\nXXH3 (b) = 2d06800538d394c2\n", "", "", "", true},
+		{"UnknownLength", "abcd  a\n", "", "", "", true},
+		{"NotHex", "zz  a\n", compare.AlgorithmMD5, "", "", true},
+		{"Garbage", "not a checksum line\n", "", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Parse(strings.NewReader(tt.input), tt.algorithm)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Parse() should fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if m.Algorithm != tt.want {
+				t.Errorf("Algorithm = %s, want %s", m.Algorithm, tt.want)
+			}
+			if len(m.Entries) != 1 || m.Entries[0].Path != tt.wantPath {
+				t.Fatalf("Entries = %+v, want path %q", m.Entries, tt.wantPath)
+			}
+			if m.Entries[0].Size != SizeUnknown {
+				t.Errorf("Size = %d, want unknown", m.Entries[0].Size)
+			}
+			if m.Entries[0].Hash != strings.ToLower(m.Entries[0].Hash) {
+				t.Errorf("Hash = %s, want lower case", m.Entries[0].Hash)
+			}
+		})
+	}
+}
+
+// TestVerify tests detection of missing, extra and changed files
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	root, backend := newTestTree(t, map[string]string{
+		"same.txt":    "same",
+		"changed.txt": "before",
+		"resized.txt": "short",
+		"gone.txt":    "gone",
+		"MANIFEST":    "",
+	})
+
+	m, err := Create(ctx, backend, compare.AlgorithmBLAKE3, Options{Skip: []string{"MANIFEST"}})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	os.WriteFile(filepath.Join(root, "changed.txt"), []byte("after!"), 0644)
+	os.WriteFile(filepath.Join(root, "resized.txt"), []byte("much longer"), 0644)
+	os.Remove(filepath.Join(root, "gone.txt"))
+	os.WriteFile(filepath.Join(root, "extra.txt"), []byte("extra"), 0644)
+
+	report, err := Verify(ctx, m, backend, Options{Workers: 3, Skip: []string{"MANIFEST"}})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	want := map[string]models.DifferenceReason{
+		"changed.txt": models.ReasonHashDiff,
+		"extra.txt":   models.ReasonOnlyInDest,
+		"gone.txt":    models.ReasonOnlyInSource,
+		"resized.txt": models.ReasonSizeDiff,
+	}
+	if len(report.Differences) != len(want) {
+		t.Fatalf("Differences = %+v, want %d", report.Differences, len(want))
+	}
+	for _, diff := range report.Differences {
+		if want[diff.RelativePath] != diff.Reason {
+			t.Errorf("%s: Reason = %s, want %s", diff.RelativePath, diff.Reason, want[diff.RelativePath])
+		}
+		if diff.Reason == models.ReasonHashDiff && (diff.DestInfo == nil || diff.DestInfo.HashAlgorithm != compare.AlgorithmBLAKE3) {
+			t.Errorf("%s: hash algorithm not recorded", diff.RelativePath)
+		}
+	}
+	if got := report.Stats.FilesSynchronized.Load(); got != 1 {
+		t.Errorf("FilesSynchronized = %d, want 1", got)
+	}
+	if report.Status != models.StatusPartial {
+		t.Errorf("Status = %s, want %s", report.Status, models.StatusPartial)
+	}
+
+	// A clean tree verifies successfully
+	os.Remove(filepath.Join(root, "extra.txt"))
+	m, _ = Create(ctx, backend, compare.AlgorithmBLAKE3, Options{Skip: []string{"MANIFEST"}})
+	report, err = Verify(ctx, m, backend, Options{Skip: []string{"MANIFEST"}})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if report.Status != models.StatusSuccess || len(report.Differences) != 0 {
+		t.Errorf("Status = %s with %d differences, want success", report.Status, len(report.Differences))
+	}
+}
+
+// TestVerifyIgnoresHashCache tests that corruption keeping the size and modification time is found
+// even when the hash cache holds the hash recorded when the manifest was created
+func TestVerifyIgnoresHashCache(t *testing.T) {
+	ctx := context.Background()
+	root, backend := newTestTree(t, map[string]string{"photo.jpg": "original"})
+
+	cache, err := hashcache.OpenDB(filepath.Join(t.TempDir(), "cache.db"), root)
+	if err != nil {
+		t.Fatalf("OpenDB() error = %v", err)
+	}
+	defer cache.Close()
+	backend.SetHashCache(cache)
+
+	m, err := Create(ctx, backend, compare.AlgorithmSHA256, Options{})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// Flip the content in place, keeping the size, inode and modification time
+	path := filepath.Join(root, "photo.jpg")
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat file: %v", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("failed to open file: %v", err)
+	}
+	f.WriteAt([]byte("X"), 0)
+	f.Close()
+	if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatalf("failed to restore modification time: %v", err)
+	}
+
+	report, err := Verify(ctx, m, backend, Options{})
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if len(report.Differences) != 1 || report.Differences[0].Reason != models.ReasonHashDiff {
+		t.Errorf("Differences = %+v, want a hash difference for photo.jpg", report.Differences)
+	}
+}
+
+// TestBackendChecksumComparator tests comparing a tree against a manifest backend
+func TestBackendChecksumComparator(t *testing.T) {
+	ctx := context.Background()
+	m, err := Parse(strings.NewReader(
+		"2c8b08da5ce60398e1f19af0e5dccc744df274b826abe585eaba68c525434806  a.txt\n"+
+			"27dd8ed44a83ff94d557f9fd0412ed5a8cbca69ea04922d88c01184a07300a5a  sub/b.txt\n"), "")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	source := NewBackend(m)
+
+	files, _ := source.List(ctx, "")
+	if len(files) != 3 {
+		t.Errorf("List() = %d entries, want 2 files and 1 directory", len(files))
+	}
+	if _, err := source.Read(ctx, "a.txt"); err != ErrReadOnly {
+		t.Errorf("Read() error = %v, want ErrReadOnly", err)
+	}
+
+	_, dest := newTestTree(t, map[string]string{
+		"a.txt":     "one\n",
+		"sub/b.txt": "changed\n",
+	})
+
+	comparator := compare.NewChecksumComparator()
+	tests := []struct {
+		path string
+		want compare.Result
+	}{
+		{"a.txt", compare.Same},
+		{filepath.Join("sub", "b.txt"), compare.Different},
+	}
+	for _, tt := range tests {
+		result, err := comparator.Compare(ctx, source, dest, tt.path, tt.path)
+		if err != nil {
+			t.Fatalf("Compare(%s) error = %v", tt.path, err)
+		}
+		if result.Result != tt.want {
+			t.Errorf("Compare(%s) = %s, want %s", tt.path, result.Result, tt.want)
+		}
+		if result.HashAlgorithm != compare.AlgorithmSHA256 {
+			t.Errorf("Compare(%s) HashAlgorithm = %s, want sha256", tt.path, result.HashAlgorithm)
+		}
+	}
+}
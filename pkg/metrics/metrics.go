@@ -0,0 +1,245 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/models"
+)
+
+// DefaultBuckets are the histogram upper bounds in seconds
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 300, 900, 3600}
+
+// Labels identify the sync job in every exported series
+type Labels struct {
+	Job        string
+	Mode       string
+	Comparison string
+}
+
+// histogram is a cumulative Prometheus-style histogram
+type histogram struct {
+	counts []uint64 // one per bucket, non-cumulative
+	count  uint64
+	sum    float64
+}
+
+// Collector gathers sync metrics and renders them in the Prometheus text format
+// It is safe for concurrent use: statistics are read from the report's atomic counters
+type Collector struct {
+	labels  Labels
+	buckets []float64
+
+	mu       sync.Mutex
+	report   *models.SyncReport
+	finished bool
+	phases   map[string]*histogram
+}
+
+// NewCollector creates a new metrics collector
+func NewCollector(labels Labels) *Collector {
+	if labels.Job == "" {
+		labels.Job = "syncnorris"
+	}
+	return &Collector{
+		labels:  labels,
+		buckets: DefaultBuckets,
+		phases:  make(map[string]*histogram),
+	}
+}
+
+// Track registers the report whose statistics are exported while the sync runs
+func (c *Collector) Track(report *models.SyncReport) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.report = report
+}
+
+// Finish records the final report; status and duration are exported from then on
+func (c *Collector) Finish(report *models.SyncReport) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.report = report
+	c.finished = true
+}
+
+// ObservePhase records the duration of one unit of work in a phase
+func (c *Collector) ObservePhase(phase string, d time.Duration) {
+	seconds := d.Seconds()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	h, ok := c.phases[phase]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(c.buckets))}
+		c.phases[phase] = h
+	}
+	for i, bound := range c.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// WriteText writes all metrics in the Prometheus text exposition format
+func (c *Collector) WriteText(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var b strings.Builder
+	base := c.labelString()
+
+	if c.report != nil {
+		stats := &c.report.Stats
+
+		b.WriteString("# HELP syncnorris_files_total Files processed by result.\n")
+		b.WriteString("# TYPE syncnorris_files_total counter\n")
+		for _, r := range []struct {
+			result string
+			value  int32
+		}{
+			{"copied", stats.FilesCopied.Load()},
+			{"updated", stats.FilesUpdated.Load()},
+			{"metadata_updated", stats.MetadataUpdated.Load()},
+			{"deleted", stats.FilesDeleted.Load()},
+			{"synchronized", stats.FilesSynchronized.Load()},
+			{"skipped", stats.FilesSkipped.Load()},
+			{"errored", stats.FilesErrored.Load()},
+		} {
+			fmt.Fprintf(&b, "syncnorris_files_total{%s,result=%s} %d\n", base, labelValue(r.result), r.value)
+		}
+
+		b.WriteString("# HELP syncnorris_files_scanned_total Files found while scanning each side.\n")
+		b.WriteString("# TYPE syncnorris_files_scanned_total counter\n")
+		fmt.Fprintf(&b, "syncnorris_files_scanned_total{%s,side=\"source\"} %d\n", base, stats.SourceFilesScanned.Load())
+		fmt.Fprintf(&b, "syncnorris_files_scanned_total{%s,side=\"dest\"} %d\n", base, stats.DestFilesScanned.Load())
+
+		b.WriteString("# HELP syncnorris_dirs_total Directories created, updated or deleted.\n")
+		b.WriteString("# TYPE syncnorris_dirs_total counter\n")
+		fmt.Fprintf(&b, "syncnorris_dirs_total{%s,result=\"created\"} %d\n", base, stats.DirsCreated.Load())
+		fmt.Fprintf(&b, "syncnorris_dirs_total{%s,result=\"updated\"} %d\n", base, stats.DirsUpdated.Load())
+		fmt.Fprintf(&b, "syncnorris_dirs_total{%s,result=\"deleted\"} %d\n", base, stats.DirsDeleted.Load())
+
+		b.WriteString("# HELP syncnorris_bytes_transferred_total Bytes written to the target side.\n")
+		b.WriteString("# TYPE syncnorris_bytes_transferred_total counter\n")
+		fmt.Fprintf(&b, "syncnorris_bytes_transferred_total{%s} %d\n", base, stats.BytesTransferred.Load())
+
+		b.WriteString("# HELP syncnorris_peak_speed_bytes Peak transfer speed in bytes per second.\n")
+		b.WriteString("# TYPE syncnorris_peak_speed_bytes gauge\n")
+		fmt.Fprintf(&b, "syncnorris_peak_speed_bytes{%s} %d\n", base, stats.PeakSpeed.Load())
+
+		if c.finished {
+			b.WriteString("# HELP syncnorris_last_run_duration_seconds Duration of the last sync run.\n")
+			b.WriteString("# TYPE syncnorris_last_run_duration_seconds gauge\n")
+			fmt.Fprintf(&b, "syncnorris_last_run_duration_seconds{%s} %g\n", base, c.report.Duration.Seconds())
+
+			b.WriteString("# HELP syncnorris_last_run_timestamp_seconds Unix time the last sync run completed.\n")
+			b.WriteString("# TYPE syncnorris_last_run_timestamp_seconds gauge\n")
+			fmt.Fprintf(&b, "syncnorris_last_run_timestamp_seconds{%s} %d\n", base, c.report.EndTime.Unix())
+
+			b.WriteString("# HELP syncnorris_last_run_status Status of the last sync run (1 for the current status).\n")
+			b.WriteString("# TYPE syncnorris_last_run_status gauge\n")
+			for _, s := range []models.SyncStatus{models.StatusSuccess, models.StatusPartial, models.StatusFailed, models.StatusCancelled} {
+				value := 0
+				if c.report.Status == s {
+					value = 1
+				}
+				fmt.Fprintf(&b, "syncnorris_last_run_status{%s,status=%s} %d\n", base, labelValue(string(s)), value)
+			}
+		}
+	}
+
+	if len(c.phases) > 0 {
+		b.WriteString("# HELP syncnorris_phase_duration_seconds Duration of work units per sync phase.\n")
+		b.WriteString("# TYPE syncnorris_phase_duration_seconds histogram\n")
+
+		phases := make([]string, 0, len(c.phases))
+		for phase := range c.phases {
+			phases = append(phases, phase)
+		}
+		sort.Strings(phases)
+
+		for _, phase := range phases {
+			h := c.phases[phase]
+			var cumulative uint64
+			for i, bound := range c.buckets {
+				cumulative += h.counts[i]
+				fmt.Fprintf(&b, "syncnorris_phase_duration_seconds_bucket{%s,phase=%s,le=\"%g\"} %d\n", base, labelValue(phase), bound, cumulative)
+			}
+			fmt.Fprintf(&b, "syncnorris_phase_duration_seconds_bucket{%s,phase=%s,le=\"+Inf\"} %d\n", base, labelValue(phase), h.count)
+			fmt.Fprintf(&b, "syncnorris_phase_duration_seconds_sum{%s,phase=%s} %g\n", base, labelValue(phase), h.sum)
+			fmt.Fprintf(&b, "syncnorris_phase_duration_seconds_count{%s,phase=%s} %d\n", base, labelValue(phase), h.count)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteTextfile writes metrics for the node_exporter textfile collector
+// The file is written to a temporary name and renamed so the collector never sees partial output
+func (c *Collector) WriteTextfile(path string) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create metrics directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create metrics file: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	if err := c.WriteText(tmp); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to set metrics file permissions: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to move metrics file into place: %w", err)
+	}
+
+	return nil
+}
+
+// Handler returns an HTTP handler serving the metrics
+func (c *Collector) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		c.WriteText(w)
+	})
+}
+
+// labelString renders the common labels
+func (c *Collector) labelString() string {
+	return fmt.Sprintf("job=%s,mode=%s,comparison=%s", labelValue(c.labels.Job), labelValue(c.labels.Mode), labelValue(c.labels.Comparison))
+}
+
+// labelEscaper escapes label values as the Prometheus text format requires
+// Unlike Go quoting, other characters (including non-ASCII ones) are written as is
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labelValue renders a quoted label value
+func labelValue(value string) string {
+	return `"` + labelEscaper.Replace(value) + `"`
+}
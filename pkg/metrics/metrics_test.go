@@ -0,0 +1,171 @@
+package metrics
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/models"
+)
+
+func newTestCollector() *Collector {
+	return NewCollector(Labels{Job: "nightly", Mode: "oneway", Comparison: "hash"})
+}
+
+// TestCollectorWriteText tests the Prometheus text output
+func TestCollectorWriteText(t *testing.T) {
+	t.Run("Counters", func(t *testing.T) {
+		c := newTestCollector()
+		report := &models.SyncReport{}
+		report.Stats.FilesCopied.Store(4)
+		report.Stats.BytesTransferred.Store(2048)
+		c.Track(report)
+
+		var buf bytes.Buffer
+		if err := c.WriteText(&buf); err != nil {
+			t.Fatalf("WriteText() error = %v", err)
+		}
+		out := buf.String()
+
+		for _, want := range []string{
+			`syncnorris_files_total{job="nightly",mode="oneway",comparison="hash",result="copied"} 4`,
+			`syncnorris_bytes_transferred_total{job="nightly",mode="oneway",comparison="hash"} 2048`,
+			"# TYPE syncnorris_files_total counter",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("output missing %q\n%s", want, out)
+			}
+		}
+		if strings.Contains(out, "syncnorris_last_run_status") {
+			t.Error("last run status should only be exported after Finish()")
+		}
+	})
+
+	t.Run("FinishedRun", func(t *testing.T) {
+		c := newTestCollector()
+		c.Finish(&models.SyncReport{
+			Status:   models.StatusPartial,
+			Duration: 90 * time.Second,
+			EndTime:  time.Unix(1700000000, 0),
+		})
+
+		var buf bytes.Buffer
+		c.WriteText(&buf)
+		out := buf.String()
+
+		for _, want := range []string{
+			`syncnorris_last_run_status{job="nightly",mode="oneway",comparison="hash",status="partial"} 1`,
+			`syncnorris_last_run_status{job="nightly",mode="oneway",comparison="hash",status="success"} 0`,
+			`syncnorris_last_run_duration_seconds{job="nightly",mode="oneway",comparison="hash"} 90`,
+			`syncnorris_last_run_timestamp_seconds{job="nightly",mode="oneway",comparison="hash"} 1700000000`,
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("output missing %q\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("PhaseHistogram", func(t *testing.T) {
+		c := newTestCollector()
+		c.ObservePhase("compare", 2*time.Millisecond)
+		c.ObservePhase("compare", 3*time.Second)
+
+		var buf bytes.Buffer
+		c.WriteText(&buf)
+		out := buf.String()
+
+		for _, want := range []string{
+			`syncnorris_phase_duration_seconds_bucket{job="nightly",mode="oneway",comparison="hash",phase="compare",le="0.001"} 0`,
+			`syncnorris_phase_duration_seconds_bucket{job="nightly",mode="oneway",comparison="hash",phase="compare",le="0.005"} 1`,
+			`syncnorris_phase_duration_seconds_bucket{job="nightly",mode="oneway",comparison="hash",phase="compare",le="5"} 2`,
+			`syncnorris_phase_duration_seconds_bucket{job="nightly",mode="oneway",comparison="hash",phase="compare",le="+Inf"} 2`,
+			`syncnorris_phase_duration_seconds_count{job="nightly",mode="oneway",comparison="hash",phase="compare"} 2`,
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("output missing %q\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("DefaultJob", func(t *testing.T) {
+		c := NewCollector(Labels{})
+		c.Track(&models.SyncReport{})
+
+		var buf bytes.Buffer
+		c.WriteText(&buf)
+		if !strings.Contains(buf.String(), `job="syncnorris"`) {
+			t.Error("default job label should be syncnorris")
+		}
+	})
+
+	t.Run("LabelEscaping", func(t *testing.T) {
+		// Tabs and non-printable characters like the no-break space are written as is
+		c := NewCollector(Labels{Job: "sauvegarde\u00a0été\t\"nas\"\\photos\n", Mode: "oneway", Comparison: "hash"})
+		c.Track(&models.SyncReport{})
+
+		var buf bytes.Buffer
+		c.WriteText(&buf)
+		want := "syncnorris_bytes_transferred_total{job=\"sauvegarde\u00a0été\t\\\"nas\\\"\\\\photos\\n\",mode=\"oneway\",comparison=\"hash\"} 0"
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q\n%s", want, buf.String())
+		}
+		if strings.Contains(buf.String(), `\u00a0`) || strings.Contains(buf.String(), `\t`) {
+			t.Error("only backslashes, double quotes and newlines should be escaped")
+		}
+	})
+}
+
+// TestCollectorWriteTextfile tests atomic textfile output
+func TestCollectorWriteTextfile(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "collector", "syncnorris.prom")
+
+	c := newTestCollector()
+	c.Finish(&models.SyncReport{Status: models.StatusSuccess})
+
+	if err := c.WriteTextfile(path); err != nil {
+		t.Fatalf("WriteTextfile() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read metrics file: %v", err)
+	}
+	if !strings.Contains(string(data), "syncnorris_last_run_status") {
+		t.Errorf("metrics file missing last run status:\n%s", data)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("directory contains %d entries, want 1 (temporary file left behind?)", len(entries))
+	}
+}
+
+// TestCollectorHandler tests the /metrics HTTP handler
+func TestCollectorHandler(t *testing.T) {
+	c := newTestCollector()
+	c.Track(&models.SyncReport{})
+
+	server := httptest.NewServer(c.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Content-Type = %s, want text/plain", ct)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "syncnorris_files_total") {
+		t.Errorf("response missing metrics:\n%s", body)
+	}
+}
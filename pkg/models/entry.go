@@ -24,9 +24,18 @@ type FileEntry struct {
 	// Permissions are the file mode bits
 	Permissions uint32
 
-	// Hash is the SHA-256 hash (optional, computed on demand)
+	// IsSymlink indicates a symbolic link, synchronized by target rather than content
+	IsSymlink bool
+
+	// LinkTarget is the target of the symbolic link
+	LinkTarget string
+
+	// Hash is the content hash (optional, computed on demand)
 	Hash string
 
+	// HashAlgorithm names the algorithm Hash was computed with
+	HashAlgorithm string
+
 	// Location indicates where the file exists
 	Location FileLocation
 }
@@ -51,12 +60,16 @@ const (
 	ActionCopy Action = "copy"
 	// ActionUpdate updates existing file in destination
 	ActionUpdate Action = "update"
+	// ActionUpdateMetadata applies permissions, modification time and owner without copying content
+	ActionUpdateMetadata Action = "update_metadata"
 	// ActionDelete deletes file from destination
 	ActionDelete Action = "delete"
 	// ActionSkip skips the file (no change needed)
 	ActionSkip Action = "skip"
 	// ActionConflict indicates a conflict requiring resolution
 	ActionConflict Action = "conflict"
+	// ActionVerify verifies a copied file against the source (used to categorize verification errors)
+	ActionVerify Action = "verify"
 )
 
 // FileOperation represents a planned operation on a file
@@ -67,4 +80,5 @@ type FileOperation struct {
 	Error    error
 	BytesCopied int64
 	Duration time.Duration
+	Stage    string // Comparison chain stage that decided the action (empty for other comparators)
 }
@@ -44,6 +44,14 @@ const (
 	CompareHash ComparisonMethod = "hash"
 	// CompareMD5 compares MD5 hashes (faster than SHA-256, less secure)
 	CompareMD5 ComparisonMethod = "md5"
+	// CompareBLAKE3 compares BLAKE3 hashes (cryptographic, hashes large files on multiple cores)
+	CompareBLAKE3 ComparisonMethod = "blake3"
+	// CompareXXH3 compares 64-bit XXH3 hashes (non-cryptographic, fastest hash)
+	CompareXXH3 ComparisonMethod = "xxh3"
+	// CompareXXH128 compares 128-bit XXH3 hashes (non-cryptographic)
+	CompareXXH128 ComparisonMethod = "xxh128"
+	// CompareChain runs the comparison stages listed in the configuration
+	CompareChain ComparisonMethod = "chain"
 )
 
 // SyncOperation represents a sync operation configuration
@@ -59,13 +67,39 @@ type SyncOperation struct {
 	DeleteOrphans      bool  // Delete files in destination that don't exist in source
 	MaxWorkers         int
 	BandwidthLimit     int64 // bytes per second, 0 = unlimited
+	BandwidthSchedule  string // Time-of-day limits, e.g. "Mon-Fri 08:00-18:00 2M; * 10M" (overrides BandwidthLimit)
+	SourceReadLimit    int64  // Bytes per second read from the source, 0 = unlimited
+	DestReadLimit      int64  // Bytes per second read from the destination, 0 = unlimited
+	WriteLimit         int64  // Bytes per second written to either side, 0 = unlimited
+	OpsLimit           int64  // Storage operations per second on both sides, 0 = unlimited
 	BufferSize         int
 	Stateful           bool  // Save state for bidirectional sync (enables change tracking)
+	Verify             bool  // Read back and hash copied files, copying again on mismatch
+	VerifyRetries      int   // Number of additional copies after a verification failure
+	ModifyWindow       time.Duration // Largest modification time difference considered equal (0 = 1 second)
+	IgnoreHourOffsets  bool          // Treat modification times differing by whole hours as equal (FAT DST shifts)
+	ShowDiff           bool          // Describe content differences with a unified diff or first differing byte
+	HardLinks          bool          // Recreate source files sharing an inode as hard links in destination (one-way)
+	Durability         string        // How writes are flushed to stable storage: none, file or full
+	Adaptive           *AdaptiveThrottling // Adjust workers and bandwidth to load (nil = fixed)
 	CreatedAt          time.Time
 	StartedAt          *time.Time
 	CompletedAt        *time.Time
 }
 
+// AdaptiveThrottling bounds the controller adjusting active workers and bandwidth during a sync
+// The controller backs off when writes are slow, errors are frequent or the host is loaded
+type AdaptiveThrottling struct {
+	MinWorkers    int
+	MaxWorkers    int
+	MinBandwidth  int64         // bytes per second
+	MaxBandwidth  int64         // bytes per second, 0 = bandwidth is not adapted
+	TargetLatency time.Duration // Average destination write latency per chunk considered healthy
+	MaxErrorRate  float64       // Largest healthy fraction of failed files
+	MaxLoad       float64       // Largest healthy load average per CPU (0 = ignore load)
+	Interval      time.Duration // Time between adjustments
+}
+
 // Validate checks if the operation configuration is valid
 func (op *SyncOperation) Validate() error {
 	if op.SourcePath == "" {
@@ -50,6 +50,9 @@ type Statistics struct {
 	FilesSynchronized  atomic.Int32 // Files already identical (no copy needed)
 	FilesSkipped       atomic.Int32 // Files skipped for other reasons (e.g., dest-only in one-way)
 	FilesErrored       atomic.Int32
+	HardLinks          atomic.Int32 // Files linked to another copy in destination instead of copied
+	MetadataUpdated    atomic.Int32 // Files whose metadata was updated without copying content
+	FastCopies         atomic.Int32 // Files cloned or copied in the kernel instead of streamed
 
 	// Source-specific counts
 	SourceFilesScanned atomic.Int32
@@ -63,10 +66,13 @@ type Statistics struct {
 	DirsScanned atomic.Int32 // Unique directories across source and destination
 	DirsCreated atomic.Int32
 	DirsDeleted atomic.Int32
+	DirsUpdated atomic.Int32 // Existing directories whose mode or modification time was updated
 
 	// Data transfer
 	BytesScanned     atomic.Int64
 	BytesTransferred atomic.Int64
+	BytesAllocated   atomic.Int64 // Destination disk space allocated by transferred files
+	SparseFiles      atomic.Int32 // Transferred files whose holes were preserved
 
 	// Performance
 	AverageSpeed     atomic.Int64 // bytes per second
@@ -118,6 +124,9 @@ type FileDifference struct {
 	Details      string           `json:"details,omitempty"`
 	SourceInfo   *FileInfo        `json:"source_info,omitempty"` // nil if file doesn't exist in source
 	DestInfo     *FileInfo        `json:"dest_info,omitempty"`   // nil if file doesn't exist in dest
+	Stage        string           `json:"stage,omitempty"`       // Comparison chain stage that decided the difference
+	Diff         string           `json:"diff,omitempty"`        // Unified diff from destination to source (text files, --show-diff)
+	DiffOffset   *int64           `json:"diff_offset,omitempty"` // First differing byte (binary files, --show-diff)
 }
 
 // DifferenceReason indicates why files remain different
@@ -128,12 +137,16 @@ const (
 	ReasonCopyError DifferenceReason = "copy_error"
 	// ReasonUpdateError indicates file update failed
 	ReasonUpdateError DifferenceReason = "update_error"
+	// ReasonVerifyFailed indicates a copied file didn't match the source when read back
+	ReasonVerifyFailed DifferenceReason = "verify_failed"
 	// ReasonSizeDiff indicates files have different sizes
 	ReasonSizeDiff DifferenceReason = "size_different"
 	// ReasonHashDiff indicates files have different hashes
 	ReasonHashDiff DifferenceReason = "hash_different"
 	// ReasonContentDiff indicates files have different content (binary comparison)
 	ReasonContentDiff DifferenceReason = "content_different"
+	// ReasonMetadataDiff indicates files have identical content but different metadata
+	ReasonMetadataDiff DifferenceReason = "metadata_different"
 	// ReasonOnlyInSource indicates file exists only in source (not copied yet or copy failed)
 	ReasonOnlyInSource DifferenceReason = "only_in_source"
 	// ReasonOnlyInDest indicates file exists only in destination (one-way mode)
@@ -142,6 +155,8 @@ const (
 	ReasonDeleted DifferenceReason = "deleted"
 	// ReasonSkipped indicates file was intentionally skipped
 	ReasonSkipped DifferenceReason = "skipped"
+	// ReasonBitRot indicates file content changed while its size and modification time did not
+	ReasonBitRot DifferenceReason = "bit_rot"
 )
 
 // FileInfo holds metadata about a file for difference reporting
@@ -149,4 +164,6 @@ type FileInfo struct {
 	Size    int64     `json:"size"`
 	ModTime time.Time `json:"mod_time"`
 	Hash    string    `json:"hash,omitempty"` // may be empty
+	// HashAlgorithm names the algorithm Hash was computed with (sha256, md5, blake3, xxh3, xxh128)
+	HashAlgorithm string `json:"hash_algorithm,omitempty"`
 }
@@ -0,0 +1,119 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/models"
+)
+
+// Notifier defines the interface for sync completion notifications
+// Implementations include webhook targets
+type Notifier interface {
+	// Notify sends a notification for the completed sync report
+	Notify(ctx context.Context, report *models.SyncReport) error
+
+	// Name returns the notifier name
+	Name() string
+}
+
+// Summary is the condensed view of a SyncReport sent to notification targets
+// It is also the data passed to body templates
+type Summary struct {
+	OperationID       string    `json:"operation_id"`
+	Source            string    `json:"source"`
+	Destination       string    `json:"destination"`
+	Mode              string    `json:"mode"`
+	DryRun            bool      `json:"dry_run"`
+	Status            string    `json:"status"`
+	ExitCode          int       `json:"exit_code"`
+	StartTime         time.Time `json:"start_time"`
+	EndTime           time.Time `json:"end_time"`
+	Duration          string    `json:"duration"`
+	DurationMs        int64     `json:"duration_ms"`
+	FilesCopied       int32     `json:"files_copied"`
+	FilesUpdated      int32     `json:"files_updated"`
+	FilesDeleted      int32     `json:"files_deleted"`
+	FilesSynchronized int32     `json:"files_synchronized"`
+	FilesSkipped      int32     `json:"files_skipped"`
+	FilesErrored      int32     `json:"files_errored"`
+	BytesTransferred  int64     `json:"bytes_transferred"`
+	ErrorCount        int       `json:"error_count"`
+	ConflictCount     int       `json:"conflict_count"`
+	DifferenceCount   int       `json:"difference_count"`
+}
+
+// NewSummary builds a notification summary from a sync report
+func NewSummary(report *models.SyncReport) Summary {
+	return Summary{
+		OperationID:       report.OperationID,
+		Source:            report.SourcePath,
+		Destination:       report.DestPath,
+		Mode:              string(report.Mode),
+		DryRun:            report.DryRun,
+		Status:            string(report.Status),
+		ExitCode:          report.Status.ExitCode(),
+		StartTime:         report.StartTime,
+		EndTime:           report.EndTime,
+		Duration:          report.Duration.Round(time.Millisecond).String(),
+		DurationMs:        report.Duration.Milliseconds(),
+		FilesCopied:       report.Stats.FilesCopied.Load(),
+		FilesUpdated:      report.Stats.FilesUpdated.Load(),
+		FilesDeleted:      report.Stats.FilesDeleted.Load(),
+		FilesSynchronized: report.Stats.FilesSynchronized.Load(),
+		FilesSkipped:      report.Stats.FilesSkipped.Load(),
+		FilesErrored:      report.Stats.FilesErrored.Load(),
+		BytesTransferred:  report.Stats.BytesTransferred.Load(),
+		ErrorCount:        len(report.Errors),
+		ConflictCount:     len(report.Conflicts),
+		DifferenceCount:   len(report.Differences),
+	}
+}
+
+// ShouldNotify reports whether a status matches the given filter
+// An empty filter matches every status
+func ShouldNotify(status models.SyncStatus, on []string) bool {
+	if len(on) == 0 {
+		return true
+	}
+	for _, s := range on {
+		if s == "always" || models.SyncStatus(s) == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Dispatcher fans a report out to several notifiers
+type Dispatcher struct {
+	notifiers []Notifier
+}
+
+// NewDispatcher creates a dispatcher for the given notifiers
+func NewDispatcher(notifiers ...Notifier) *Dispatcher {
+	return &Dispatcher{notifiers: notifiers}
+}
+
+// Add registers an additional notifier
+func (d *Dispatcher) Add(n Notifier) {
+	d.notifiers = append(d.notifiers, n)
+}
+
+// Len returns the number of registered notifiers
+func (d *Dispatcher) Len() int {
+	return len(d.notifiers)
+}
+
+// Notify sends the report to every notifier
+// Failures do not stop delivery to the remaining targets; all errors are joined
+func (d *Dispatcher) Notify(ctx context.Context, report *models.SyncReport) error {
+	var errs []error
+	for _, n := range d.notifiers {
+		if err := n.Notify(ctx, report); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", n.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,189 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/models"
+)
+
+// SignatureHeader carries the HMAC-SHA256 signature of the request body
+const SignatureHeader = "X-Syncnorris-Signature"
+
+// WebhookConfig holds configuration for a webhook notifier
+type WebhookConfig struct {
+	// URL is the endpoint receiving the POST request
+	URL string
+	// Headers are added to every request
+	Headers map[string]string
+	// Secret enables HMAC-SHA256 signing of the body (empty = unsigned)
+	Secret string
+	// BodyTemplate is a text/template rendered with a Summary (empty = JSON summary)
+	BodyTemplate string
+	// ContentType of the request body (default: application/json)
+	ContentType string
+	// On filters which statuses trigger the webhook: success, partial, failed, cancelled, always
+	On []string
+	// MaxRetries is the number of retries after the first attempt
+	MaxRetries int
+	// InitialBackoff is the delay before the first retry, doubled on each attempt
+	InitialBackoff time.Duration
+	// Timeout bounds each individual HTTP request
+	Timeout time.Duration
+}
+
+// WebhookNotifier posts sync summaries to an HTTP endpoint
+type WebhookNotifier struct {
+	config   WebhookConfig
+	client   *http.Client
+	template *template.Template
+}
+
+// NewWebhookNotifier creates a new webhook notifier
+func NewWebhookNotifier(config WebhookConfig) (*WebhookNotifier, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("webhook URL is required")
+	}
+	if config.ContentType == "" {
+		config.ContentType = "application/json"
+	}
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = time.Second
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	var tmpl *template.Template
+	if config.BodyTemplate != "" {
+		var err error
+		tmpl, err = template.New("webhook").Funcs(templateFuncs).Parse(config.BodyTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook body template: %w", err)
+		}
+	}
+
+	return &WebhookNotifier{
+		config:   config,
+		client:   &http.Client{Timeout: config.Timeout},
+		template: tmpl,
+	}, nil
+}
+
+// templateFuncs are helpers available in webhook body templates
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// Notify posts the report summary if its status matches the filter
+func (w *WebhookNotifier) Notify(ctx context.Context, report *models.SyncReport) error {
+	if !ShouldNotify(report.Status, w.config.On) {
+		return nil
+	}
+
+	body, err := w.renderBody(NewSummary(report))
+	if err != nil {
+		return err
+	}
+
+	backoff := w.config.InitialBackoff
+	var lastErr error
+	for attempt := 0; attempt <= w.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		retry, err := w.send(ctx, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+
+	return fmt.Errorf("webhook delivery failed: %w", lastErr)
+}
+
+// renderBody renders the request body from the summary
+func (w *WebhookNotifier) renderBody(summary Summary) ([]byte, error) {
+	if w.template == nil {
+		data, err := json.Marshal(summary)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode webhook body: %w", err)
+		}
+		return data, nil
+	}
+
+	var buf bytes.Buffer
+	if err := w.template.Execute(&buf, summary); err != nil {
+		return nil, fmt.Errorf("failed to render webhook body: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// send performs a single delivery attempt
+// The returned bool indicates whether the failure is worth retrying
+func (w *WebhookNotifier) send(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", w.config.ContentType)
+	req.Header.Set("User-Agent", "syncnorris")
+	for k, v := range w.config.Headers {
+		req.Header.Set(k, v)
+	}
+	if w.config.Secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(w.config.Secret, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	// Retry on server errors and rate limiting, not on other client errors
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("unexpected status: %s", resp.Status)
+}
+
+// Name returns the notifier name
+func (w *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+// Sign computes the hex-encoded HMAC-SHA256 of body with the given secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
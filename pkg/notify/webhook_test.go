@@ -0,0 +1,192 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/models"
+)
+
+func newTestReport(status models.SyncStatus) *models.SyncReport {
+	report := &models.SyncReport{
+		OperationID: "op-123",
+		SourcePath:  "/src",
+		DestPath:    "/dst",
+		Mode:        models.ModeOneWay,
+		Duration:    1500 * time.Millisecond,
+		Status:      status,
+	}
+	report.Stats.FilesCopied.Store(3)
+	report.Stats.BytesTransferred.Store(4096)
+	return report
+}
+
+// TestWebhookNotifier tests webhook delivery against a local server
+func TestWebhookNotifier(t *testing.T) {
+	t.Run("DefaultJSONBody", func(t *testing.T) {
+		var received Summary
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				t.Errorf("Method = %s, want POST", r.Method)
+			}
+			if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %s, want application/json", ct)
+			}
+			if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+				t.Errorf("failed to decode body: %v", err)
+			}
+		}))
+		defer server.Close()
+
+		n, err := NewWebhookNotifier(WebhookConfig{URL: server.URL})
+		if err != nil {
+			t.Fatalf("NewWebhookNotifier() error = %v", err)
+		}
+		if err := n.Notify(context.Background(), newTestReport(models.StatusSuccess)); err != nil {
+			t.Fatalf("Notify() error = %v", err)
+		}
+
+		if received.OperationID != "op-123" {
+			t.Errorf("OperationID = %s, want op-123", received.OperationID)
+		}
+		if received.FilesCopied != 3 {
+			t.Errorf("FilesCopied = %d, want 3", received.FilesCopied)
+		}
+		if received.Status != "success" {
+			t.Errorf("Status = %s, want success", received.Status)
+		}
+	})
+
+	t.Run("TemplateHeadersAndSignature", func(t *testing.T) {
+		var body []byte
+		var header, signature string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ = io.ReadAll(r.Body)
+			header = r.Header.Get("X-Team")
+			signature = r.Header.Get(SignatureHeader)
+		}))
+		defer server.Close()
+
+		n, err := NewWebhookNotifier(WebhookConfig{
+			URL:          server.URL,
+			Headers:      map[string]string{"X-Team": "storage"},
+			Secret:       "s3cret",
+			BodyTemplate: `{"text":"sync {{.Status}}: {{.FilesCopied}} copied"}`,
+		})
+		if err != nil {
+			t.Fatalf("NewWebhookNotifier() error = %v", err)
+		}
+		if err := n.Notify(context.Background(), newTestReport(models.StatusPartial)); err != nil {
+			t.Fatalf("Notify() error = %v", err)
+		}
+
+		if string(body) != `{"text":"sync partial: 3 copied"}` {
+			t.Errorf("body = %s", body)
+		}
+		if header != "storage" {
+			t.Errorf("X-Team = %s, want storage", header)
+		}
+		if signature != "sha256="+Sign("s3cret", body) {
+			t.Errorf("signature = %s, does not match body", signature)
+		}
+	})
+
+	t.Run("RetryWithBackoff", func(t *testing.T) {
+		var attempts atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if attempts.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer server.Close()
+
+		n, _ := NewWebhookNotifier(WebhookConfig{
+			URL:            server.URL,
+			MaxRetries:     3,
+			InitialBackoff: time.Millisecond,
+		})
+		if err := n.Notify(context.Background(), newTestReport(models.StatusFailed)); err != nil {
+			t.Fatalf("Notify() error = %v", err)
+		}
+		if attempts.Load() != 3 {
+			t.Errorf("attempts = %d, want 3", attempts.Load())
+		}
+	})
+
+	t.Run("NoRetryOnClientError", func(t *testing.T) {
+		var attempts atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		n, _ := NewWebhookNotifier(WebhookConfig{
+			URL:            server.URL,
+			MaxRetries:     3,
+			InitialBackoff: time.Millisecond,
+		})
+		if err := n.Notify(context.Background(), newTestReport(models.StatusSuccess)); err == nil {
+			t.Error("Notify() should fail on 400 response")
+		}
+		if attempts.Load() != 1 {
+			t.Errorf("attempts = %d, want 1", attempts.Load())
+		}
+	})
+
+	t.Run("StatusFilter", func(t *testing.T) {
+		var attempts atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+		}))
+		defer server.Close()
+
+		n, _ := NewWebhookNotifier(WebhookConfig{
+			URL: server.URL,
+			On:  []string{"partial", "failed"},
+		})
+		n.Notify(context.Background(), newTestReport(models.StatusSuccess))
+		if attempts.Load() != 0 {
+			t.Errorf("attempts = %d, want 0 for filtered status", attempts.Load())
+		}
+		n.Notify(context.Background(), newTestReport(models.StatusFailed))
+		if attempts.Load() != 1 {
+			t.Errorf("attempts = %d, want 1", attempts.Load())
+		}
+	})
+
+	t.Run("InvalidConfig", func(t *testing.T) {
+		if _, err := NewWebhookNotifier(WebhookConfig{}); err == nil {
+			t.Error("NewWebhookNotifier() should fail without URL")
+		}
+		if _, err := NewWebhookNotifier(WebhookConfig{URL: "http://x", BodyTemplate: "{{.Bad"}); err == nil {
+			t.Error("NewWebhookNotifier() should fail for invalid template")
+		}
+	})
+}
+
+// TestShouldNotify tests status filtering
+func TestShouldNotify(t *testing.T) {
+	tests := []struct {
+		status models.SyncStatus
+		on     []string
+		want   bool
+	}{
+		{models.StatusSuccess, nil, true},
+		{models.StatusSuccess, []string{"always"}, true},
+		{models.StatusSuccess, []string{"failed"}, false},
+		{models.StatusPartial, []string{"partial", "failed"}, true},
+	}
+
+	for _, tt := range tests {
+		if got := ShouldNotify(tt.status, tt.on); got != tt.want {
+			t.Errorf("ShouldNotify(%s, %v) = %v, want %v", tt.status, tt.on, got, tt.want)
+		}
+	}
+}
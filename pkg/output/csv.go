@@ -0,0 +1,84 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/models"
+)
+
+// csvHeader lists the columns of the CSV differences report
+var csvHeader = []string{
+	"record_type",
+	"path",
+	"reason",
+	"details",
+	"source_size",
+	"source_mod_time",
+	"source_hash",
+	"dest_size",
+	"dest_mod_time",
+	"dest_hash",
+}
+
+// writeDifferencesCSV writes differences, conflicts and errors as CSV
+// The record_type column is "difference", "conflict" or "error"
+func writeDifferencesCSV(report *models.SyncReport, w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, diff := range report.Differences {
+		record := []string{"difference", diff.RelativePath, string(diff.Reason), diff.Details}
+		record = append(record, csvFileInfo(diff.SourceInfo)...)
+		record = append(record, csvFileInfo(diff.DestInfo)...)
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range report.Conflicts {
+		details := fmt.Sprintf("resolved with %s", c.Resolution)
+		if c.ResultDescription != "" {
+			details += ": " + c.ResultDescription
+		}
+		record := []string{"conflict", c.Path, string(c.Type), details}
+		record = append(record, csvFileEntry(c.SourceEntry)...)
+		record = append(record, csvFileEntry(c.DestEntry)...)
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	for _, e := range report.Errors {
+		record := []string{"error", e.FilePath, string(e.Operation), e.Error}
+		record = append(record, csvFileInfo(nil)...)
+		record = append(record, csvFileInfo(nil)...)
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvFileInfo returns the size, modification time and hash columns for one side
+func csvFileInfo(info *models.FileInfo) []string {
+	if info == nil {
+		return []string{"", "", ""}
+	}
+	return []string{fmt.Sprint(info.Size), info.ModTime.Format(time.RFC3339), qualifiedHash(info.Hash, info.HashAlgorithm)}
+}
+
+// csvFileEntry returns the size, modification time and hash columns for a conflict side
+func csvFileEntry(entry *models.FileEntry) []string {
+	if entry == nil {
+		return []string{"", "", ""}
+	}
+	return []string{fmt.Sprint(entry.Size), entry.ModTime.Format(time.RFC3339), qualifiedHash(entry.Hash, entry.HashAlgorithm)}
+}
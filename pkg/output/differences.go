@@ -9,19 +9,23 @@ import (
 	"time"
 
 	"github.com/sdejongh/syncnorris/pkg/models"
+	"golang.org/x/term"
 )
 
 // WriteDifferencesReport writes the differences report to a file or stdout
 // If filepath is empty, writes to stdout
-// Format can be "human" or "json"
-func WriteDifferencesReport(report *models.SyncReport, filepath string, format string) error {
+// Format can be "human", "json", "html", "junit" or "csv"
+// showDiff includes the unified diffs and differing byte offsets of compared files (human and json formats)
+func WriteDifferencesReport(report *models.SyncReport, filepath string, format string, showDiff bool) error {
 	var w io.Writer
 	var shouldClose bool
+	var color bool
 
 	if filepath == "" {
 		// Write to stdout
 		w = os.Stdout
 		shouldClose = false
+		color = useColor(os.Stdout)
 
 		// Always display something to stdout, even if no differences
 		if len(report.Differences) == 0 {
@@ -46,9 +50,15 @@ func WriteDifferencesReport(report *models.SyncReport, filepath string, format s
 	var err error
 	switch format {
 	case "json":
-		err = writeDifferencesJSON(report, w)
+		err = writeDifferencesJSON(report, w, showDiff)
+	case "html":
+		err = writeDifferencesHTML(report, w)
+	case "junit":
+		err = writeDifferencesJUnit(report, w)
+	case "csv":
+		err = writeDifferencesCSV(report, w)
 	default: // "human"
-		err = writeDifferencesHuman(report, w)
+		err = writeDifferencesHuman(report, w, showDiff, color)
 	}
 
 	if err != nil && shouldClose {
@@ -59,7 +69,7 @@ func WriteDifferencesReport(report *models.SyncReport, filepath string, format s
 }
 
 // writeDifferencesHuman writes differences in human-readable format
-func writeDifferencesHuman(report *models.SyncReport, w io.Writer) error {
+func writeDifferencesHuman(report *models.SyncReport, w io.Writer, showDiff, color bool) error {
 	fmt.Fprintf(w, "Differences Report\n")
 	fmt.Fprintf(w, "==================\n\n")
 	fmt.Fprintf(w, "Generated: %s\n", time.Now().Format(time.RFC3339))
@@ -102,24 +112,30 @@ func writeDifferencesHuman(report *models.SyncReport, w io.Writer) error {
 	reasonOrder := []models.DifferenceReason{
 		models.ReasonCopyError,
 		models.ReasonUpdateError,
+		models.ReasonVerifyFailed,
+		models.ReasonBitRot,
 		models.ReasonDeleted,
 		models.ReasonOnlyInSource,
 		models.ReasonOnlyInDest,
 		models.ReasonHashDiff,
 		models.ReasonContentDiff,
 		models.ReasonSizeDiff,
+		models.ReasonMetadataDiff,
 		models.ReasonSkipped,
 	}
 
 	reasonLabels := map[models.DifferenceReason]string{
 		models.ReasonCopyError:    "Copy Errors",
 		models.ReasonUpdateError:  "Update Errors",
+		models.ReasonVerifyFailed: "Verification Failures",
+		models.ReasonBitRot:       "Bit Rot",
 		models.ReasonDeleted:      "Deleted from Destination",
 		models.ReasonOnlyInSource: "Only in Source",
 		models.ReasonOnlyInDest:   "Only in Destination",
 		models.ReasonHashDiff:     "Hash Differences",
 		models.ReasonContentDiff:  "Content Differences",
 		models.ReasonSizeDiff:     "Size Differences",
+		models.ReasonMetadataDiff: "Metadata Differences",
 		models.ReasonSkipped:      "Skipped Files",
 	}
 
@@ -135,14 +151,20 @@ func writeDifferencesHuman(report *models.SyncReport, w io.Writer) error {
 
 		for _, diff := range diffs {
 			fmt.Fprintf(w, "  %s\n", diff.RelativePath)
-			if diff.Details != "" {
+			if strings.Contains(diff.Details, "\n") {
+				// Multi-line details (e.g., a diff excerpt) are printed as an indented block
+				fmt.Fprintf(w, "    Details:\n")
+				for _, line := range strings.Split(strings.TrimSuffix(diff.Details, "\n"), "\n") {
+					fmt.Fprintf(w, "      %s\n", line)
+				}
+			} else if diff.Details != "" {
 				fmt.Fprintf(w, "    Details: %s\n", diff.Details)
 			}
 
 			if diff.SourceInfo != nil {
 				fmt.Fprintf(w, "    Source:  %s", formatBytes(diff.SourceInfo.Size))
 				if diff.SourceInfo.Hash != "" {
-					fmt.Fprintf(w, ", hash: %s", diff.SourceInfo.Hash[:12])
+					fmt.Fprintf(w, ", hash: %s", qualifiedHash(shortHash(diff.SourceInfo.Hash), diff.SourceInfo.HashAlgorithm))
 				}
 				fmt.Fprintf(w, "\n")
 			}
@@ -150,11 +172,18 @@ func writeDifferencesHuman(report *models.SyncReport, w io.Writer) error {
 			if diff.DestInfo != nil {
 				fmt.Fprintf(w, "    Dest:    %s", formatBytes(diff.DestInfo.Size))
 				if diff.DestInfo.Hash != "" {
-					fmt.Fprintf(w, ", hash: %s", diff.DestInfo.Hash[:12])
+					fmt.Fprintf(w, ", hash: %s", qualifiedHash(shortHash(diff.DestInfo.Hash), diff.DestInfo.HashAlgorithm))
 				}
 				fmt.Fprintf(w, "\n")
 			}
 
+			if showDiff && diff.DiffOffset != nil {
+				fmt.Fprintf(w, "    First difference at byte offset %d\n", *diff.DiffOffset)
+			}
+			if showDiff && diff.Diff != "" {
+				writeUnifiedDiff(w, diff.Diff, color)
+			}
+
 			fmt.Fprintf(w, "\n")
 		}
 
@@ -165,7 +194,17 @@ func writeDifferencesHuman(report *models.SyncReport, w io.Writer) error {
 }
 
 // writeDifferencesJSON writes differences in JSON format
-func writeDifferencesJSON(report *models.SyncReport, w io.Writer) error {
+func writeDifferencesJSON(report *models.SyncReport, w io.Writer, showDiff bool) error {
+	differences := report.Differences
+	if !showDiff {
+		differences = make([]models.FileDifference, len(report.Differences))
+		for i, diff := range report.Differences {
+			diff.Diff = ""
+			diff.DiffOffset = nil
+			differences[i] = diff
+		}
+	}
+
 	output := struct {
 		Generated     string                  `json:"generated"`
 		SourcePath    string                  `json:"source_path"`
@@ -186,7 +225,7 @@ func writeDifferencesJSON(report *models.SyncReport, w io.Writer) error {
 		DryRun:        report.DryRun,
 		TotalCount:    len(report.Differences),
 		ConflictCount: len(report.Conflicts),
-		Differences:   report.Differences,
+		Differences:   differences,
 		Conflicts:     report.Conflicts,
 	}
 
@@ -264,3 +303,64 @@ func writeConflictsHuman(conflicts []models.Conflict, w io.Writer) {
 
 	fmt.Fprintf(w, "\n")
 }
+
+// ANSI colors of unified diff lines
+const (
+	colorReset = "\033[0m"
+	colorBold  = "\033[1m"
+	colorRed   = "\033[31m"
+	colorGreen = "\033[32m"
+	colorCyan  = "\033[36m"
+)
+
+// useColor reports whether colored output should be written to a file (a terminal, unless NO_COLOR is set)
+func useColor(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// writeUnifiedDiff writes an indented unified diff, coloring added, removed and hunk lines
+func writeUnifiedDiff(w io.Writer, unified string, color bool) {
+	for _, line := range strings.Split(strings.TrimSuffix(unified, "\n"), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if !color {
+			fmt.Fprintf(w, "      %s\n", line)
+			continue
+		}
+
+		code := ""
+		switch {
+		case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "):
+			code = colorBold
+		case strings.HasPrefix(line, "@@"):
+			code = colorCyan
+		case strings.HasPrefix(line, "+"):
+			code = colorGreen
+		case strings.HasPrefix(line, "-"):
+			code = colorRed
+		}
+		if code == "" {
+			fmt.Fprintf(w, "      %s\n", line)
+		} else {
+			fmt.Fprintf(w, "      %s%s%s\n", code, line, colorReset)
+		}
+	}
+}
+
+// qualifiedHash prefixes a hash with its algorithm name (e.g. "blake3:af13...")
+func qualifiedHash(hash, algorithm string) string {
+	if hash == "" || algorithm == "" {
+		return hash
+	}
+	return algorithm + ":" + hash
+}
+
+// shortHash truncates a hash to 12 characters for human-readable output
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
@@ -8,15 +8,32 @@ import (
 
 // ProgressUpdate represents a progress notification during sync
 type ProgressUpdate struct {
-	Type         string // "file_start", "compare_start", "file_progress", "file_complete", "file_error", "summary"
+	Type         string // "scan_progress", "file_decision", "file_start", "compare_start", "file_progress", "file_complete", "file_error", "conflict", "rate_limit", "summary"
 	FilePath     string
 	BytesWritten int64
 	TotalBytes   int64
 	CurrentFile  int
 	TotalFiles   int
 	Error        error
+	Action       models.Action    // Decided action for "file_decision" updates
+	Reason       string           // Why the action was decided
+	Conflict     *models.Conflict // Conflict details for "conflict" updates
+	Limit        string           // Limit reported by a "rate_limit" update (LimitBandwidth, LimitSourceRead, ...)
+	RateLimit    int64            // Effective limit in bytes or operations per second for "rate_limit" updates (0 = unlimited)
 }
 
+// Limits reported by "rate_limit" updates
+const (
+	LimitBandwidth  = "bandwidth"   // Bandwidth shared by transfers and comparisons, in bytes per second
+	LimitSourceRead = "source_read" // Source reads, in bytes per second
+	LimitDestRead   = "dest_read"   // Destination reads, in bytes per second
+	LimitWrite      = "write"       // Writes, in bytes per second
+	LimitOps        = "ops"         // Storage operations (list, stat, open, write, delete), per second
+)
+
+// limitOrder is the order in which limits are displayed
+var limitOrder = []string{LimitBandwidth, LimitSourceRead, LimitDestRead, LimitWrite, LimitOps}
+
 // Formatter defines the interface for output formatting
 // Implementations include human-readable and JSON formatters
 type Formatter interface {
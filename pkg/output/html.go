@@ -0,0 +1,324 @@
+package output
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/models"
+)
+
+// htmlReportData is the data passed to the HTML report template
+type htmlReportData struct {
+	Generated   string
+	Report      *models.SyncReport
+	Duration    string
+	Stats       []htmlStat
+	Operations  []htmlOperation
+	Differences []htmlDifference
+	Errors      []htmlError
+	Conflicts   []htmlConflict
+	Tree        *htmlTreeNode
+}
+
+// htmlStat is a single summary tile
+type htmlStat struct {
+	Label string
+	Value string
+}
+
+// htmlOperation is a row of the operations table
+type htmlOperation struct {
+	Path       string
+	Action     string
+	Reason     string
+	Size       int64
+	SizeStr    string
+	DurationMs int64
+	Duration   string
+	Error      string
+}
+
+// htmlDifference is a row of the differences table
+type htmlDifference struct {
+	Path       string
+	Reason     string
+	Details    string
+	SourceSize string
+	DestSize   string
+}
+
+// htmlError is a row of the errors table
+type htmlError struct {
+	Path      string
+	Operation string
+	Error     string
+	Timestamp string
+}
+
+// htmlConflict is a row of the conflicts table
+type htmlConflict struct {
+	Path     string
+	Type     string
+	Strategy string
+	Winner   string
+	Result   string
+}
+
+// htmlTreeNode is a directory in the changed-directories tree
+type htmlTreeNode struct {
+	Name     string
+	Changes  int // Changed files in this directory and below
+	Files    []string
+	Children []*htmlTreeNode
+	children map[string]*htmlTreeNode
+}
+
+// writeDifferencesHTML writes a self-contained HTML report
+func writeDifferencesHTML(report *models.SyncReport, w io.Writer) error {
+	data := htmlReportData{
+		Generated: time.Now().Format(time.RFC3339),
+		Report:    report,
+		Duration:  report.Duration.Round(time.Millisecond).String(),
+		Stats: []htmlStat{
+			{"Files copied", fmt.Sprint(report.Stats.FilesCopied.Load())},
+			{"Files updated", fmt.Sprint(report.Stats.FilesUpdated.Load())},
+			{"Metadata updated", fmt.Sprint(report.Stats.MetadataUpdated.Load())},
+			{"Files deleted", fmt.Sprint(report.Stats.FilesDeleted.Load())},
+			{"Files synchronized", fmt.Sprint(report.Stats.FilesSynchronized.Load())},
+			{"Files skipped", fmt.Sprint(report.Stats.FilesSkipped.Load())},
+			{"Files errored", fmt.Sprint(report.Stats.FilesErrored.Load())},
+			{"Hard links", fmt.Sprint(report.Stats.HardLinks.Load())},
+			{"Fast copies", fmt.Sprint(report.Stats.FastCopies.Load())},
+			{"Dirs created", fmt.Sprint(report.Stats.DirsCreated.Load())},
+			{"Dirs updated", fmt.Sprint(report.Stats.DirsUpdated.Load())},
+			{"Dirs deleted", fmt.Sprint(report.Stats.DirsDeleted.Load())},
+			{"Data transferred", formatBytes(report.Stats.BytesTransferred.Load())},
+			{"Disk space allocated", formatBytes(report.Stats.BytesAllocated.Load())},
+		},
+		Tree: &htmlTreeNode{Name: "/", children: make(map[string]*htmlTreeNode)},
+	}
+
+	for _, op := range report.Operations {
+		row := htmlOperation{
+			Action:     string(op.Action),
+			Reason:     op.Reason,
+			DurationMs: op.Duration.Milliseconds(),
+			Duration:   op.Duration.Round(time.Millisecond).String(),
+		}
+		if op.Entry != nil {
+			row.Path = op.Entry.RelativePath
+			row.Size = op.Entry.Size
+			row.SizeStr = formatBytes(op.Entry.Size)
+		}
+		if op.Error != nil {
+			row.Error = op.Error.Error()
+		}
+		data.Operations = append(data.Operations, row)
+	}
+
+	for _, diff := range report.Differences {
+		row := htmlDifference{
+			Path:    diff.RelativePath,
+			Reason:  string(diff.Reason),
+			Details: diff.Details,
+		}
+		if diff.SourceInfo != nil {
+			row.SourceSize = formatBytes(diff.SourceInfo.Size)
+		}
+		if diff.DestInfo != nil {
+			row.DestSize = formatBytes(diff.DestInfo.Size)
+		}
+		data.Differences = append(data.Differences, row)
+
+		if diff.Reason != models.ReasonSkipped {
+			data.Tree.add(diff.RelativePath)
+		}
+	}
+
+	for _, e := range report.Errors {
+		data.Errors = append(data.Errors, htmlError{
+			Path:      e.FilePath,
+			Operation: string(e.Operation),
+			Error:     e.Error,
+			Timestamp: e.Timestamp.Format(time.RFC3339),
+		})
+	}
+
+	for _, c := range report.Conflicts {
+		data.Conflicts = append(data.Conflicts, htmlConflict{
+			Path:     c.Path,
+			Type:     string(c.Type),
+			Strategy: string(c.Resolution),
+			Winner:   c.Winner,
+			Result:   c.ResultDescription,
+		})
+		data.Tree.add(c.Path)
+	}
+
+	data.Tree.finalize()
+
+	return htmlReportTemplate.Execute(w, data)
+}
+
+// add records a changed file in the tree, creating intermediate directories
+func (n *htmlTreeNode) add(relativePath string) {
+	p := filepath.ToSlash(relativePath)
+	dir, file := path.Split(p)
+
+	node := n
+	node.Changes++
+	for _, part := range strings.Split(strings.Trim(dir, "/"), "/") {
+		if part == "" {
+			continue
+		}
+		child, ok := node.children[part]
+		if !ok {
+			child = &htmlTreeNode{Name: part, children: make(map[string]*htmlTreeNode)}
+			node.children[part] = child
+		}
+		child.Changes++
+		node = child
+	}
+	node.Files = append(node.Files, file)
+}
+
+// finalize sorts children and files for stable output
+func (n *htmlTreeNode) finalize() {
+	n.Children = make([]*htmlTreeNode, 0, len(n.children))
+	for _, child := range n.children {
+		child.finalize()
+		n.Children = append(n.Children, child)
+	}
+	sort.Slice(n.Children, func(i, j int) bool { return n.Children[i].Name < n.Children[j].Name })
+	sort.Strings(n.Files)
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>syncnorris report - {{.Report.SourcePath}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #222; }
+h1 { margin-bottom: 0.2em; }
+h2 { margin-top: 2em; border-bottom: 1px solid #ddd; padding-bottom: 0.2em; }
+.meta { color: #666; }
+.meta td { padding-right: 1.5em; }
+.status { font-weight: bold; text-transform: uppercase; }
+.status-success { color: #1a7f37; }
+.status-partial { color: #9a6700; }
+.status-failed, .status-cancelled { color: #cf222e; }
+.stats { display: flex; flex-wrap: wrap; gap: 0.8em; margin-top: 1em; }
+.stat { border: 1px solid #ddd; border-radius: 6px; padding: 0.6em 1em; min-width: 8em; }
+.stat .value { font-size: 1.4em; font-weight: bold; }
+.stat .label { color: #666; font-size: 0.85em; }
+table.data { border-collapse: collapse; width: 100%; font-size: 0.9em; }
+table.data th, table.data td { border-bottom: 1px solid #eee; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+table.data th { background: #f6f8fa; cursor: pointer; user-select: none; white-space: nowrap; }
+table.data th.asc::after { content: " \25B2"; }
+table.data th.desc::after { content: " \25BC"; }
+table.data td.path { font-family: monospace; word-break: break-all; }
+table.data td.details { white-space: pre-wrap; }
+input.filter { margin: 0.5em 0; padding: 0.3em; width: 20em; }
+.empty { color: #666; font-style: italic; }
+ul.tree { list-style: none; padding-left: 1.2em; font-family: monospace; }
+ul.tree .count { color: #666; }
+</style>
+</head>
+<body>
+<h1>syncnorris report</h1>
+<table class="meta">
+<tr><td>Source</td><td>{{.Report.SourcePath}}</td></tr>
+<tr><td>Destination</td><td>{{.Report.DestPath}}</td></tr>
+<tr><td>Mode</td><td>{{.Report.Mode}}{{if .Report.DryRun}} (dry-run){{end}}</td></tr>
+<tr><td>Status</td><td class="status status-{{.Report.Status}}">{{.Report.Status}}</td></tr>
+<tr><td>Duration</td><td>{{.Duration}}</td></tr>
+<tr><td>Generated</td><td>{{.Generated}}</td></tr>
+</table>
+
+<h2>Summary</h2>
+<div class="stats">
+{{range .Stats}}<div class="stat"><div class="value">{{.Value}}</div><div class="label">{{.Label}}</div></div>
+{{end}}</div>
+
+<h2>Changed directories</h2>
+{{if .Tree.Changes}}{{template "tree" .Tree}}{{else}}<p class="empty">No changes.</p>{{end}}
+
+<h2>Differences ({{len .Differences}})</h2>
+{{if .Differences}}<input class="filter" type="search" placeholder="Filter..." data-table="differences">
+<table class="data" id="differences">
+<thead><tr><th>Path</th><th>Reason</th><th>Details</th><th>Source</th><th>Destination</th></tr></thead>
+<tbody>
+{{range .Differences}}<tr><td class="path">{{.Path}}</td><td>{{.Reason}}</td><td class="details">{{.Details}}</td><td>{{.SourceSize}}</td><td>{{.DestSize}}</td></tr>
+{{end}}</tbody>
+</table>{{else}}<p class="empty">No differences.</p>{{end}}
+
+<h2>Conflicts ({{len .Conflicts}})</h2>
+{{if .Conflicts}}<input class="filter" type="search" placeholder="Filter..." data-table="conflicts">
+<table class="data" id="conflicts">
+<thead><tr><th>Path</th><th>Type</th><th>Strategy</th><th>Winner</th><th>Result</th></tr></thead>
+<tbody>
+{{range .Conflicts}}<tr><td class="path">{{.Path}}</td><td>{{.Type}}</td><td>{{.Strategy}}</td><td>{{.Winner}}</td><td>{{.Result}}</td></tr>
+{{end}}</tbody>
+</table>{{else}}<p class="empty">No conflicts.</p>{{end}}
+
+<h2>Errors ({{len .Errors}})</h2>
+{{if .Errors}}<input class="filter" type="search" placeholder="Filter..." data-table="errors">
+<table class="data" id="errors">
+<thead><tr><th>Path</th><th>Operation</th><th>Error</th><th>Time</th></tr></thead>
+<tbody>
+{{range .Errors}}<tr><td class="path">{{.Path}}</td><td>{{.Operation}}</td><td>{{.Error}}</td><td>{{.Timestamp}}</td></tr>
+{{end}}</tbody>
+</table>{{else}}<p class="empty">No errors.</p>{{end}}
+
+<h2>Operations ({{len .Operations}})</h2>
+{{if .Operations}}<input class="filter" type="search" placeholder="Filter..." data-table="operations">
+<table class="data" id="operations">
+<thead><tr><th>Path</th><th>Action</th><th>Reason</th><th>Size</th><th>Duration</th><th>Error</th></tr></thead>
+<tbody>
+{{range .Operations}}<tr><td class="path">{{.Path}}</td><td>{{.Action}}</td><td>{{.Reason}}</td><td data-sort="{{.Size}}">{{.SizeStr}}</td><td data-sort="{{.DurationMs}}">{{.Duration}}</td><td>{{.Error}}</td></tr>
+{{end}}</tbody>
+</table>{{else}}<p class="empty">No operations.</p>{{end}}
+
+<script>
+document.querySelectorAll("input.filter").forEach(function (input) {
+  input.addEventListener("input", function () {
+    var needle = input.value.toLowerCase();
+    document.querySelectorAll("#" + input.dataset.table + " tbody tr").forEach(function (row) {
+      row.style.display = row.textContent.toLowerCase().indexOf(needle) >= 0 ? "" : "none";
+    });
+  });
+});
+document.querySelectorAll("table.data th").forEach(function (th) {
+  th.addEventListener("click", function () {
+    var table = th.closest("table"), tbody = table.tBodies[0];
+    var index = Array.prototype.indexOf.call(th.parentNode.children, th);
+    var asc = !th.classList.contains("asc");
+    table.querySelectorAll("th").forEach(function (h) { h.classList.remove("asc", "desc"); });
+    th.classList.add(asc ? "asc" : "desc");
+    var key = function (row) {
+      var cell = row.children[index];
+      return cell.dataset.sort !== undefined ? parseFloat(cell.dataset.sort) : cell.textContent.toLowerCase();
+    };
+    var rows = Array.prototype.slice.call(tbody.rows);
+    rows.sort(function (a, b) {
+      var ka = key(a), kb = key(b);
+      return (ka < kb ? -1 : ka > kb ? 1 : 0) * (asc ? 1 : -1);
+    });
+    rows.forEach(function (row) { tbody.appendChild(row); });
+  });
+});
+</script>
+</body>
+</html>
+{{define "tree"}}<ul class="tree">
+{{range .Children}}<li><details open><summary>{{.Name}}/ <span class="count">({{.Changes}})</span></summary>{{template "tree" .}}</details></li>
+{{end}}{{range .Files}}<li>{{.}}</li>
+{{end}}</ul>{{end}}
+`))
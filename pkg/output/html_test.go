@@ -0,0 +1,99 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/models"
+)
+
+// TestWriteDifferencesHTML tests escaping and the sections of the HTML report
+func TestWriteDifferencesHTML(t *testing.T) {
+	report := &models.SyncReport{
+		SourcePath: "/src/<a&b>",
+		DestPath:   "/dst",
+		Status:     models.StatusPartial,
+		Duration:   1500 * time.Millisecond,
+		Operations: []models.FileOperation{
+			{Entry: &models.FileEntry{RelativePath: "docs/<draft> & notes.txt", Size: 2048}, Action: models.ActionUpdate, Reason: "file updated from source"},
+			{Entry: &models.FileEntry{RelativePath: "broken.txt"}, Action: models.ActionSkip, Reason: "processing failed", Error: errors.New("open <broken.txt>: permission denied")},
+		},
+		Differences: []models.FileDifference{
+			{
+				RelativePath: "docs/<draft> & notes.txt",
+				Reason:       models.ReasonContentDiff,
+				Details:      "size <1K> & newer",
+				SourceInfo:   &models.FileInfo{Size: 2048},
+				DestInfo:     &models.FileInfo{Size: 1024},
+			},
+			{RelativePath: "docs/new.txt", Reason: models.ReasonOnlyInSource, Details: "file exists only in source"},
+			{RelativePath: "cache/tmp.bin", Reason: models.ReasonSkipped, Details: "file skipped"},
+		},
+		Conflicts: []models.Conflict{
+			{Path: "shared/a&b.txt", Type: models.ConflictModifyModify, Resolution: models.ConflictNewer, Winner: "source"},
+		},
+		Errors: []models.SyncError{
+			{FilePath: "broken.txt", Operation: models.ActionSkip, Error: "open <broken.txt>: permission denied"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := writeDifferencesHTML(report, &buf); err != nil {
+		t.Fatalf("writeDifferencesHTML() error = %v", err)
+	}
+	html := buf.String()
+
+	// Paths, details and errors are escaped, never injected as markup
+	for _, raw := range []string{"<draft>", "<a&b>", "<1K>", "<broken.txt>", "a&b.txt"} {
+		if strings.Contains(html, raw) {
+			t.Errorf("report contains unescaped %q", raw)
+		}
+	}
+	for _, escaped := range []string{
+		"docs/&lt;draft&gt; &amp; notes.txt",
+		"/src/&lt;a&amp;b&gt;",
+		"size &lt;1K&gt; &amp; newer",
+		"open &lt;broken.txt&gt;: permission denied",
+		"shared/a&amp;b.txt",
+	} {
+		if !strings.Contains(html, escaped) {
+			t.Errorf("report does not contain escaped %q", escaped)
+		}
+	}
+
+	// Every section is present with its row count
+	for _, section := range []string{
+		"<h2>Summary</h2>",
+		"<h2>Changed directories</h2>",
+		"<h2>Differences (3)</h2>",
+		"<h2>Conflicts (1)</h2>",
+		"<h2>Errors (1)</h2>",
+		"<h2>Operations (2)</h2>",
+	} {
+		if !strings.Contains(html, section) {
+			t.Errorf("report does not contain section %q", section)
+		}
+	}
+
+	// Each difference row carries its reason
+	for _, row := range []string{
+		`<td class="path">docs/&lt;draft&gt; &amp; notes.txt</td><td>content_different</td>`,
+		`<td class="path">docs/new.txt</td><td>only_in_source</td>`,
+		`<td class="path">cache/tmp.bin</td><td>skipped</td>`,
+	} {
+		if !strings.Contains(html, row) {
+			t.Errorf("differences table does not contain %q", row)
+		}
+	}
+
+	// The directory tree lists changed files but leaves skipped ones out
+	if !strings.Contains(html, "<summary>docs/ <span class=\"count\">(2)</span></summary>") {
+		t.Errorf("changed directories tree does not count 2 changes under docs/")
+	}
+	if strings.Contains(html, "<summary>cache/") {
+		t.Errorf("changed directories tree lists the skipped cache/ directory")
+	}
+}
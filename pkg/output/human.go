@@ -57,6 +57,9 @@ func (f *HumanFormatter) Progress(update ProgressUpdate) error {
 		fmt.Fprintf(f.writer, "[%d/%d] ✗ %s: %v\n",
 			update.CurrentFile, f.totalFiles,
 			update.FilePath, update.Error)
+
+	case "rate_limit":
+		fmt.Fprintf(f.writer, "%s: %s\n", limitLabels[update.Limit], formatLimit(update.Limit, update.RateLimit))
 	}
 
 	return nil
@@ -80,15 +83,30 @@ func (f *HumanFormatter) Complete(report *models.SyncReport) error {
 	fmt.Fprintf(f.writer, "  Operations:\n")
 	fmt.Fprintf(f.writer, "    Files copied:       %d\n", report.Stats.FilesCopied.Load())
 	fmt.Fprintf(f.writer, "    Files updated:      %d\n", report.Stats.FilesUpdated.Load())
+	if metadataUpdated := report.Stats.MetadataUpdated.Load(); metadataUpdated > 0 {
+		fmt.Fprintf(f.writer, "    Metadata updated:   %d\n", metadataUpdated)
+	}
 	fmt.Fprintf(f.writer, "    Files deleted:      %d\n", report.Stats.FilesDeleted.Load())
 	fmt.Fprintf(f.writer, "    Files synchronized: %d\n", report.Stats.FilesSynchronized.Load())
 	fmt.Fprintf(f.writer, "    Files skipped:      %d\n", report.Stats.FilesSkipped.Load())
 	fmt.Fprintf(f.writer, "    Files errored:      %d\n", report.Stats.FilesErrored.Load())
+	if hardLinks := report.Stats.HardLinks.Load(); hardLinks > 0 {
+		fmt.Fprintf(f.writer, "    Hard links:         %d\n", hardLinks)
+	}
+	if fastCopies := report.Stats.FastCopies.Load(); fastCopies > 0 {
+		fmt.Fprintf(f.writer, "    Fast copies:        %d\n", fastCopies)
+	}
 	fmt.Fprintf(f.writer, "    Dirs created:       %d\n", report.Stats.DirsCreated.Load())
+	if dirsUpdated := report.Stats.DirsUpdated.Load(); dirsUpdated > 0 {
+		fmt.Fprintf(f.writer, "    Dirs updated:       %d\n", dirsUpdated)
+	}
 	fmt.Fprintf(f.writer, "    Dirs deleted:       %d\n", report.Stats.DirsDeleted.Load())
 	fmt.Fprintf(f.writer, "\n")
 	fmt.Fprintf(f.writer, "  Transfer:\n")
 	fmt.Fprintf(f.writer, "    Data:           %s\n", formatBytes(report.Stats.BytesTransferred.Load()))
+	if sparseFiles := report.Stats.SparseFiles.Load(); sparseFiles > 0 {
+		fmt.Fprintf(f.writer, "    Allocated:      %s (%d sparse files)\n", formatBytes(report.Stats.BytesAllocated.Load()), sparseFiles)
+	}
 
 	if report.Duration.Seconds() > 0 {
 		avgSpeed := float64(report.Stats.BytesTransferred.Load()) / report.Duration.Seconds()
@@ -135,6 +153,26 @@ func formatBytes(bytes int64) string {
 	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
+// limitLabels names the limits reported by "rate_limit" updates
+var limitLabels = map[string]string{
+	LimitBandwidth:  "Bandwidth limit",
+	LimitSourceRead: "Source read limit",
+	LimitDestRead:   "Destination read limit",
+	LimitWrite:      "Write limit",
+	LimitOps:        "Operation limit",
+}
+
+// formatLimit formats a limit in bytes per second, or operations per second for LimitOps (0 = unlimited)
+func formatLimit(limit string, rate int64) string {
+	if rate <= 0 {
+		return "unlimited"
+	}
+	if limit == LimitOps {
+		return fmt.Sprintf("%d ops/s", rate)
+	}
+	return formatBytes(rate) + "/s"
+}
+
 // formatDuration formats duration in human-readable format
 func formatDuration(d time.Duration) string {
 	if d < time.Minute {
@@ -51,11 +51,14 @@ type JSONReportData struct {
 
 // JSONDifferenceData represents a file difference
 type JSONDifferenceData struct {
-	Path       string          `json:"path"`
-	Reason     string          `json:"reason"`
-	Details    string          `json:"details,omitempty"`
+	Path       string            `json:"path"`
+	Reason     string            `json:"reason"`
+	Details    string            `json:"details,omitempty"`
+	Stage      string            `json:"stage,omitempty"` // Comparison chain stage that decided the difference
 	SourceInfo *JSONFileInfoData `json:"source_info,omitempty"`
 	DestInfo   *JSONFileInfoData `json:"dest_info,omitempty"`
+	Diff       string            `json:"diff,omitempty"`        // Unified diff from destination to source (--show-diff)
+	DiffOffset *int64            `json:"diff_offset,omitempty"` // First differing byte of binary files (--show-diff)
 }
 
 // JSONFileInfoData represents file info in JSON
@@ -63,6 +66,8 @@ type JSONFileInfoData struct {
 	Size    int64  `json:"size"`
 	ModTime string `json:"mod_time"`
 	Hash    string `json:"hash,omitempty"`
+	// HashAlgorithm names the algorithm Hash was computed with
+	HashAlgorithm string `json:"hash_algorithm,omitempty"`
 }
 
 // JSONStatsData represents statistics in JSON format
@@ -86,25 +91,32 @@ type JSONScannedData struct {
 type JSONOperationsData struct {
 	FilesCopied       int32 `json:"files_copied"`
 	FilesUpdated      int32 `json:"files_updated"`
+	MetadataUpdated   int32 `json:"metadata_updated"`
 	FilesDeleted      int32 `json:"files_deleted"`
 	FilesSynchronized int32 `json:"files_synchronized"`
 	FilesSkipped      int32 `json:"files_skipped"`
 	FilesErrored      int32 `json:"files_errored"`
+	HardLinks         int32 `json:"hard_links"`
+	FastCopies        int32 `json:"fast_copies"`
 	DirsCreated       int32 `json:"dirs_created"`
+	DirsUpdated       int32 `json:"dirs_updated"`
 	DirsDeleted       int32 `json:"dirs_deleted"`
 }
 
 // JSONTransferData represents transfer statistics
 type JSONTransferData struct {
 	BytesTransferred int64  `json:"bytes_transferred"`
+	BytesAllocated   int64  `json:"bytes_allocated"`
+	SparseFiles      int32  `json:"sparse_files"`
 	AverageSpeed     int64  `json:"average_speed_bytes_per_sec,omitempty"`
 	AverageSpeedStr  string `json:"average_speed,omitempty"`
 }
 
 // JSONErrorData represents an error entry
 type JSONErrorData struct {
-	Path  string `json:"path"`
-	Error string `json:"error"`
+	Path      string `json:"path"`
+	Operation string `json:"operation,omitempty"`
+	Error     string `json:"error"`
 }
 
 // NewJSONFormatter creates a new JSON formatter
@@ -151,20 +163,13 @@ func (f *JSONFormatter) Complete(report *models.SyncReport) error {
 		f.writer = io.Discard
 	}
 
-	// Calculate average speed
-	var avgSpeed int64
-	var avgSpeedStr string
-	if report.Duration.Seconds() > 0 {
-		avgSpeed = int64(float64(report.Stats.BytesTransferred.Load()) / report.Duration.Seconds())
-		avgSpeedStr = formatBytes(avgSpeed) + "/s"
-	}
-
 	// Build errors list
 	var errors []JSONErrorData
 	for _, err := range report.Errors {
 		errors = append(errors, JSONErrorData{
-			Path:  err.FilePath,
-			Error: err.Error,
+			Path:      err.FilePath,
+			Operation: string(err.Operation),
+			Error:     err.Error,
 		})
 	}
 
@@ -175,12 +180,18 @@ func (f *JSONFormatter) Complete(report *models.SyncReport) error {
 			Path:    diff.RelativePath,
 			Reason:  string(diff.Reason),
 			Details: diff.Details,
+			Stage:   diff.Stage,
+
+			Diff:       diff.Diff,
+			DiffOffset: diff.DiffOffset,
 		}
 		if diff.SourceInfo != nil {
 			diffData.SourceInfo = &JSONFileInfoData{
 				Size:    diff.SourceInfo.Size,
 				ModTime: diff.SourceInfo.ModTime.Format(time.RFC3339),
 				Hash:    diff.SourceInfo.Hash,
+
+				HashAlgorithm: diff.SourceInfo.HashAlgorithm,
 			}
 		}
 		if diff.DestInfo != nil {
@@ -188,6 +199,8 @@ func (f *JSONFormatter) Complete(report *models.SyncReport) error {
 				Size:    diff.DestInfo.Size,
 				ModTime: diff.DestInfo.ModTime.Format(time.RFC3339),
 				Hash:    diff.DestInfo.Hash,
+
+				HashAlgorithm: diff.DestInfo.HashAlgorithm,
 			}
 		}
 		differences = append(differences, diffData)
@@ -196,33 +209,9 @@ func (f *JSONFormatter) Complete(report *models.SyncReport) error {
 	// Create report data
 	reportData := JSONReportData{
 		Status:      string(report.Status),
-		Duration:   report.Duration.Round(time.Millisecond).String(),
-		DurationMs: report.Duration.Milliseconds(),
-		Stats: JSONStatsData{
-			Scanned: JSONScannedData{
-				SourceFiles: report.Stats.SourceFilesScanned.Load(),
-				SourceDirs:  report.Stats.SourceDirsScanned.Load(),
-				DestFiles:   report.Stats.DestFilesScanned.Load(),
-				DestDirs:    report.Stats.DestDirsScanned.Load(),
-				UniqueFiles: report.Stats.FilesScanned.Load(),
-				UniqueDirs:  report.Stats.DirsScanned.Load(),
-			},
-			Operations: JSONOperationsData{
-				FilesCopied:       report.Stats.FilesCopied.Load(),
-				FilesUpdated:      report.Stats.FilesUpdated.Load(),
-				FilesDeleted:      report.Stats.FilesDeleted.Load(),
-				FilesSynchronized: report.Stats.FilesSynchronized.Load(),
-				FilesSkipped:      report.Stats.FilesSkipped.Load(),
-				FilesErrored:      report.Stats.FilesErrored.Load(),
-				DirsCreated:       report.Stats.DirsCreated.Load(),
-				DirsDeleted:       report.Stats.DirsDeleted.Load(),
-			},
-			Transfer: JSONTransferData{
-				BytesTransferred: report.Stats.BytesTransferred.Load(),
-				AverageSpeed:     avgSpeed,
-				AverageSpeedStr:  avgSpeedStr,
-			},
-		},
+		Duration:    report.Duration.Round(time.Millisecond).String(),
+		DurationMs:  report.Duration.Milliseconds(),
+		Stats:       newJSONStatsData(report),
 		Differences: differences,
 		Errors:      errors,
 	}
@@ -242,6 +231,49 @@ func (f *JSONFormatter) Complete(report *models.SyncReport) error {
 	return encoder.Encode(reportData)
 }
 
+// newJSONStatsData builds the statistics section of a JSON report
+func newJSONStatsData(report *models.SyncReport) JSONStatsData {
+	// Calculate average speed
+	var avgSpeed int64
+	var avgSpeedStr string
+	if report.Duration.Seconds() > 0 {
+		avgSpeed = int64(float64(report.Stats.BytesTransferred.Load()) / report.Duration.Seconds())
+		avgSpeedStr = formatBytes(avgSpeed) + "/s"
+	}
+
+	return JSONStatsData{
+		Scanned: JSONScannedData{
+			SourceFiles: report.Stats.SourceFilesScanned.Load(),
+			SourceDirs:  report.Stats.SourceDirsScanned.Load(),
+			DestFiles:   report.Stats.DestFilesScanned.Load(),
+			DestDirs:    report.Stats.DestDirsScanned.Load(),
+			UniqueFiles: report.Stats.FilesScanned.Load(),
+			UniqueDirs:  report.Stats.DirsScanned.Load(),
+		},
+		Operations: JSONOperationsData{
+			FilesCopied:       report.Stats.FilesCopied.Load(),
+			FilesUpdated:      report.Stats.FilesUpdated.Load(),
+			MetadataUpdated:   report.Stats.MetadataUpdated.Load(),
+			FilesDeleted:      report.Stats.FilesDeleted.Load(),
+			FilesSynchronized: report.Stats.FilesSynchronized.Load(),
+			FilesSkipped:      report.Stats.FilesSkipped.Load(),
+			FilesErrored:      report.Stats.FilesErrored.Load(),
+			HardLinks:         report.Stats.HardLinks.Load(),
+			FastCopies:        report.Stats.FastCopies.Load(),
+			DirsCreated:       report.Stats.DirsCreated.Load(),
+			DirsUpdated:       report.Stats.DirsUpdated.Load(),
+			DirsDeleted:       report.Stats.DirsDeleted.Load(),
+		},
+		Transfer: JSONTransferData{
+			BytesTransferred: report.Stats.BytesTransferred.Load(),
+			BytesAllocated:   report.Stats.BytesAllocated.Load(),
+			SparseFiles:      report.Stats.SparseFiles.Load(),
+			AverageSpeed:     avgSpeed,
+			AverageSpeedStr:  avgSpeedStr,
+		},
+	}
+}
+
 // Error reports an error
 func (f *JSONFormatter) Error(err error) error {
 	f.events = append(f.events, JSONEvent{
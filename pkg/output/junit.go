@@ -0,0 +1,214 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/models"
+)
+
+// junitTestSuites is the root element of a JUnit XML report
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite groups test cases by category
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a single file check
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+// junitMessage is the body of a failure, error or skipped element
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// writeDifferencesJUnit writes differences as a JUnit XML report
+// Each difference becomes a failed test case, identical files become passing test cases,
+// conflicts are reported as failures and sync errors (including failed copies) as errors
+func writeDifferencesJUnit(report *models.SyncReport, w io.Writer) error {
+	timestamp := report.StartTime.Format(time.RFC3339)
+	if report.StartTime.IsZero() {
+		timestamp = time.Now().Format(time.RFC3339)
+	}
+
+	// Skipped files without an error or a difference are in sync, whatever comparison reason they carry
+	differing := make(map[string]bool, len(report.Differences))
+	for _, diff := range report.Differences {
+		differing[diff.RelativePath] = true
+	}
+
+	files := junitTestSuite{Name: "files", Timestamp: timestamp}
+	for _, op := range report.Operations {
+		if op.Entry == nil || op.Action != models.ActionSkip || op.Error != nil || differing[op.Entry.RelativePath] {
+			continue
+		}
+		files.Cases = append(files.Cases, junitTestCase{
+			Name:      op.Entry.RelativePath,
+			ClassName: "synchronized",
+			Time:      junitSeconds(op.Duration),
+		})
+	}
+	for _, diff := range report.Differences {
+		// Failed copies, updates and verifications are reported once, in the errors suite
+		if isErrorReason(diff.Reason) {
+			continue
+		}
+		tc := junitTestCase{
+			Name:      diff.RelativePath,
+			ClassName: string(diff.Reason),
+			Time:      "0",
+		}
+		msg := &junitMessage{
+			Message: differenceMessage(diff),
+			Type:    string(diff.Reason),
+			Text:    differenceText(diff),
+		}
+		if diff.Reason == models.ReasonSkipped {
+			tc.Skipped = msg
+			files.Skipped++
+		} else {
+			tc.Failure = msg
+			files.Failures++
+		}
+		files.Cases = append(files.Cases, tc)
+	}
+	files.Tests = len(files.Cases)
+
+	conflicts := junitTestSuite{Name: "conflicts", Timestamp: timestamp}
+	for _, c := range report.Conflicts {
+		conflicts.Cases = append(conflicts.Cases, junitTestCase{
+			Name:      c.Path,
+			ClassName: string(c.Type),
+			Time:      "0",
+			Failure: &junitMessage{
+				Message: fmt.Sprintf("conflict (%s) resolved with %s", c.Type, c.Resolution),
+				Type:    string(c.Type),
+				Text:    conflictText(c),
+			},
+		})
+	}
+	conflicts.Tests = len(conflicts.Cases)
+	conflicts.Failures = len(conflicts.Cases)
+
+	errs := junitTestSuite{Name: "errors", Timestamp: timestamp}
+	for _, e := range report.Errors {
+		errs.Cases = append(errs.Cases, junitTestCase{
+			Name:      e.FilePath,
+			ClassName: string(e.Operation),
+			Time:      "0",
+			Error: &junitMessage{
+				Message: e.Error,
+				Type:    string(e.Operation),
+			},
+		})
+	}
+	errs.Tests = len(errs.Cases)
+	errs.Errors = len(errs.Cases)
+
+	suites := junitTestSuites{
+		Name:   fmt.Sprintf("syncnorris %s -> %s", report.SourcePath, report.DestPath),
+		Time:   junitSeconds(report.Duration),
+		Suites: []junitTestSuite{files, conflicts, errs},
+	}
+	for _, s := range suites.Suites {
+		suites.Tests += s.Tests
+		suites.Failures += s.Failures
+		suites.Errors += s.Errors
+		suites.Skipped += s.Skipped
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// junitSeconds formats a duration in seconds as expected by JUnit consumers
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// isErrorReason reports whether a difference duplicates an entry of the report errors
+func isErrorReason(reason models.DifferenceReason) bool {
+	switch reason {
+	case models.ReasonCopyError, models.ReasonUpdateError, models.ReasonVerifyFailed:
+		return true
+	}
+	return false
+}
+
+// differenceMessage returns a one-line summary of a difference
+func differenceMessage(diff models.FileDifference) string {
+	if diff.Details != "" {
+		return diff.Details
+	}
+	return string(diff.Reason)
+}
+
+// differenceText returns the source/destination details of a difference
+func differenceText(diff models.FileDifference) string {
+	text := ""
+	if diff.SourceInfo != nil {
+		text += fmt.Sprintf("source: %d bytes, modified %s", diff.SourceInfo.Size, diff.SourceInfo.ModTime.Format(time.RFC3339))
+		if diff.SourceInfo.Hash != "" {
+			text += ", hash " + qualifiedHash(diff.SourceInfo.Hash, diff.SourceInfo.HashAlgorithm)
+		}
+		text += "\n"
+	}
+	if diff.DestInfo != nil {
+		text += fmt.Sprintf("dest: %d bytes, modified %s", diff.DestInfo.Size, diff.DestInfo.ModTime.Format(time.RFC3339))
+		if diff.DestInfo.Hash != "" {
+			text += ", hash " + qualifiedHash(diff.DestInfo.Hash, diff.DestInfo.HashAlgorithm)
+		}
+		text += "\n"
+	}
+	return text
+}
+
+// conflictText returns the resolution details of a conflict
+func conflictText(c models.Conflict) string {
+	text := ""
+	if c.Winner != "" {
+		text += "winner: " + c.Winner + "\n"
+	}
+	if c.ResultDescription != "" {
+		text += "result: " + c.ResultDescription + "\n"
+	}
+	for _, f := range c.ConflictFiles {
+		text += "conflict copy: " + f + "\n"
+	}
+	return text
+}
@@ -0,0 +1,114 @@
+package output
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/models"
+)
+
+// TestWriteDifferencesJUnit tests test case, failure, error and skipped counts of a JUnit report
+func TestWriteDifferencesJUnit(t *testing.T) {
+	report := &models.SyncReport{
+		SourcePath: "/src",
+		DestPath:   "/dst",
+		StartTime:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Operations: []models.FileOperation{
+			// In sync, whatever reason the comparator gave
+			{Entry: &models.FileEntry{RelativePath: "same.txt"}, Action: models.ActionSkip, Reason: "files are identical"},
+			{Entry: &models.FileEntry{RelativePath: "hashed.bin"}, Action: models.ActionSkip, Reason: "hashes match (xxh3)"},
+			{Entry: &models.FileEntry{RelativePath: "linked.bin"}, Action: models.ActionSkip, Reason: "hard link to same.txt"},
+			// Not passing: failed, copied or listed as a difference
+			{Entry: &models.FileEntry{RelativePath: "broken.txt"}, Action: models.ActionSkip, Reason: "processing failed", Error: errors.New("permission denied")},
+			{Entry: &models.FileEntry{RelativePath: "new.txt"}, Action: models.ActionCopy, Reason: "file copied from source"},
+			{Entry: &models.FileEntry{RelativePath: "ignored.txt"}, Action: models.ActionSkip, Reason: "file skipped"},
+		},
+		Differences: []models.FileDifference{
+			{RelativePath: "new.txt", Reason: models.ReasonOnlyInSource},
+			{RelativePath: "changed.txt", Reason: models.ReasonContentDiff, Details: "file content differs"},
+			{RelativePath: "ignored.txt", Reason: models.ReasonSkipped},
+		},
+		Conflicts: []models.Conflict{
+			{Path: "both.txt", Type: models.ConflictModifyModify, Resolution: models.ConflictNewer},
+		},
+		Errors: []models.SyncError{
+			{FilePath: "broken.txt", Operation: models.ActionSkip, Error: "permission denied"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := writeDifferencesJUnit(report, &buf); err != nil {
+		t.Fatalf("writeDifferencesJUnit() error = %v", err)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("failed to parse JUnit XML: %v\n%s", err, buf.String())
+	}
+
+	if suites.Tests != 8 || suites.Failures != 3 || suites.Errors != 1 || suites.Skipped != 1 {
+		t.Errorf("testsuites counts = tests %d, failures %d, errors %d, skipped %d; want 8, 3, 1, 1",
+			suites.Tests, suites.Failures, suites.Errors, suites.Skipped)
+	}
+	if len(suites.Suites) != 3 {
+		t.Fatalf("got %d test suites, want 3", len(suites.Suites))
+	}
+
+	files := suites.Suites[0]
+	if files.Name != "files" || files.Tests != 6 || files.Failures != 2 || files.Skipped != 1 {
+		t.Errorf("files suite = %s: tests %d, failures %d, skipped %d; want files: 6, 2, 1",
+			files.Name, files.Tests, files.Failures, files.Skipped)
+	}
+
+	passed := map[string]bool{}
+	for _, tc := range files.Cases {
+		if tc.Failure == nil && tc.Error == nil && tc.Skipped == nil {
+			passed[tc.Name] = true
+		}
+	}
+	if len(passed) != 3 || !passed["same.txt"] || !passed["hashed.bin"] || !passed["linked.bin"] {
+		t.Errorf("passing test cases = %v, want same.txt, hashed.bin and linked.bin", passed)
+	}
+
+	if conflicts := suites.Suites[1]; conflicts.Tests != 1 || conflicts.Failures != 1 {
+		t.Errorf("conflicts suite: tests %d, failures %d; want 1, 1", conflicts.Tests, conflicts.Failures)
+	}
+	if errs := suites.Suites[2]; errs.Tests != 1 || errs.Errors != 1 {
+		t.Errorf("errors suite: tests %d, errors %d; want 1, 1", errs.Tests, errs.Errors)
+	}
+}
+
+// TestWriteDifferencesJUnitFailedCopy tests that a failed copy is reported as a single error
+func TestWriteDifferencesJUnitFailedCopy(t *testing.T) {
+	report := &models.SyncReport{
+		Operations: []models.FileOperation{
+			{Entry: &models.FileEntry{RelativePath: "big.iso"}, Action: models.ActionSkip, Reason: "processing failed", Error: errors.New("no space left on device")},
+		},
+		Differences: []models.FileDifference{
+			{RelativePath: "big.iso", Reason: models.ReasonCopyError, Details: "no space left on device"},
+		},
+		Errors: []models.SyncError{
+			{FilePath: "big.iso", Operation: models.ActionCopy, Error: "no space left on device"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := writeDifferencesJUnit(report, &buf); err != nil {
+		t.Fatalf("writeDifferencesJUnit() error = %v", err)
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("failed to parse JUnit XML: %v\n%s", err, buf.String())
+	}
+
+	if suites.Tests != 1 || suites.Failures+suites.Errors != 1 {
+		t.Errorf("testsuites counts = tests %d, failures %d, errors %d; want one failing test case",
+			suites.Tests, suites.Failures, suites.Errors)
+	}
+	if errs := suites.Suites[2]; len(errs.Cases) != 1 || errs.Cases[0].Name != "big.iso" || errs.Cases[0].Error == nil {
+		t.Errorf("errors suite cases = %+v, want big.iso as an error", errs.Cases)
+	}
+}
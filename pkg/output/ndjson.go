@@ -0,0 +1,271 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/models"
+)
+
+// NDJSONSchemaVersion is the version of the NDJSON event schema
+// It is incremented whenever an event type or field changes incompatibly
+const NDJSONSchemaVersion = 1
+
+// ndjsonScanInterval limits how often scan progress events are emitted
+const ndjsonScanInterval = time.Second
+
+// NDJSONFormatter streams one JSON event per line as the sync progresses
+// Unlike JSONFormatter it never buffers per-file data, so memory and output
+// stay proportional to the work in flight rather than to the tree size
+type NDJSONFormatter struct {
+	mu       sync.Mutex
+	writer   io.Writer
+	encoder  *json.Encoder
+	seq      int64
+	lastScan time.Time
+	pending  *NDJSONScanData // Throttled scan totals, flushed before the next other event
+	started  map[string]bool // Files with an open transfer_start event
+}
+
+// NDJSONEvent is a single line of NDJSON output
+type NDJSONEvent struct {
+	Schema    int       `json:"schema"`
+	Seq       int64     `json:"seq"`
+	Timestamp time.Time `json:"timestamp"`
+	Type      string    `json:"type"`
+	Data      any       `json:"data,omitempty"`
+}
+
+// NDJSONStartData is the data of a "start" event
+type NDJSONStartData struct {
+	TotalFiles int   `json:"total_files"`
+	TotalBytes int64 `json:"total_bytes"`
+	MaxWorkers int   `json:"max_workers"`
+}
+
+// NDJSONScanData is the data of a "scan_progress" event
+type NDJSONScanData struct {
+	TotalFiles int   `json:"total_files"`
+	TotalBytes int64 `json:"total_bytes"`
+}
+
+// NDJSONDecisionData is the data of a "decision" event
+type NDJSONDecisionData struct {
+	Path   string `json:"path"`
+	Action string `json:"action"`
+	Reason string `json:"reason,omitempty"`
+	Size   int64  `json:"size,omitempty"`
+}
+
+// NDJSONTransferData is the data of "transfer_start", "transfer_complete" and "file_complete" events
+type NDJSONTransferData struct {
+	Path  string `json:"path"`
+	Bytes int64  `json:"bytes"`
+}
+
+// NDJSONErrorData is the data of an "error" event
+type NDJSONErrorData struct {
+	Path  string `json:"path,omitempty"`
+	Error string `json:"error"`
+}
+
+// NDJSONRateLimitData is the data of a "rate_limit" event
+// Operation limits carry ops_per_second, the other limits bytes_per_second (0 = unlimited)
+type NDJSONRateLimitData struct {
+	Limit          string `json:"limit"`
+	BytesPerSecond *int64 `json:"bytes_per_second,omitempty"`
+	OpsPerSecond   *int64 `json:"ops_per_second,omitempty"`
+}
+
+// NDJSONConflictData is the data of a "conflict" event
+type NDJSONConflictData struct {
+	Path       string   `json:"path"`
+	Type       string   `json:"type"`
+	Resolution string   `json:"resolution"`
+	Winner     string   `json:"winner,omitempty"`
+	Result     string   `json:"result,omitempty"`
+	Files      []string `json:"conflict_files,omitempty"`
+}
+
+// NDJSONCompleteData is the data of the final "complete" event
+type NDJSONCompleteData struct {
+	Status      string        `json:"status"`
+	Duration    string        `json:"duration"`
+	DurationMs  int64         `json:"duration_ms"`
+	Stats       JSONStatsData `json:"stats"`
+	Differences int           `json:"differences"`
+	Conflicts   int           `json:"conflicts"`
+	Errors      int           `json:"errors"`
+}
+
+// NewNDJSONFormatter creates a new NDJSON streaming formatter
+func NewNDJSONFormatter() *NDJSONFormatter {
+	return &NDJSONFormatter{
+		started: make(map[string]bool),
+	}
+}
+
+// Start initializes the formatter and emits the start event
+func (f *NDJSONFormatter) Start(writer io.Writer, totalFiles int, totalBytes int64, maxWorkers int) error {
+	if writer == nil {
+		writer = os.Stdout
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.writer = writer
+	f.encoder = json.NewEncoder(writer)
+
+	return f.emit("start", NDJSONStartData{
+		TotalFiles: totalFiles,
+		TotalBytes: totalBytes,
+		MaxWorkers: maxWorkers,
+	})
+}
+
+// Progress converts progress updates into events
+// Byte-level progress is not streamed to keep the output proportional to the number of files
+func (f *NDJSONFormatter) Progress(update ProgressUpdate) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch update.Type {
+	case "scan_progress":
+		data := NDJSONScanData{
+			TotalFiles: update.TotalFiles,
+			TotalBytes: update.TotalBytes,
+		}
+		now := time.Now()
+		if now.Sub(f.lastScan) < ndjsonScanInterval {
+			f.pending = &data
+			return nil
+		}
+		f.lastScan = now
+		f.pending = nil
+		return f.emit("scan_progress", data)
+
+	case "file_decision":
+		return f.emit("decision", NDJSONDecisionData{
+			Path:   update.FilePath,
+			Action: string(update.Action),
+			Reason: update.Reason,
+			Size:   update.TotalBytes,
+		})
+
+	case "file_start":
+		f.started[update.FilePath] = true
+		return f.emit("transfer_start", NDJSONTransferData{
+			Path:  update.FilePath,
+			Bytes: update.TotalBytes,
+		})
+
+	case "file_complete":
+		// Files completed without a transfer (identical files, dry-run) are reported as file_complete
+		eventType := "file_complete"
+		if f.started[update.FilePath] {
+			eventType = "transfer_complete"
+			delete(f.started, update.FilePath)
+		}
+		return f.emit(eventType, NDJSONTransferData{
+			Path:  update.FilePath,
+			Bytes: update.BytesWritten,
+		})
+
+	case "file_error":
+		delete(f.started, update.FilePath)
+		data := NDJSONErrorData{Path: update.FilePath}
+		if update.Error != nil {
+			data.Error = update.Error.Error()
+		}
+		return f.emit("error", data)
+
+	case "rate_limit":
+		data := NDJSONRateLimitData{Limit: update.Limit}
+		rate := update.RateLimit
+		if update.Limit == LimitOps {
+			data.OpsPerSecond = &rate
+		} else {
+			data.BytesPerSecond = &rate
+		}
+		return f.emit("rate_limit", data)
+
+	case "conflict":
+		if update.Conflict == nil {
+			return nil
+		}
+		c := update.Conflict
+		return f.emit("conflict", NDJSONConflictData{
+			Path:       c.Path,
+			Type:       string(c.Type),
+			Resolution: string(c.Resolution),
+			Winner:     c.Winner,
+			Result:     c.ResultDescription,
+			Files:      c.ConflictFiles,
+		})
+	}
+
+	return nil
+}
+
+// Complete emits the final summary event
+// Per-file differences are not repeated here since they were streamed as decision events
+func (f *NDJSONFormatter) Complete(report *models.SyncReport) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.emit("complete", NDJSONCompleteData{
+		Status:      string(report.Status),
+		Duration:    report.Duration.Round(time.Millisecond).String(),
+		DurationMs:  report.Duration.Milliseconds(),
+		Stats:       newJSONStatsData(report),
+		Differences: len(report.Differences),
+		Conflicts:   len(report.Conflicts),
+		Errors:      len(report.Errors),
+	})
+}
+
+// Error emits an error event that is not tied to a file
+func (f *NDJSONFormatter) Error(err error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.emit("error", NDJSONErrorData{Error: err.Error()})
+}
+
+// Name returns the formatter name
+func (f *NDJSONFormatter) Name() string {
+	return "ndjson"
+}
+
+// emit writes one event line; the caller must hold f.mu
+// Throttled scan totals are written first, so consumers see the final totals before other events
+func (f *NDJSONFormatter) emit(eventType string, data any) error {
+	if f.encoder == nil {
+		f.writer = os.Stdout
+		f.encoder = json.NewEncoder(f.writer)
+	}
+	if pending := f.pending; pending != nil && eventType != "scan_progress" {
+		f.pending = nil
+		f.lastScan = time.Now()
+		if err := f.write("scan_progress", *pending); err != nil {
+			return err
+		}
+	}
+	return f.write(eventType, data)
+}
+
+// write encodes one event line; the caller must hold f.mu
+func (f *NDJSONFormatter) write(eventType string, data any) error {
+	f.seq++
+	return f.encoder.Encode(NDJSONEvent{
+		Schema:    NDJSONSchemaVersion,
+		Seq:       f.seq,
+		Timestamp: time.Now(),
+		Type:      eventType,
+		Data:      data,
+	})
+}
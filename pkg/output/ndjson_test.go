@@ -0,0 +1,173 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/models"
+)
+
+// TestNDJSONFormatter tests the event sequence and field names of the NDJSON stream
+func TestNDJSONFormatter(t *testing.T) {
+	var buf bytes.Buffer
+	f := NewNDJSONFormatter()
+
+	if err := f.Start(&buf, 0, 0, 4); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	updates := []ProgressUpdate{
+		{Type: "scan_progress", TotalFiles: 3, TotalBytes: 300},
+		// Throttled: less than a second after the previous scan event, written before the next event
+		{Type: "scan_progress", TotalFiles: 4, TotalBytes: 400},
+		{Type: "rate_limit", Limit: LimitOps, RateLimit: 50},
+		{Type: "file_decision", FilePath: "a.txt", Action: models.ActionCopy, Reason: "file exists only in source", TotalBytes: 100},
+		{Type: "file_start", FilePath: "a.txt", TotalBytes: 100},
+		// Byte-level progress is not streamed
+		{Type: "file_progress", FilePath: "a.txt", BytesWritten: 50, TotalBytes: 100},
+		{Type: "file_complete", FilePath: "a.txt", BytesWritten: 100},
+		{Type: "file_decision", FilePath: "b.txt", Action: models.ActionSkip, Reason: "files are identical", TotalBytes: 200},
+		{Type: "file_complete", FilePath: "b.txt"},
+		{Type: "file_error", FilePath: "c.txt", Error: errors.New("permission denied")},
+		{Type: "conflict", FilePath: "d.txt", Conflict: &models.Conflict{
+			Path:          "d.txt",
+			Type:          models.ConflictModifyModify,
+			Resolution:    models.ConflictNewer,
+			Winner:        "source",
+			ConflictFiles: []string{"d.txt.dest-conflict"},
+		}},
+	}
+	for _, update := range updates {
+		if err := f.Progress(update); err != nil {
+			t.Fatalf("Progress(%s) error = %v", update.Type, err)
+		}
+	}
+	report := &models.SyncReport{
+		Status:      models.StatusPartial,
+		Duration:    1500 * time.Millisecond,
+		Differences: []models.FileDifference{{RelativePath: "a.txt", Reason: models.ReasonOnlyInSource}},
+		Errors:      []models.SyncError{{FilePath: "c.txt", Error: "permission denied"}},
+	}
+	if err := f.Complete(report); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	type event struct {
+		Schema    int                        `json:"schema"`
+		Seq       int64                      `json:"seq"`
+		Timestamp time.Time                  `json:"timestamp"`
+		Type      string                     `json:"type"`
+		Data      map[string]json.RawMessage `json:"data"`
+	}
+	var events []event
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var e event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("line %d is not a JSON object: %v\n%s", len(events)+1, err, scanner.Text())
+		}
+		events = append(events, e)
+	}
+
+	// Each event type and the fields of its data object, in emission order
+	want := []struct {
+		eventType string
+		fields    string
+	}{
+		{"start", "max_workers total_bytes total_files"},
+		{"scan_progress", "total_bytes total_files"},
+		{"scan_progress", "total_bytes total_files"},
+		{"rate_limit", "limit ops_per_second"},
+		{"decision", "action path reason size"},
+		{"transfer_start", "bytes path"},
+		{"transfer_complete", "bytes path"},
+		{"decision", "action path reason size"},
+		{"file_complete", "bytes path"},
+		{"error", "error path"},
+		{"conflict", "conflict_files path resolution type winner"},
+		{"complete", "conflicts differences duration duration_ms errors stats status"},
+	}
+	if len(events) != len(want) {
+		types := make([]string, len(events))
+		for i, e := range events {
+			types[i] = e.Type
+		}
+		t.Fatalf("got %d events %v, want %d", len(events), types, len(want))
+	}
+
+	for i, e := range events {
+		if e.Schema != NDJSONSchemaVersion {
+			t.Errorf("event %d schema = %d, want %d", i, e.Schema, NDJSONSchemaVersion)
+		}
+		if e.Seq != int64(i+1) {
+			t.Errorf("event %d seq = %d, want %d", i, e.Seq, i+1)
+		}
+		if e.Timestamp.IsZero() {
+			t.Errorf("event %d has no timestamp", i)
+		}
+		if e.Type != want[i].eventType {
+			t.Errorf("event %d type = %q, want %q", i, e.Type, want[i].eventType)
+			continue
+		}
+		fields := make([]string, 0, len(e.Data))
+		for name := range e.Data {
+			fields = append(fields, name)
+		}
+		sort.Strings(fields)
+		if got := strings.Join(fields, " "); got != want[i].fields {
+			t.Errorf("%s event fields = %q, want %q", e.Type, got, want[i].fields)
+		}
+	}
+
+	if got := string(events[1].Data["total_files"]); got != "3" {
+		t.Errorf("scan_progress total_files = %s, want 3", got)
+	}
+	if got := string(events[2].Data["total_files"]); got != "4" {
+		t.Errorf("flushed scan_progress total_files = %s, want 4", got)
+	}
+	if got := string(events[4].Data["action"]); got != `"copy"` {
+		t.Errorf("decision action = %s, want \"copy\"", got)
+	}
+	if got := string(events[11].Data["status"]); got != `"partial"` {
+		t.Errorf("complete status = %s, want \"partial\"", got)
+	}
+}
+
+// TestNDJSONFormatterFinalScanTotals tests that throttled scan totals reach the stream
+func TestNDJSONFormatterFinalScanTotals(t *testing.T) {
+	var buf bytes.Buffer
+	f := NewNDJSONFormatter()
+	if err := f.Start(&buf, 0, 0, 1); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	f.Progress(ProgressUpdate{Type: "scan_progress", TotalFiles: 1, TotalBytes: 10})
+	f.Progress(ProgressUpdate{Type: "scan_progress", TotalFiles: 2, TotalBytes: 30})
+	if err := f.Complete(&models.SyncReport{Status: models.StatusSuccess}); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	var last NDJSONScanData
+	var scans int
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var e struct {
+			Type string         `json:"type"`
+			Data NDJSONScanData `json:"data"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("invalid event line: %v", err)
+		}
+		if e.Type == "scan_progress" {
+			scans++
+			last = e.Data
+		}
+	}
+	if scans != 2 || last.TotalFiles != 2 || last.TotalBytes != 30 {
+		t.Errorf("got %d scan_progress events, last %+v; want 2, last with 2 files and 30 bytes", scans, last)
+	}
+}
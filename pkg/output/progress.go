@@ -73,6 +73,9 @@ type ProgressFormatter struct {
 
 	// For Windows: track max lines ever displayed to avoid display artifacts
 	maxLinesDisplayed int
+
+	// Effective limits by name (shown once a "rate_limit" update was received)
+	limits map[string]int64
 }
 
 // NewProgressFormatter creates a new progress bar formatter
@@ -229,6 +232,12 @@ func (f *ProgressFormatter) Progress(update ProgressUpdate) error {
 		f.processedFiles++
 		f.processedBytes += update.BytesWritten
 
+	case "rate_limit":
+		if f.limits == nil {
+			f.limits = make(map[string]int64)
+		}
+		f.limits[update.Limit] = update.RateLimit
+
 	case "file_error":
 		if fp, exists := f.activeFiles[update.CurrentFile]; exists {
 			fp.status = "error"
@@ -512,6 +521,8 @@ func (f *ProgressFormatter) renderContentWindows() {
 		dataLine += fmt.Sprintf(" ETA: %s", eta)
 	}
 
+	dataLine += f.limitsLabel()
+
 	content.WriteString(f.padToWidth(dataLine) + "\n")
 	lines++
 
@@ -587,6 +598,28 @@ func (f *ProgressFormatter) visibleLength(s string) int {
 	return len([]rune(s))
 }
 
+// limitShortLabels names limits in the progress display (operation limits show their unit instead)
+var limitShortLabels = map[string]string{
+	LimitBandwidth:  "limit: ",
+	LimitSourceRead: "src read: ",
+	LimitDestRead:   "dst read: ",
+	LimitWrite:      "write: ",
+}
+
+// limitsLabel returns the effective limits appended to the data line, e.g. " [limit: 10.0 MiB/s, 200 ops/s]"
+func (f *ProgressFormatter) limitsLabel() string {
+	var parts []string
+	for _, limit := range limitOrder {
+		if rate, ok := f.limits[limit]; ok {
+			parts = append(parts, limitShortLabels[limit]+formatLimit(limit, rate))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(parts, ", ") + "]"
+}
+
 // truncateLine ensures a line doesn't exceed terminal width
 func (f *ProgressFormatter) truncateLine(line string) string {
 	// Account for ANSI color codes which don't take visual space
@@ -799,6 +832,8 @@ func (f *ProgressFormatter) renderContent() {
 		dataLine += fmt.Sprintf(" ETA: %s", eta)
 	}
 
+	dataLine += f.limitsLabel()
+
 	content.WriteString(f.truncateLine(dataLine) + "\n")
 	lines++
 
@@ -891,15 +926,30 @@ func (f *ProgressFormatter) Complete(report *models.SyncReport) error {
 	fmt.Fprintf(f.writer, "  Operations:\n")
 	fmt.Fprintf(f.writer, "    Files copied:       %d\n", report.Stats.FilesCopied.Load())
 	fmt.Fprintf(f.writer, "    Files updated:      %d\n", report.Stats.FilesUpdated.Load())
+	if metadataUpdated := report.Stats.MetadataUpdated.Load(); metadataUpdated > 0 {
+		fmt.Fprintf(f.writer, "    Metadata updated:   %d\n", metadataUpdated)
+	}
 	fmt.Fprintf(f.writer, "    Files deleted:      %d\n", report.Stats.FilesDeleted.Load())
 	fmt.Fprintf(f.writer, "    Files synchronized: %d\n", report.Stats.FilesSynchronized.Load())
 	fmt.Fprintf(f.writer, "    Files skipped:      %d\n", report.Stats.FilesSkipped.Load())
 	fmt.Fprintf(f.writer, "    Files errored:      %d\n", report.Stats.FilesErrored.Load())
+	if hardLinks := report.Stats.HardLinks.Load(); hardLinks > 0 {
+		fmt.Fprintf(f.writer, "    Hard links:         %d\n", hardLinks)
+	}
+	if fastCopies := report.Stats.FastCopies.Load(); fastCopies > 0 {
+		fmt.Fprintf(f.writer, "    Fast copies:        %d\n", fastCopies)
+	}
 	fmt.Fprintf(f.writer, "    Dirs created:       %d\n", report.Stats.DirsCreated.Load())
+	if dirsUpdated := report.Stats.DirsUpdated.Load(); dirsUpdated > 0 {
+		fmt.Fprintf(f.writer, "    Dirs updated:       %d\n", dirsUpdated)
+	}
 	fmt.Fprintf(f.writer, "    Dirs deleted:       %d\n", report.Stats.DirsDeleted.Load())
 	fmt.Fprintf(f.writer, "\n")
 	fmt.Fprintf(f.writer, "  Transfer:\n")
 	fmt.Fprintf(f.writer, "    Data:           %s\n", formatBytes(report.Stats.BytesTransferred.Load()))
+	if sparseFiles := report.Stats.SparseFiles.Load(); sparseFiles > 0 {
+		fmt.Fprintf(f.writer, "    Allocated:      %s (%d sparse files)\n", formatBytes(report.Stats.BytesAllocated.Load()), sparseFiles)
+	}
 
 	if avgSpeed > 0 {
 		fmt.Fprintf(f.writer, "    Average speed:  %s/s\n", formatBytes(avgSpeed))
@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// opsBurstDivisor bounds bursts to a tenth of a second worth of operations
+const opsBurstDivisor = 10
+
+// OpsLimiter limits the number of operations per second across multiple goroutines
+type OpsLimiter struct {
+	opsPerSecond int64
+	mu           sync.Mutex
+	tokens       float64   // Available operations
+	lastUpdate   time.Time // Last time tokens were updated
+	burst        float64   // Maximum tokens
+}
+
+// NewOpsLimiter creates a new limiter allowing opsPerSecond operations per second
+func NewOpsLimiter(opsPerSecond int64) *OpsLimiter {
+	if opsPerSecond <= 0 {
+		return nil // No limiting
+	}
+
+	burst := float64(opsPerSecond / opsBurstDivisor)
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &OpsLimiter{
+		opsPerSecond: opsPerSecond,
+		tokens:       burst,
+		lastUpdate:   time.Now(),
+		burst:        burst,
+	}
+}
+
+// Rate returns the limit in operations per second
+func (l *OpsLimiter) Rate() int64 {
+	return l.opsPerSecond
+}
+
+// Wait blocks until an operation may start or the context is canceled
+func (l *OpsLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.tokens += now.Sub(l.lastUpdate).Seconds() * float64(l.opsPerSecond)
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+		l.lastUpdate = now
+
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+
+		waitTime := time.Duration((1 - l.tokens) / float64(l.opsPerSecond) * float64(time.Second))
+		if waitTime < time.Millisecond {
+			waitTime = time.Millisecond
+		}
+		l.mu.Unlock()
+
+		timer := time.NewTimer(waitTime)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -14,13 +14,23 @@ type Reader struct {
 	ctx       context.Context
 }
 
+// scheduleCheckInterval is how often a scheduled limiter reevaluates its schedule
+const scheduleCheckInterval = time.Second
+
+// minBucketSize is the smallest burst size, for smooth transfers at low rates
+const minBucketSize = 65536
+
 // Limiter controls the rate of data transfer across multiple readers
 type Limiter struct {
-	bytesPerSecond int64
+	bytesPerSecond int64 // 0 = unlimited (scheduled limiters only)
 	mu             sync.Mutex
 	tokens         int64     // Available tokens (bytes)
 	lastUpdate     time.Time // Last time tokens were updated
 	bucketSize     int64     // Maximum tokens (burst size)
+
+	schedule     *Schedule   // Time-of-day limits (nil = fixed rate)
+	nextCheck    time.Time   // Next time the schedule is evaluated
+	onRateChange func(int64) // Optional callback when the rate changes
 }
 
 // NewLimiter creates a new rate limiter with the specified bytes per second limit
@@ -32,8 +42,8 @@ func NewLimiter(bytesPerSecond int64) *Limiter {
 
 	// Bucket size is 1 second worth of data or 64KB minimum for smooth transfers
 	bucketSize := bytesPerSecond
-	if bucketSize < 65536 {
-		bucketSize = 65536
+	if bucketSize < minBucketSize {
+		bucketSize = minBucketSize
 	}
 
 	return &Limiter{
@@ -44,6 +54,102 @@ func NewLimiter(bytesPerSecond int64) *Limiter {
 	}
 }
 
+// NewScheduledLimiter creates a rate limiter following a time-of-day schedule
+// The schedule is reevaluated every second, so limits change during long transfers
+func NewScheduledLimiter(schedule *Schedule) *Limiter {
+	l := &Limiter{
+		schedule:   schedule,
+		lastUpdate: time.Now(),
+	}
+	l.applySchedule()
+	return l
+}
+
+// Rate returns the current limit in bytes per second (0 = unlimited)
+func (l *Limiter) Rate() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.applySchedule()
+	return l.bytesPerSecond
+}
+
+// SetRate changes the limit in bytes per second (0 = unlimited)
+// Scheduled limiters override the rate at their next schedule evaluation
+func (l *Limiter) SetRate(bytesPerSecond int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.setRate(bytesPerSecond)
+}
+
+// SetOnRateChange sets a callback invoked with the new rate whenever it changes
+// The callback runs with the limiter locked and must not call the limiter
+func (l *Limiter) SetOnRateChange(callback func(bytesPerSecond int64)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onRateChange = callback
+}
+
+// Schedule returns the limiter's schedule (nil for a fixed rate)
+func (l *Limiter) Schedule() *Schedule {
+	return l.schedule
+}
+
+// applySchedule updates the rate from the schedule (must be called with lock held)
+func (l *Limiter) applySchedule() {
+	if l.schedule == nil {
+		return
+	}
+	now := time.Now()
+	if now.Before(l.nextCheck) {
+		return
+	}
+	l.nextCheck = now.Add(scheduleCheckInterval)
+	l.setRate(l.schedule.LimitAt(now))
+}
+
+// setRate changes the rate and resizes the bucket (must be called with lock held)
+func (l *Limiter) setRate(bytesPerSecond int64) {
+	if bytesPerSecond < 0 {
+		bytesPerSecond = 0
+	}
+	if bytesPerSecond == l.bytesPerSecond {
+		return
+	}
+
+	// Credit the time elapsed at the old rate before switching
+	wasUnlimited := l.bytesPerSecond == 0
+	if !wasUnlimited {
+		l.refillTokens()
+	}
+
+	l.bytesPerSecond = bytesPerSecond
+	l.lastUpdate = time.Now()
+	if bytesPerSecond > 0 {
+		l.bucketSize = bytesPerSecond
+		if l.bucketSize < minBucketSize {
+			l.bucketSize = minBucketSize
+		}
+		if wasUnlimited || l.tokens > l.bucketSize {
+			l.tokens = l.bucketSize
+		}
+	}
+
+	if l.onRateChange != nil {
+		l.onRateChange(bytesPerSecond)
+	}
+}
+
+// chunkSize returns how many of n bytes may be read at once (must be called without lock held)
+func (l *Limiter) chunkSize(n int) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.applySchedule()
+	if l.bytesPerSecond > 0 && int64(n) > l.bucketSize {
+		return int(l.bucketSize)
+	}
+	return n
+}
+
 // NewReader wraps an io.Reader with rate limiting
 func NewReader(ctx context.Context, reader io.Reader, limiter *Limiter) io.Reader {
 	if limiter == nil {
@@ -66,10 +172,7 @@ func (r *Reader) Read(p []byte) (int, error) {
 	}
 
 	// Wait for tokens
-	toRead := len(p)
-	if toRead > int(r.limiter.bucketSize) {
-		toRead = int(r.limiter.bucketSize)
-	}
+	toRead := r.limiter.chunkSize(len(p))
 
 	r.limiter.waitForTokens(int64(toRead))
 
@@ -82,12 +185,38 @@ func (r *Reader) Read(p []byte) (int, error) {
 	return n, err
 }
 
+// Take waits until up to n bytes may be transferred and returns how many may be transferred now
+// It paces transfers that don't go through a reader, such as copies made by the kernel
+func (l *Limiter) Take(ctx context.Context, n int) (int, error) {
+	if l == nil {
+		return n, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	n = l.chunkSize(n)
+	l.waitForTokens(int64(n))
+	l.consumeTokens(int64(n))
+	return n, nil
+}
+
 // waitForTokens blocks until enough tokens are available
 func (l *Limiter) waitForTokens(needed int64) {
 	for {
 		l.mu.Lock()
+		l.applySchedule()
+		if l.bytesPerSecond == 0 {
+			l.mu.Unlock()
+			return
+		}
 		l.refillTokens()
 
+		// The rate may have dropped since the read size was chosen
+		if needed > l.bucketSize {
+			needed = l.bucketSize
+		}
+
 		if l.tokens >= needed {
 			l.mu.Unlock()
 			return
@@ -125,6 +254,9 @@ func (l *Limiter) refillTokens() {
 func (l *Limiter) consumeTokens(n int64) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
+	if l.bytesPerSecond == 0 {
+		return
+	}
 	l.tokens -= n
 	if l.tokens < 0 {
 		l.tokens = 0
@@ -0,0 +1,219 @@
+package ratelimit
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rateRegexp matches a rate such as "500K", "10M", "1.5G", "2MB/s"
+var rateRegexp = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([KMGTkmgt])?[Bb]?(?:/[Ss])?$`)
+
+// ParseRate parses a bandwidth string like "10M", "1G", "500K" into bytes per second
+// "unlimited", "off" and "0" return 0 (no limit)
+func ParseRate(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	switch strings.ToLower(s) {
+	case "", "0", "unlimited", "off":
+		return 0, nil
+	}
+
+	matches := rateRegexp.FindStringSubmatch(s)
+	if matches == nil {
+		return 0, fmt.Errorf("invalid bandwidth format: %s (use: 10M, 1G, 500K)", s)
+	}
+
+	value, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid bandwidth value: %s", matches[1])
+	}
+
+	var multiplier int64 = 1
+	switch strings.ToUpper(matches[2]) {
+	case "K":
+		multiplier = 1024
+	case "M":
+		multiplier = 1024 * 1024
+	case "G":
+		multiplier = 1024 * 1024 * 1024
+	case "T":
+		multiplier = 1024 * 1024 * 1024 * 1024
+	}
+
+	return int64(value * float64(multiplier)), nil
+}
+
+// dayNames maps day abbreviations to weekdays
+var dayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// scheduleRule is one entry of a bandwidth schedule
+type scheduleRule struct {
+	days  [7]bool // Indexed by time.Weekday
+	start int     // Minutes after midnight (inclusive)
+	end   int     // Minutes after midnight (exclusive), may be before start to wrap past midnight
+	limit int64   // Bytes per second (0 = unlimited)
+}
+
+// matches reports whether the rule applies at t
+// A time range wrapping past midnight belongs to the day it starts on
+func (r scheduleRule) matches(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+
+	if r.start < r.end {
+		return r.days[day] && minute >= r.start && minute < r.end
+	}
+	if minute >= r.start {
+		return r.days[day]
+	}
+	return minute < r.end && r.days[(day+6)%7]
+}
+
+// Schedule holds bandwidth limits that depend on the day of the week and the time of day
+type Schedule struct {
+	rules []scheduleRule
+	spec  string
+}
+
+// ParseSchedule parses a bandwidth schedule
+// Rules are separated by ";" and the first rule matching the local time applies:
+//
+//	Mon-Fri 08:00-18:00 2M; Sat,Sun 4M; * unlimited
+//
+// Each rule has optional days ("*", "Mon", "Mon-Fri", "Sat,Sun"), an optional time range
+// (may wrap past midnight, "22:00-06:00") and a limit. Times no rule matches are unlimited
+func ParseSchedule(spec string) (*Schedule, error) {
+	s := &Schedule{spec: strings.TrimSpace(spec)}
+
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		rule, err := parseScheduleRule(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bandwidth schedule rule %q: %w", part, err)
+		}
+		s.rules = append(s.rules, rule)
+	}
+
+	if len(s.rules) == 0 {
+		return nil, fmt.Errorf("bandwidth schedule has no rules")
+	}
+	return s, nil
+}
+
+// parseScheduleRule parses "[days] [HH:MM-HH:MM] limit"
+func parseScheduleRule(s string) (scheduleRule, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 || len(fields) > 3 {
+		return scheduleRule{}, fmt.Errorf("expected [days] [HH:MM-HH:MM] limit")
+	}
+
+	rule := scheduleRule{start: 0, end: 24 * 60}
+	for i := range rule.days {
+		rule.days[i] = true
+	}
+
+	limit, err := ParseRate(fields[len(fields)-1])
+	if err != nil {
+		return scheduleRule{}, err
+	}
+	rule.limit = limit
+
+	for _, field := range fields[:len(fields)-1] {
+		switch {
+		case strings.Contains(field, ":"):
+			if rule.start, rule.end, err = parseTimeRange(field); err != nil {
+				return scheduleRule{}, err
+			}
+		case field == "*":
+		default:
+			if rule.days, err = parseDays(field); err != nil {
+				return scheduleRule{}, err
+			}
+		}
+	}
+	return rule, nil
+}
+
+// parseDays parses "Mon", "Mon-Fri", "Fri-Mon" or "Sat,Sun"
+func parseDays(s string) ([7]bool, error) {
+	var days [7]bool
+	for _, part := range strings.Split(strings.ToLower(s), ",") {
+		from, to, isRange := strings.Cut(part, "-")
+		first, ok := dayNames[from]
+		if !ok {
+			return days, fmt.Errorf("unknown day: %s (use Mon, Tue, Wed, Thu, Fri, Sat, Sun)", from)
+		}
+		last := first
+		if isRange {
+			if last, ok = dayNames[to]; !ok {
+				return days, fmt.Errorf("unknown day: %s (use Mon, Tue, Wed, Thu, Fri, Sat, Sun)", to)
+			}
+		}
+		for d := first; ; d = (d + 1) % 7 {
+			days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+// parseTimeRange parses "HH:MM-HH:MM" into minutes after midnight
+func parseTimeRange(s string) (int, int, error) {
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid time range: %s (use HH:MM-HH:MM)", s)
+	}
+	start, err := parseTimeOfDay(from)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := parseTimeOfDay(to)
+	if err != nil {
+		return 0, 0, err
+	}
+	if start == end {
+		return 0, 0, fmt.Errorf("empty time range: %s", s)
+	}
+	return start, end, nil
+}
+
+// parseTimeOfDay parses "HH:MM" (00:00 to 24:00) into minutes after midnight
+func parseTimeOfDay(s string) (int, error) {
+	hours, minutes, ok := strings.Cut(s, ":")
+	h, herr := strconv.Atoi(hours)
+	m, merr := strconv.Atoi(minutes)
+	if !ok || herr != nil || merr != nil || h < 0 || m < 0 || m > 59 || h*60+m > 24*60 {
+		return 0, fmt.Errorf("invalid time: %s (use HH:MM)", s)
+	}
+	return h*60 + m, nil
+}
+
+// LimitAt returns the bandwidth limit in bytes per second at t (0 = unlimited)
+func (s *Schedule) LimitAt(t time.Time) int64 {
+	for _, rule := range s.rules {
+		if rule.matches(t) {
+			return rule.limit
+		}
+	}
+	return 0
+}
+
+// String returns the schedule specification
+func (s *Schedule) String() string {
+	return s.spec
+}
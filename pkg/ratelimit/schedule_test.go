@@ -0,0 +1,154 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+// TestParseRate tests bandwidth string parsing
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{"500K", 500 * 1024, false},
+		{"10M", 10 * 1024 * 1024, false},
+		{"1.5G", 1536 * 1024 * 1024, false},
+		{"2MB/s", 2 * 1024 * 1024, false},
+		{"1000", 1000, false},
+		{"unlimited", 0, false},
+		{"off", 0, false},
+		{"fast", 0, true},
+		{"-1M", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRate(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRate(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseRate(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+// TestScheduleLimitAt tests rule matching by day and time of day
+func TestScheduleLimitAt(t *testing.T) {
+	schedule, err := ParseSchedule("Mon-Fri 08:00-18:00 2M; Fri-Sun 22:00-06:00 unlimited; Sat,Sun 4M; * 10M")
+	if err != nil {
+		t.Fatalf("ParseSchedule() error = %v", err)
+	}
+
+	// 2025-06-02 is a Monday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, 6, day, hour, minute, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want int64
+	}{
+		{"Monday office hours", at(2, 9, 30), 2 * 1024 * 1024},
+		{"Monday office start", at(2, 8, 0), 2 * 1024 * 1024},
+		{"Monday office end", at(2, 18, 0), 10 * 1024 * 1024},
+		{"Monday night", at(2, 23, 0), 10 * 1024 * 1024},
+		{"Friday night", at(6, 23, 0), 0},
+		{"Saturday early morning (Friday night rule)", at(7, 5, 59), 0},
+		{"Saturday afternoon", at(7, 14, 0), 4 * 1024 * 1024},
+		{"Monday early morning (Sunday night rule)", at(9, 3, 0), 0},
+		{"Tuesday early morning", at(3, 3, 0), 10 * 1024 * 1024},
+	}
+
+	for _, tt := range tests {
+		if got := schedule.LimitAt(tt.t); got != tt.want {
+			t.Errorf("%s: LimitAt(%s) = %d, want %d", tt.name, tt.t.Format("Mon 15:04"), got, tt.want)
+		}
+	}
+
+	// Times no rule matches are unlimited
+	office, err := ParseSchedule("Mon-Fri 08:00-18:00 2M")
+	if err != nil {
+		t.Fatalf("ParseSchedule() error = %v", err)
+	}
+	if got := office.LimitAt(at(7, 12, 0)); got != 0 {
+		t.Errorf("LimitAt(Saturday) = %d, want 0 (unlimited)", got)
+	}
+}
+
+// TestParseScheduleErrors tests invalid schedules
+func TestParseScheduleErrors(t *testing.T) {
+	invalid := []string{
+		"",
+		";",
+		"Mon-Fri",
+		"Funday 2M",
+		"Mon-Fri 08:00 2M",
+		"Mon-Fri 08:00-25:00 2M",
+		"Mon-Fri 08:00-08:00 2M",
+		"Mon-Fri 08:00-18:00 fast",
+		"Mon 08:00-18:00 2M extra",
+	}
+
+	for _, spec := range invalid {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("ParseSchedule(%q) should fail", spec)
+		}
+	}
+}
+
+// TestLimiterSetRate tests changing the rate of a running limiter
+func TestLimiterSetRate(t *testing.T) {
+	limiter := NewLimiter(1024 * 1024)
+
+	var changes []int64
+	limiter.SetOnRateChange(func(bytesPerSecond int64) {
+		changes = append(changes, bytesPerSecond)
+	})
+
+	limiter.SetRate(1000)
+	if limiter.Rate() != 1000 || limiter.bucketSize != 65536 {
+		t.Errorf("Rate() = %d, bucketSize = %d; want 1000, 65536", limiter.Rate(), limiter.bucketSize)
+	}
+	if limiter.tokens > limiter.bucketSize {
+		t.Errorf("tokens = %d, want at most bucketSize %d", limiter.tokens, limiter.bucketSize)
+	}
+
+	// Unlimited: reads don't wait
+	limiter.SetRate(0)
+	start := time.Now()
+	limiter.waitForTokens(10 * 1024 * 1024)
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("unlimited wait took %v", elapsed)
+	}
+
+	limiter.SetRate(0) // Unchanged rates are not reported
+	if len(changes) != 2 || changes[0] != 1000 || changes[1] != 0 {
+		t.Errorf("rate changes = %v, want [1000 0]", changes)
+	}
+}
+
+// TestScheduledLimiter tests that a scheduled limiter follows its schedule
+func TestScheduledLimiter(t *testing.T) {
+	schedule, err := ParseSchedule("* 1M")
+	if err != nil {
+		t.Fatalf("ParseSchedule() error = %v", err)
+	}
+
+	limiter := NewScheduledLimiter(schedule)
+	if limiter.Rate() != 1024*1024 {
+		t.Errorf("Rate() = %d, want %d", limiter.Rate(), 1024*1024)
+	}
+
+	// A manual rate lasts until the schedule is evaluated again
+	limiter.SetRate(2048)
+	limiter.mu.Lock()
+	limiter.nextCheck = time.Time{}
+	limiter.mu.Unlock()
+	if limiter.Rate() != 1024*1024 {
+		t.Errorf("Rate() after reevaluation = %d, want %d", limiter.Rate(), 1024*1024)
+	}
+}
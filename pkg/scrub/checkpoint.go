@@ -0,0 +1,136 @@
+package scrub
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Checkpoint records scrubbed files in an append-only file so an interrupted scrub can resume
+// Each line is a JSON encoded Result; a truncated last line (from a crash) is ignored
+type Checkpoint struct {
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+	done map[string]Result
+}
+
+// DefaultCheckpointPath returns the default checkpoint location for a scrubbed root
+func DefaultCheckpointPath(root string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine cache directory: %w", err)
+	}
+	h := fnv.New64a()
+	h.Write([]byte(filepath.Clean(root)))
+	return filepath.Join(cacheDir, "syncnorris", "scrub", fmt.Sprintf("%016x.jsonl", h.Sum64())), nil
+}
+
+// OpenCheckpoint opens the checkpoint at path
+// When resume is false any previous checkpoint is discarded and the scrub starts over
+func OpenCheckpoint(path string, resume bool) (*Checkpoint, error) {
+	c := &Checkpoint{path: path, done: make(map[string]Result)}
+
+	if resume {
+		if err := c.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if !resume {
+		flags |= os.O_TRUNC
+	}
+	f, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint: %w", err)
+	}
+	c.file = f
+
+	return c, nil
+}
+
+// load reads the results of a previous run
+func (c *Checkpoint) load() error {
+	f, err := os.Open(c.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to open checkpoint: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var result Result
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil || result.Path == "" {
+			continue
+		}
+		c.done[result.Path] = result
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Path returns the checkpoint file path
+func (c *Checkpoint) Path() string {
+	return c.path
+}
+
+// Len returns the number of files recorded by previous runs
+func (c *Checkpoint) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.done)
+}
+
+// Done returns the recorded result of a file, if it was already scrubbed
+func (c *Checkpoint) Done(path string) (Result, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result, ok := c.done[path]
+	return result, ok
+}
+
+// Record appends the result of a scrubbed file
+func (c *Checkpoint) Record(result Result) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	c.done[result.Path] = result
+	return nil
+}
+
+// Close closes the checkpoint, keeping it for a later resume
+func (c *Checkpoint) Close() error {
+	return c.file.Close()
+}
+
+// Remove closes and deletes the checkpoint once a scrub has completed
+func (c *Checkpoint) Remove() error {
+	c.file.Close()
+	if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove checkpoint: %w", err)
+	}
+	return nil
+}
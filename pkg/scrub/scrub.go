@@ -0,0 +1,330 @@
+// Package scrub re-hashes files against previously recorded hashes to detect bit rot
+// Hashes can come from a checksum manifest, a hash cache or the sync state
+package scrub
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/sdejongh/syncnorris/internal/parallel"
+	"github.com/sdejongh/syncnorris/pkg/compare"
+	"github.com/sdejongh/syncnorris/pkg/models"
+	"github.com/sdejongh/syncnorris/pkg/ratelimit"
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+// SizeUnknown is the size of records whose size was not recorded
+const SizeUnknown = -1
+
+// Record is the recorded hash of one file version
+type Record struct {
+	Path      string // Relative path with forward slashes
+	Algorithm string
+	Hash      string
+	Size      int64     // SizeUnknown when not recorded
+	ModTime   time.Time // Zero when not recorded
+}
+
+// Status is the outcome of scrubbing one file
+type Status string
+
+const (
+	// StatusOK indicates the content still matches the recorded hash
+	StatusOK Status = "ok"
+	// StatusBitRot indicates the content changed while size and modification time did not
+	StatusBitRot Status = "bit_rot"
+	// StatusMismatch indicates the content changed, but size and modification time weren't
+	// recorded so bit rot cannot be told apart from an edit
+	StatusMismatch Status = "mismatch"
+	// StatusModified indicates the file was modified since its hash was recorded (not checked)
+	StatusModified Status = "modified"
+	// StatusMissing indicates the file no longer exists
+	StatusMissing Status = "missing"
+	// StatusRepaired indicates bit rot was repaired from the replica
+	StatusRepaired Status = "repaired"
+	// StatusError indicates the file could not be read
+	StatusError Status = "error"
+)
+
+// Result is the outcome of scrubbing one file
+type Result struct {
+	Path      string    `json:"path"`
+	Status    Status    `json:"status"`
+	Detail    string    `json:"detail,omitempty"`
+	Algorithm string    `json:"algorithm,omitempty"`
+	Expected  string    `json:"expected,omitempty"` // Recorded hash
+	Actual    string    `json:"actual,omitempty"`   // Hash of the content found (before any repair)
+	Size      int64     `json:"size"`
+	ModTime   time.Time `json:"mod_time,omitempty"`
+}
+
+// Options configures a scrub
+type Options struct {
+	// Workers is the number of files hashed in parallel (default: 1)
+	Workers int
+
+	// Limiter limits the read bandwidth shared by all workers (nil = unlimited)
+	Limiter *ratelimit.Limiter
+
+	// Replica is another copy of the tree used to repair bit rot (nil = report only)
+	// A replica file is only copied back if it still matches the recorded hash
+	Replica storage.Backend
+
+	// Checkpoint records results so an interrupted scrub can resume (nil = not resumable)
+	Checkpoint *Checkpoint
+
+	// Progress is called after each file is scrubbed (optional, may be called concurrently)
+	Progress func(Result)
+}
+
+// Run re-hashes the files of records and reports those that no longer match
+// Bit rot is reported as bit_rot differences, mismatches whose cause is unknown as
+// hash_different, missing files as only_in_source and modified files as skipped.
+// Files already in the checkpoint are not hashed again. Returns the context error
+// if the scrub was interrupted; results recorded so far are kept in the checkpoint
+func Run(ctx context.Context, backend storage.Backend, records []Record, opts Options) (*models.SyncReport, error) {
+	report := &models.SyncReport{
+		StartTime: time.Now(),
+		Mode:      models.ModeOneWay,
+		DryRun:    opts.Replica == nil,
+	}
+
+	sorted := make([]Record, len(records))
+	copy(sorted, records)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+
+	results := make([]Result, len(sorted))
+	err := parallel.ForEach(ctx, len(sorted), opts.Workers, func(i int) error {
+		rec := sorted[i]
+		if opts.Checkpoint != nil {
+			if result, ok := opts.Checkpoint.Done(rec.Path); ok {
+				results[i] = result
+				return nil
+			}
+		}
+
+		result := scrubFile(ctx, backend, rec, opts)
+		if ctx.Err() != nil {
+			// Interrupted files are scrubbed again on resume
+			return ctx.Err()
+		}
+		results[i] = result
+
+		if opts.Checkpoint != nil {
+			if err := opts.Checkpoint.Record(result); err != nil {
+				return err
+			}
+		}
+		if opts.Progress != nil {
+			opts.Progress(result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	buildReport(report, sorted, results)
+	return report, nil
+}
+
+// scrubFile checks one file against its record and repairs bit rot if a replica is configured
+func scrubFile(ctx context.Context, backend storage.Backend, rec Record, opts Options) Result {
+	result := Result{Path: rec.Path, Algorithm: rec.Algorithm, Expected: rec.Hash, Size: rec.Size}
+	path := filepath.FromSlash(rec.Path)
+
+	info, err := backend.Stat(ctx, path)
+	if err != nil {
+		result.Status = StatusMissing
+		result.Detail = "recorded but missing"
+		return result
+	}
+	result.Size = info.Size
+	result.ModTime = info.ModTime
+
+	if rec.Size != SizeUnknown && rec.Size != info.Size {
+		result.Status = StatusModified
+		result.Detail = fmt.Sprintf("size is %d, recorded %d", info.Size, rec.Size)
+		return result
+	}
+	if !rec.ModTime.IsZero() && !rec.ModTime.Equal(info.ModTime) {
+		result.Status = StatusModified
+		result.Detail = fmt.Sprintf("modified %s, recorded %s", info.ModTime.Format(time.RFC3339), rec.ModTime.Format(time.RFC3339))
+		return result
+	}
+
+	actual, err := hashContent(ctx, backend, path, rec.Algorithm, opts.Limiter)
+	if err != nil {
+		result.Status = StatusError
+		result.Detail = err.Error()
+		return result
+	}
+	result.Actual = actual
+
+	switch {
+	case actual == rec.Hash:
+		result.Status = StatusOK
+		return result
+	case rec.Size == SizeUnknown || rec.ModTime.IsZero():
+		result.Status = StatusMismatch
+		result.Detail = fmt.Sprintf("%s hash differs from recorded hash", rec.Algorithm)
+		return result
+	}
+
+	result.Status = StatusBitRot
+	result.Detail = fmt.Sprintf("%s hash differs from recorded hash, size and modification time unchanged", rec.Algorithm)
+	if opts.Replica != nil {
+		if err := repair(ctx, backend, opts.Replica, path, rec, info, opts.Limiter); err != nil {
+			result.Detail += "; repair failed: " + err.Error()
+		} else {
+			result.Status = StatusRepaired
+			result.Detail = "repaired from replica"
+		}
+	}
+	return result
+}
+
+// repair copies a file back from the replica if the replica still matches the recorded hash
+// The damaged file's metadata is kept and the repaired file is hashed again
+func repair(ctx context.Context, backend, replica storage.Backend, path string, rec Record, info *storage.FileInfo, limiter *ratelimit.Limiter) error {
+	replicaHash, err := hashContent(ctx, replica, path, rec.Algorithm, limiter)
+	if err != nil {
+		return fmt.Errorf("failed to read replica: %w", err)
+	}
+	if replicaHash != rec.Hash {
+		return fmt.Errorf("replica does not match the recorded hash either")
+	}
+
+	reader, err := replica.Read(ctx, path)
+	if err != nil {
+		return fmt.Errorf("failed to read replica: %w", err)
+	}
+	defer reader.Close()
+
+	if err := backend.Write(ctx, path, ratelimit.NewReader(ctx, reader, limiter), info.Size, info); err != nil {
+		return fmt.Errorf("failed to write repaired file: %w", err)
+	}
+
+	repaired, err := hashContent(ctx, backend, path, rec.Algorithm, limiter)
+	if err != nil {
+		return fmt.Errorf("failed to read repaired file: %w", err)
+	}
+	if repaired != rec.Hash {
+		return fmt.Errorf("repaired file does not match the recorded hash")
+	}
+	return nil
+}
+
+// hashContent hashes a file read from the backend
+// The backend's hash cache is deliberately bypassed: it would return the recorded hash
+// for a file whose size and modification time didn't change, which is exactly bit rot
+func hashContent(ctx context.Context, backend storage.Backend, path, algorithm string, limiter *ratelimit.Limiter) (string, error) {
+	hasher, err := compare.NewHasher(algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	reader, err := backend.Read(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	if _, err := io.Copy(hasher, &contextReader{ctx: ctx, reader: ratelimit.NewReader(ctx, reader, limiter)}); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", hasher.Sum(nil)), nil
+}
+
+// contextReader stops reading when the context is cancelled
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(p)
+}
+
+// buildReport converts scrub results to a report
+func buildReport(report *models.SyncReport, records []Record, results []Result) {
+	for i, result := range results {
+		rec := records[i]
+		expected := &models.FileInfo{Hash: rec.Hash, HashAlgorithm: rec.Algorithm, ModTime: rec.ModTime}
+		if rec.Size != SizeUnknown {
+			expected.Size = rec.Size
+		}
+		actual := &models.FileInfo{Size: result.Size, ModTime: result.ModTime, Hash: result.Actual}
+		if result.Actual != "" {
+			actual.HashAlgorithm = rec.Algorithm
+		}
+		diff := models.FileDifference{
+			RelativePath: result.Path,
+			Details:      result.Detail,
+			SourceInfo:   expected,
+			DestInfo:     actual,
+		}
+
+		switch result.Status {
+		case StatusOK:
+			report.Stats.FilesSynchronized.Add(1)
+			report.Stats.BytesScanned.Add(result.Size)
+			continue
+
+		case StatusRepaired:
+			report.Stats.FilesUpdated.Add(1)
+			report.Stats.BytesScanned.Add(result.Size)
+			report.Stats.BytesTransferred.Add(result.Size)
+			report.Operations = append(report.Operations, models.FileOperation{
+				Entry:       &models.FileEntry{RelativePath: result.Path, Size: result.Size, ModTime: rec.ModTime},
+				Action:      models.ActionUpdate,
+				Reason:      result.Detail,
+				BytesCopied: result.Size,
+			})
+			continue
+
+		case StatusError:
+			report.Stats.FilesErrored.Add(1)
+			report.Errors = append(report.Errors, models.SyncError{
+				FilePath:  result.Path,
+				Operation: models.ActionSkip,
+				Error:     result.Detail,
+				Timestamp: time.Now(),
+			})
+			continue
+
+		case StatusBitRot:
+			diff.Reason = models.ReasonBitRot
+		case StatusMismatch:
+			diff.Reason = models.ReasonHashDiff
+		case StatusMissing:
+			diff.Reason = models.ReasonOnlyInSource
+			diff.DestInfo = nil
+		case StatusModified:
+			report.Stats.FilesSkipped.Add(1)
+			diff.Reason = models.ReasonSkipped
+		}
+		report.Differences = append(report.Differences, diff)
+	}
+
+	report.Stats.FilesScanned.Store(int32(len(results)))
+	report.EndTime = time.Now()
+	report.Duration = report.EndTime.Sub(report.StartTime)
+	report.Status = models.StatusSuccess
+	if len(report.Errors) > 0 {
+		report.Status = models.StatusPartial
+	}
+	for _, diff := range report.Differences {
+		if diff.Reason != models.ReasonSkipped {
+			report.Status = models.StatusPartial
+			break
+		}
+	}
+}
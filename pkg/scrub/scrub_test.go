@@ -0,0 +1,276 @@
+package scrub
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/compare"
+	"github.com/sdejongh/syncnorris/pkg/hashcache"
+	"github.com/sdejongh/syncnorris/pkg/manifest"
+	"github.com/sdejongh/syncnorris/pkg/models"
+	"github.com/sdejongh/syncnorris/pkg/ratelimit"
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+// newTestTree creates a directory with a few files and returns its backend
+func newTestTree(t *testing.T, files map[string]string) (string, *storage.Local) {
+	t.Helper()
+
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create parent dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+	}
+
+	backend, err := storage.NewLocal(root)
+	if err != nil {
+		t.Fatalf("failed to create backend: %v", err)
+	}
+	return root, backend
+}
+
+// rot overwrites a file with same-size content and restores its modification time
+func rot(t *testing.T, path, content string) {
+	t.Helper()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat %s: %v", path, err)
+	}
+	if int64(len(content)) != info.Size() {
+		t.Fatalf("rotten content must keep the size of %s", path)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+	if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatalf("failed to restore times of %s: %v", path, err)
+	}
+}
+
+// records hashes a tree and returns its scrub records
+func records(t *testing.T, backend storage.Backend) []Record {
+	t.Helper()
+
+	m, err := manifest.Create(context.Background(), backend, compare.AlgorithmXXH128, manifest.Options{})
+	if err != nil {
+		t.Fatalf("manifest.Create() error = %v", err)
+	}
+	return FromManifest(m)
+}
+
+// statuses maps the paths of a report's differences to their reasons
+func statuses(report *models.SyncReport) map[string]models.DifferenceReason {
+	got := make(map[string]models.DifferenceReason)
+	for _, diff := range report.Differences {
+		got[diff.RelativePath] = diff.Reason
+	}
+	return got
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	root, backend := newTestTree(t, map[string]string{
+		"ok.txt":       "intact",
+		"rotten.txt":   "pristine",
+		"edited.txt":   "draft",
+		"gone.txt":     "gone",
+		"sub/deep.txt": "deep",
+	})
+	recs := records(t, backend)
+
+	rot(t, filepath.Join(root, "rotten.txt"), "pristinE")
+	os.WriteFile(filepath.Join(root, "edited.txt"), []byte("final version"), 0644)
+	os.Remove(filepath.Join(root, "gone.txt"))
+
+	report, err := Run(ctx, backend, recs, Options{Workers: 3, Limiter: ratelimit.NewLimiter(1 << 20)})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	want := map[string]models.DifferenceReason{
+		"rotten.txt": models.ReasonBitRot,
+		"edited.txt": models.ReasonSkipped,
+		"gone.txt":   models.ReasonOnlyInSource,
+	}
+	got := statuses(report)
+	if len(got) != len(want) {
+		t.Fatalf("Differences = %+v, want %d", report.Differences, len(want))
+	}
+	for path, reason := range want {
+		if got[path] != reason {
+			t.Errorf("%s: Reason = %s, want %s", path, got[path], reason)
+		}
+	}
+	if n := report.Stats.FilesSynchronized.Load(); n != 2 {
+		t.Errorf("FilesSynchronized = %d, want 2", n)
+	}
+	if report.Status != models.StatusPartial {
+		t.Errorf("Status = %s, want partial", report.Status)
+	}
+}
+
+func TestRunUnknownMetadata(t *testing.T) {
+	root, backend := newTestTree(t, map[string]string{"a.txt": "aaaa"})
+	recs := records(t, backend)
+	recs[0].Size = SizeUnknown
+	recs[0].ModTime = time.Time{}
+
+	rot(t, filepath.Join(root, "a.txt"), "aaab")
+
+	report, err := Run(context.Background(), backend, recs, Options{})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got := statuses(report)["a.txt"]; got != models.ReasonHashDiff {
+		t.Errorf("Reason = %s, want %s", got, models.ReasonHashDiff)
+	}
+}
+
+func TestRunRepair(t *testing.T) {
+	ctx := context.Background()
+	files := map[string]string{
+		"fixable.txt":  "good content",
+		"hopeless.txt": "also good",
+	}
+	root, backend := newTestTree(t, files)
+	replicaRoot, replica := newTestTree(t, files)
+	recs := records(t, backend)
+
+	rot(t, filepath.Join(root, "fixable.txt"), "good cOntent")
+	rot(t, filepath.Join(root, "hopeless.txt"), "also baad")
+	rot(t, filepath.Join(replicaRoot, "hopeless.txt"), "also gooo")
+
+	report, err := Run(ctx, backend, recs, Options{Replica: replica})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if content, _ := os.ReadFile(filepath.Join(root, "fixable.txt")); string(content) != "good content" {
+		t.Errorf("fixable.txt = %q, want repaired content", content)
+	}
+	if n := report.Stats.FilesUpdated.Load(); n != 1 {
+		t.Errorf("FilesUpdated = %d, want 1", n)
+	}
+	got := statuses(report)
+	if len(got) != 1 || got["hopeless.txt"] != models.ReasonBitRot {
+		t.Errorf("Differences = %+v, want hopeless.txt bit rot", report.Differences)
+	}
+	if content, _ := os.ReadFile(filepath.Join(root, "hopeless.txt")); string(content) != "also baad" {
+		t.Errorf("hopeless.txt = %q, must not be overwritten by a damaged replica", content)
+	}
+}
+
+func TestCheckpointResume(t *testing.T) {
+	ctx := context.Background()
+	root, backend := newTestTree(t, map[string]string{
+		"a.txt": "aaaa",
+		"b.txt": "bbbb",
+	})
+	recs := records(t, backend)
+	rot(t, filepath.Join(root, "a.txt"), "aaab")
+	rot(t, filepath.Join(root, "b.txt"), "bbbc")
+
+	path := filepath.Join(t.TempDir(), "scrub", "checkpoint.jsonl")
+	cp, err := OpenCheckpoint(path, false)
+	if err != nil {
+		t.Fatalf("OpenCheckpoint() error = %v", err)
+	}
+	// A previous run already checked a.txt before it rotted
+	cp.Record(Result{Path: "a.txt", Status: StatusOK, Size: 4})
+	cp.Close()
+
+	// Simulate a crash in the middle of a line
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"path":"b.txt","sta`)
+	f.Close()
+
+	cp, err = OpenCheckpoint(path, true)
+	if err != nil {
+		t.Fatalf("OpenCheckpoint(resume) error = %v", err)
+	}
+	if cp.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", cp.Len())
+	}
+
+	report, err := Run(ctx, backend, recs, Options{Checkpoint: cp})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	got := statuses(report)
+	if len(got) != 1 || got["b.txt"] != models.ReasonBitRot {
+		t.Errorf("Differences = %+v, want only b.txt (a.txt resumed as ok)", report.Differences)
+	}
+	if err := cp.Remove(); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("checkpoint should be removed")
+	}
+
+	// Starting over ignores previous results
+	cp, _ = OpenCheckpoint(path, false)
+	cp.Record(Result{Path: "a.txt", Status: StatusOK})
+	cp.Close()
+	cp, _ = OpenCheckpoint(path, false)
+	defer cp.Close()
+	if cp.Len() != 0 {
+		t.Errorf("Len() = %d, want 0 when not resuming", cp.Len())
+	}
+}
+
+func TestRunCancelled(t *testing.T) {
+	_, backend := newTestTree(t, map[string]string{"a.txt": "aaaa"})
+	recs := records(t, backend)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cp, _ := OpenCheckpoint(filepath.Join(t.TempDir(), "checkpoint.jsonl"), false)
+	defer cp.Close()
+	if _, err := Run(ctx, backend, recs, Options{Checkpoint: cp}); err == nil {
+		t.Fatal("Run() should fail when cancelled")
+	}
+	if cp.Len() != 0 {
+		t.Errorf("Len() = %d, interrupted files must not be recorded", cp.Len())
+	}
+}
+
+func TestFromHashCache(t *testing.T) {
+	ctx := context.Background()
+	cache, err := hashcache.OpenDB(filepath.Join(t.TempDir(), "cache.db"), "/data")
+	if err != nil {
+		t.Fatalf("OpenDB() error = %v", err)
+	}
+	defer cache.Close()
+
+	modTime := time.Unix(1700000000, 0)
+	both := hashcache.Key{Path: "both.txt", Size: 4, ModTime: modTime}
+	cache.Store(both, compare.AlgorithmSHA256, "aa")
+	cache.Store(both, compare.AlgorithmXXH3, "bb")
+	cache.Store(hashcache.Key{Path: "sha.txt", Size: 2, ModTime: modTime}, compare.AlgorithmSHA256, "cc")
+
+	recs, err := FromHashCache(ctx, cache, "")
+	if err != nil {
+		t.Fatalf("FromHashCache() error = %v", err)
+	}
+	if len(recs) != 2 || recs[0].Algorithm != compare.AlgorithmXXH3 || recs[1].Algorithm != compare.AlgorithmSHA256 {
+		t.Errorf("records = %+v, want the fastest algorithm of each entry", recs)
+	}
+	if !recs[0].ModTime.Equal(modTime) || recs[0].Size != 4 {
+		t.Errorf("record metadata = %d %s", recs[0].Size, recs[0].ModTime)
+	}
+
+	recs, _ = FromHashCache(ctx, cache, compare.AlgorithmXXH3)
+	if len(recs) != 1 || recs[0].Path != "both.txt" {
+		t.Errorf("records = %+v, want only entries hashed with xxh3", recs)
+	}
+}
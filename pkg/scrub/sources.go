@@ -0,0 +1,82 @@
+package scrub
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/sdejongh/syncnorris/pkg/compare"
+	"github.com/sdejongh/syncnorris/pkg/hashcache"
+	"github.com/sdejongh/syncnorris/pkg/manifest"
+)
+
+// cacheAlgorithms is the order in which hash cache algorithms are preferred, fastest first
+var cacheAlgorithms = []string{
+	compare.AlgorithmXXH3,
+	compare.AlgorithmXXH128,
+	compare.AlgorithmBLAKE3,
+	compare.AlgorithmMD5,
+	compare.AlgorithmSHA256,
+}
+
+// FromManifest returns the records of a checksum manifest
+// Manifests in the sum and BSD formats don't record sizes and modification times, so
+// changed files are reported as mismatches rather than bit rot
+func FromManifest(m *manifest.Manifest) []Record {
+	records := make([]Record, 0, len(m.Entries))
+	for _, e := range m.Entries {
+		size := e.Size
+		if size == manifest.SizeUnknown {
+			size = SizeUnknown
+		}
+		records = append(records, Record{
+			Path:      e.Path,
+			Algorithm: m.Algorithm,
+			Hash:      e.Hash,
+			Size:      size,
+			ModTime:   e.ModTime,
+		})
+	}
+	return records
+}
+
+// FromHashCache returns the records of a hash cache
+// Entries hashed with several algorithms are checked with algorithm, or with the fastest
+// one available when algorithm is empty; entries without that algorithm are left out
+func FromHashCache(ctx context.Context, cache hashcache.Cache, algorithm string) ([]Record, error) {
+	if algorithm != "" {
+		if _, err := compare.NewHasher(algorithm); err != nil {
+			return nil, err
+		}
+	}
+
+	entries, err := cache.Entries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hash cache: %w", err)
+	}
+
+	records := make([]Record, 0, len(entries))
+	for _, e := range entries {
+		algo := algorithm
+		if algo == "" {
+			for _, candidate := range cacheAlgorithms {
+				if _, ok := e.Hashes[candidate]; ok {
+					algo = candidate
+					break
+				}
+			}
+		}
+		hash, ok := e.Hashes[algo]
+		if !ok {
+			continue
+		}
+		records = append(records, Record{
+			Path:      filepath.ToSlash(e.Path),
+			Algorithm: algo,
+			Hash:      hash,
+			Size:      e.Size,
+			ModTime:   e.ModTime,
+		})
+	}
+	return records, nil
+}
@@ -2,8 +2,11 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"io"
 	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/hashcache"
 )
 
 // FileInfo represents metadata about a file
@@ -12,8 +15,17 @@ type FileInfo struct {
 	Size         int64
 	ModTime      time.Time
 	IsDir        bool
-	Permissions  uint32
+	Permissions  uint32 // Permission bits, plus setuid/setgid/sticky when special bits are preserved
 	RelativePath string
+	Inode        uint64 // Zero when the platform does not expose inodes
+	Device       uint64
+	Links        uint64            // Number of hard links to the file, zero when the platform does not expose it
+	IsSymlink    bool              // The entry is a symbolic link (only reported when links are preserved)
+	LinkTarget   string            // Target of the symbolic link, as stored in the link
+	Allocated    int64             // Disk space allocated to the file, zero when the platform does not expose it
+	Sparse       bool              // The file has holes: less space is allocated than its size
+	Owner        *Owner            // nil when ownership is not preserved or not supported
+	Xattrs       map[string][]byte // Preserved extended attributes and ACLs, nil when not read
 }
 
 // Backend defines the interface for storage operations
@@ -41,6 +53,50 @@ type Backend interface {
 	// MkdirAll creates a directory and all necessary parents
 	MkdirAll(ctx context.Context, path string) error
 
+	// Symlink creates a symbolic link pointing to target, replacing any existing file or link
+	Symlink(ctx context.Context, path string, target string) error
+
 	// Close releases any resources held by the backend
 	Close() error
 }
+
+// ErrHardLinksNotSupported is returned when a backend cannot create hard links
+var ErrHardLinksNotSupported = errors.New("backend does not support hard links")
+
+// HardLinker is an optional interface for backends that can create hard links
+type HardLinker interface {
+	// Link creates path as a hard link to the existing file, replacing any file or link at path
+	Link(ctx context.Context, path string, existing string) error
+}
+
+// HashCacher is an optional interface for backends that carry a persistent hash cache
+// Comparators consult the cache before reading file content and fill it in afterwards
+type HashCacher interface {
+	// HashCache returns the backend's hash cache, or nil if caching is disabled
+	HashCache() hashcache.Cache
+}
+
+// CacheKey returns the hash cache key identifying this version of the file
+func (f *FileInfo) CacheKey() hashcache.Key {
+	return hashcache.Key{
+		Path:    f.RelativePath,
+		Size:    f.Size,
+		ModTime: f.ModTime,
+		Inode:   f.Inode,
+		Device:  f.Device,
+	}
+}
+
+// Checksum is a content hash known to a backend without reading the file
+type Checksum struct {
+	Algorithm string
+	Hash      string
+	Size      int64 // -1 when the size is not known
+}
+
+// Checksummer is an optional interface for backends that record file hashes instead of content
+// (e.g., a checksum manifest used as the source side of a comparison)
+type Checksummer interface {
+	// Checksum returns the recorded hash of a file
+	Checksum(path string) (Checksum, bool)
+}
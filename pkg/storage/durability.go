@@ -0,0 +1,184 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Durability selects how writes are flushed to stable storage
+type Durability string
+
+const (
+	// DurabilityNone leaves writing back data to the operating system (default)
+	DurabilityNone Durability = "none"
+
+	// DurabilityFile fsyncs each written file before reporting it written
+	DurabilityFile Durability = "file"
+
+	// DurabilityFull also fsyncs the directories whose entries changed (new files, renames and
+	// deletions), so written files survive a power loss with their names
+	DurabilityFull Durability = "full"
+)
+
+// ParseDurability parses a durability level; an empty string selects DurabilityNone
+func ParseDurability(s string) (Durability, error) {
+	switch d := Durability(s); d {
+	case "":
+		return DurabilityNone, nil
+	case DurabilityNone, DurabilityFile, DurabilityFull:
+		return d, nil
+	default:
+		return "", fmt.Errorf("invalid durability %q (must be none, file or full)", s)
+	}
+}
+
+// syncsFiles reports whether written files are fsynced
+func (d Durability) syncsFiles() bool {
+	return d == DurabilityFile || d == DurabilityFull
+}
+
+// Flusher is an optional interface for backends that defer part of the work making writes durable
+type Flusher interface {
+	// Flush makes the changes written so far durable
+	Flush(ctx context.Context) error
+}
+
+// SyncDir fsyncs a directory, making the creation, renaming and removal of its entries durable
+func SyncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open directory: %w", err)
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("failed to sync directory %s: %w", dir, err)
+	}
+	return nil
+}
+
+// dirtyDirs collects the directories to fsync at the next flush
+// Fsyncing a directory once for all the entries changed in it is what keeps full durability
+// affordable when a sync writes many files
+type dirtyDirs struct {
+	mu   sync.Mutex
+	dirs map[string]struct{}
+}
+
+// add marks dir and its ancestors up to root, since the directories themselves may be new
+func (d *dirtyDirs) add(root, dir string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.dirs == nil {
+		d.dirs = make(map[string]struct{})
+	}
+	for withinRoot(root, dir) {
+		if _, ok := d.dirs[dir]; ok {
+			return
+		}
+		d.dirs[dir] = struct{}{}
+		if dir == root {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// take returns the marked directories, deepest first, and clears them
+func (d *dirtyDirs) take() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	dirs := make([]string, 0, len(d.dirs))
+	for dir := range d.dirs {
+		dirs = append(dirs, dir)
+	}
+	d.dirs = nil
+
+	sort.Slice(dirs, func(i, j int) bool {
+		if di, dj := strings.Count(dirs[i], string(filepath.Separator)), strings.Count(dirs[j], string(filepath.Separator)); di != dj {
+			return di > dj
+		}
+		return dirs[i] < dirs[j]
+	})
+	return dirs
+}
+
+// SetDurability selects how writes are flushed to stable storage (DurabilityNone by default)
+func (l *Local) SetDurability(durability Durability) {
+	l.durability = durability
+}
+
+// Durability returns how writes are flushed to stable storage
+func (l *Local) Durability() Durability {
+	return l.durability
+}
+
+// Flush fsyncs the directories changed since the last flush (full durability)
+// Directories removed since are skipped
+func (l *Local) Flush(ctx context.Context) error {
+	var failures []string
+	for _, dir := range l.dirty.take() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := SyncDir(dir); err != nil && !errors.Is(err, os.ErrNotExist) {
+			failures = append(failures, err.Error())
+		}
+	}
+
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// commitFile fsyncs a written file when the durability level requires it, and records its
+// directory for the next flush
+func (l *Local) commitFile(file *os.File, fullPath string) error {
+	if l.durability.syncsFiles() {
+		if err := file.Sync(); err != nil {
+			return fmt.Errorf("failed to sync file: %w", err)
+		}
+	}
+	l.changed(fullPath)
+	return nil
+}
+
+// changed records that the entry at fullPath was created, replaced or removed
+func (l *Local) changed(fullPath string) {
+	if l.durability == DurabilityFull {
+		l.dirty.add(l.rootPath, filepath.Dir(fullPath))
+	}
+}
+
+// commitMetadata makes metadata applied to an existing entry durable
+func (l *Local) commitMetadata(fullPath string, isDir bool) error {
+	if isDir {
+		if l.durability == DurabilityFull {
+			l.dirty.add(l.rootPath, fullPath)
+		}
+		return nil
+	}
+	if !l.durability.syncsFiles() {
+		return nil
+	}
+
+	file, err := os.Open(fullPath)
+	if err != nil {
+		return fmt.Errorf("failed to sync file: %w", err)
+	}
+	defer file.Close()
+
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDurability(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Durability
+		wantErr bool
+	}{
+		{"", DurabilityNone, false},
+		{"none", DurabilityNone, false},
+		{"file", DurabilityFile, false},
+		{"full", DurabilityFull, false},
+		{"always", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDurability(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseDurability(%q) = %q, %v; want %q, error %v", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestDirtyDirs(t *testing.T) {
+	root := filepath.FromSlash("/root")
+	var dirs dirtyDirs
+
+	dirs.add(root, filepath.Join(root, "a", "b"))
+	dirs.add(root, filepath.Join(root, "a", "b"))
+	dirs.add(root, filepath.Join(root, "c"))
+	dirs.add(root, filepath.Dir(root)) // Outside the root
+
+	want := []string{
+		filepath.Join(root, "a", "b"),
+		filepath.Join(root, "a"),
+		filepath.Join(root, "c"),
+		root,
+	}
+	if got := dirs.take(); !reflect.DeepEqual(got, want) {
+		t.Errorf("take() = %v, want %v", got, want)
+	}
+	if got := dirs.take(); len(got) != 0 {
+		t.Errorf("take() after take() = %v, want none", got)
+	}
+}
+
+func TestLocalDurability(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("none", func(t *testing.T) {
+		local, err := NewLocal(t.TempDir())
+		if err != nil {
+			t.Fatalf("NewLocal() error = %v", err)
+		}
+		if err := local.Write(ctx, "dir/file.txt", strings.NewReader("data"), 4, nil); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		if got := local.dirty.take(); len(got) != 0 {
+			t.Errorf("dirty directories = %v, want none", got)
+		}
+	})
+
+	t.Run("full", func(t *testing.T) {
+		root := t.TempDir()
+		local, err := NewLocal(root)
+		if err != nil {
+			t.Fatalf("NewLocal() error = %v", err)
+		}
+		local.SetDurability(DurabilityFull)
+
+		if err := local.Write(ctx, "dir/sub/file.txt", strings.NewReader("data"), 4, &FileInfo{Permissions: 0600}); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		if err := local.Delete(ctx, "dir/sub/file.txt"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if err := local.Write(ctx, "other.txt", strings.NewReader("data"), 4, nil); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+
+		// Directories removed before the flush are skipped
+		if err := os.RemoveAll(filepath.Join(local.RootPath(), "dir", "sub")); err != nil {
+			t.Fatalf("failed to remove directory: %v", err)
+		}
+
+		// dir/sub, dir and the root
+		local.dirty.mu.Lock()
+		got := len(local.dirty.dirs)
+		local.dirty.mu.Unlock()
+		if got != 3 {
+			t.Errorf("dirty directories = %d, want 3", got)
+		}
+
+		if err := local.Flush(ctx); err != nil {
+			t.Fatalf("Flush() error = %v", err)
+		}
+		if got := local.dirty.take(); len(got) != 0 {
+			t.Errorf("dirty directories after Flush() = %v, want none", got)
+		}
+
+		data, err := os.ReadFile(filepath.Join(root, "other.txt"))
+		if err != nil || string(data) != "data" {
+			t.Errorf("other.txt = %q, %v; want \"data\"", data, err)
+		}
+	})
+}
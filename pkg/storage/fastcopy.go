@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sdejongh/syncnorris/pkg/ratelimit"
+)
+
+// CopyMethod names how a FastCopier copied a file
+type CopyMethod string
+
+const (
+	// CopyReflink shares the source blocks with the copy (btrfs, XFS, ...)
+	CopyReflink CopyMethod = "reflink"
+	// CopyKernel copies the data in the kernel with copy_file_range
+	CopyKernel CopyMethod = "copy_file_range"
+)
+
+// fastCopyChunkSize is the largest amount of data copied by one kernel call
+const fastCopyChunkSize = 8 * 1024 * 1024
+
+// ErrFastCopyNotSupported is returned when a file cannot be copied without streaming it
+var ErrFastCopyNotSupported = errors.New("fast copy not supported")
+
+// FastCopyHooks lets the caller limit and follow a fast copy
+type FastCopyHooks struct {
+	// Pace is called before each kernel copy with the bytes about to be copied, and returns how
+	// many may be copied now (nil = unlimited). Clones are not paced: they transfer no data
+	Pace func(n int) (int, error)
+
+	// Progress receives the number of bytes copied so far (nil = not reported)
+	Progress func(copied int64)
+}
+
+// FastCopier is an optional interface for backends that can copy files without streaming them
+// through user space
+type FastCopier interface {
+	// FastCopy copies sourcePath of source to path, cloning the file when the filesystem supports
+	// it and copying it in the kernel otherwise, then applies metadata like Write
+	// Returns ErrFastCopyNotSupported when neither is possible: the file must be copied normally
+	FastCopy(ctx context.Context, path string, source Backend, sourcePath string, metadata *FileInfo, hooks FastCopyHooks) (CopyMethod, error)
+}
+
+// pace returns how many of n bytes may be copied now
+func (h FastCopyHooks) pace(n int) (int, error) {
+	if h.Pace == nil {
+		return n, nil
+	}
+	return h.Pace(n)
+}
+
+// progress reports the bytes copied so far
+func (h FastCopyHooks) progress(copied int64) {
+	if h.Progress != nil {
+		h.Progress(copied)
+	}
+}
+
+// limit adds a rate limit to the pacing of kernel copies
+func (h FastCopyHooks) limit(ctx context.Context, limiter *ratelimit.Limiter) FastCopyHooks {
+	if limiter == nil {
+		return h
+	}
+	pace := h.Pace
+	h.Pace = func(n int) (int, error) {
+		if pace != nil {
+			var err error
+			if n, err = pace(n); err != nil {
+				return 0, err
+			}
+		}
+		return limiter.Take(ctx, n)
+	}
+	return h
+}
+
+// FastCopy copies a file from another local backend with a reflink clone or copy_file_range
+func (l *Local) FastCopy(ctx context.Context, path string, source Backend, sourcePath string, metadata *FileInfo, hooks FastCopyHooks) (CopyMethod, error) {
+	// A throttled source is copied directly, paced by its read limit
+	if throttled, ok := source.(interface{ unwrap() (Backend, Throttle) }); ok {
+		var throttle Throttle
+		source, throttle = throttled.unwrap()
+		if err := throttle.Ops.Wait(ctx); err != nil {
+			return "", err
+		}
+		hooks = hooks.limit(ctx, throttle.Read)
+	}
+
+	src, ok := source.(*Local)
+	if !ok {
+		return "", ErrFastCopyNotSupported
+	}
+
+	srcPath := filepath.Join(src.rootPath, sourcePath)
+	if err := src.checkRead(srcPath); err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	in, err := os.Open(srcPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return "", fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	fullPath := filepath.Join(l.rootPath, path)
+	out, err := createFile(fullPath)
+	if err != nil {
+		return "", err
+	}
+	l.forgetHash(path)
+	method, err := copyFast(ctx, out, in, info.Size(), hooks)
+	if err == nil {
+		err = l.finishFile(out, fullPath, path, metadata)
+	}
+	if closeErr := out.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close file: %w", closeErr)
+	}
+	if err != nil {
+		var softErr *MetadataError
+		if errors.As(err, &softErr) {
+			return method, err
+		}
+		return "", err
+	}
+	return method, nil
+}
@@ -0,0 +1,53 @@
+//go:build linux
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// copyFast clones src into dst, or copies it with copy_file_range when cloning is not supported
+func copyFast(ctx context.Context, dst, src *os.File, size int64, hooks FastCopyHooks) (CopyMethod, error) {
+	if err := unix.IoctlFileClone(int(dst.Fd()), int(src.Fd())); err == nil {
+		hooks.progress(size)
+		return CopyReflink, nil
+	}
+
+	var copied int64
+	for copied < size {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		n, err := hooks.pace(int(min(size-copied, fastCopyChunkSize)))
+		if err != nil {
+			return "", err
+		}
+		written, err := unix.CopyFileRange(int(src.Fd()), nil, int(dst.Fd()), nil, n, 0)
+		if err != nil {
+			if copied == 0 && fastCopyUnsupported(err) {
+				return "", ErrFastCopyNotSupported
+			}
+			return "", fmt.Errorf("failed to copy file: %w", err)
+		}
+		if written == 0 {
+			return "", fmt.Errorf("incomplete copy: expected %d bytes, copied %d", size, copied)
+		}
+
+		copied += int64(written)
+		hooks.progress(copied)
+	}
+
+	return CopyKernel, nil
+}
+
+// fastCopyUnsupported reports whether copy_file_range cannot copy between the files
+func fastCopyUnsupported(err error) bool {
+	return errors.Is(err, unix.EXDEV) || errors.Is(err, unix.ENOSYS) || errors.Is(err, unix.EOPNOTSUPP) ||
+		errors.Is(err, unix.EINVAL) || errors.Is(err, unix.EBADF)
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package storage
+
+import (
+	"context"
+	"os"
+)
+
+// copyFast is not supported outside Linux: files are streamed
+func copyFast(ctx context.Context, dst, src *os.File, size int64, hooks FastCopyHooks) (CopyMethod, error) {
+	return "", ErrFastCopyNotSupported
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalFastCopy(t *testing.T) {
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+	src, err := NewLocal(srcDir)
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+	dst, err := NewLocal(dstDir)
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+
+	content := bytes.Repeat([]byte("syncnorris"), 100000)
+	if err := os.WriteFile(filepath.Join(srcDir, "file.bin"), content, 0640); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	ctx := context.Background()
+	info, err := src.Stat(ctx, "file.bin")
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+
+	var progress int64
+	hooks := FastCopyHooks{Progress: func(copied int64) { progress = copied }}
+	method, err := dst.FastCopy(ctx, "sub/file.bin", src, "file.bin", info, hooks)
+	if errors.Is(err, ErrFastCopyNotSupported) {
+		t.Skip("fast copy not supported on this filesystem")
+	}
+	if err != nil {
+		t.Fatalf("FastCopy() error = %v", err)
+	}
+	if method != CopyReflink && method != CopyKernel {
+		t.Errorf("FastCopy() method = %q", method)
+	}
+	if progress != int64(len(content)) {
+		t.Errorf("progress = %d, want %d", progress, len(content))
+	}
+
+	got, err := os.ReadFile(filepath.Join(dstDir, "sub", "file.bin"))
+	if err != nil {
+		t.Fatalf("failed to read copy: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Error("copy content differs from the source")
+	}
+
+	copied, err := os.Stat(filepath.Join(dstDir, "sub", "file.bin"))
+	if err != nil {
+		t.Fatalf("failed to stat copy: %v", err)
+	}
+	if copied.Mode().Perm() != 0640 || !copied.ModTime().Equal(info.ModTime) {
+		t.Errorf("copy mode = %v, mtime = %v; want 0640, %v", copied.Mode().Perm(), copied.ModTime(), info.ModTime)
+	}
+}
@@ -0,0 +1,25 @@
+//go:build !unix
+
+package storage
+
+import "os"
+
+// fileID returns zero identifiers on platforms without inode numbers
+func fileID(info os.FileInfo) (inode, device uint64) {
+	return 0, 0
+}
+
+// fileOwner reports no ownership on platforms without owner IDs
+func fileOwner(info os.FileInfo) (uid, gid uint32, ok bool) {
+	return 0, 0, false
+}
+
+// allocatedSize reports no allocation on platforms without block counts
+func allocatedSize(info os.FileInfo) (int64, bool) {
+	return 0, false
+}
+
+// linkCount returns zero on platforms without inode numbers (hard links are not detected)
+func linkCount(info os.FileInfo) uint64 {
+	return 0
+}
@@ -0,0 +1,40 @@
+//go:build unix
+
+package storage
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileID returns the inode and device numbers of a file
+func fileID(info os.FileInfo) (inode, device uint64) {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(st.Ino), uint64(st.Dev)
+	}
+	return 0, 0
+}
+
+// fileOwner returns the owner and group IDs of a file
+func fileOwner(info os.FileInfo) (uid, gid uint32, ok bool) {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return st.Uid, st.Gid, true
+	}
+	return 0, 0, false
+}
+
+// allocatedSize returns the disk space allocated to a file
+func allocatedSize(info os.FileInfo) (int64, bool) {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return int64(st.Blocks) * 512, true
+	}
+	return 0, false
+}
+
+// linkCount returns the number of hard links to a file
+func linkCount(info os.FileInfo) uint64 {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(st.Nlink)
+	}
+	return 0
+}
@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LinkMode selects how a backend handles symbolic links
+type LinkMode string
+
+const (
+	// LinkPreserve reports links as links so they are recreated rather than copied (default)
+	LinkPreserve LinkMode = "preserve"
+
+	// LinkFollow follows links and reports their targets as regular files and directories
+	LinkFollow LinkMode = "follow"
+
+	// LinkSkip ignores links entirely
+	LinkSkip LinkMode = "skip"
+
+	// LinkSafeFollow follows links whose target stays within the root and ignores the others
+	LinkSafeFollow LinkMode = "safe-follow"
+)
+
+// ParseLinkMode parses a symbolic link mode; an empty string selects LinkPreserve
+func ParseLinkMode(s string) (LinkMode, error) {
+	switch mode := LinkMode(s); mode {
+	case "":
+		return LinkPreserve, nil
+	case LinkPreserve, LinkFollow, LinkSkip, LinkSafeFollow:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid link mode %q (must be preserve, follow, skip or safe-follow)", s)
+	}
+}
+
+// follows reports whether the mode follows links instead of reporting them
+func (m LinkMode) follows() bool {
+	return m == LinkFollow || m == LinkSafeFollow
+}
+
+// isSymlink reports whether file info describes a symbolic link
+func isSymlink(info os.FileInfo) bool {
+	return info.Mode()&fs.ModeSymlink != 0
+}
+
+// withinRoot reports whether path is root or one of its descendants
+func withinRoot(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// checkFollow verifies that a path may be accessed through the links it contains
+// In safe-follow mode, a path resolving outside the root is refused
+func (l *Local) checkFollow(fullPath string) error {
+	if l.linkMode != LinkSafeFollow {
+		return nil
+	}
+	resolved, err := filepath.EvalSymlinks(fullPath)
+	if err != nil {
+		return err
+	}
+	if !withinRoot(l.realRoot, resolved) {
+		return fmt.Errorf("symbolic link points outside the root: %s", fullPath)
+	}
+	return nil
+}
+
+// listFollow lists a directory recursively, following symbolic links
+func (l *Local) listFollow(ctx context.Context, fullPath string) ([]FileInfo, error) {
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	var files []FileInfo
+	if err := l.walkFollow(ctx, fullPath, info, make(map[string]bool), &files); err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	return files, nil
+}
+
+// walkFollow adds an entry and, for a directory, its content to files
+// ancestors holds the resolved paths of the directories being walked: a link leading
+// back to one of them would loop forever, so it is skipped
+func (l *Local) walkFollow(ctx context.Context, p string, info os.FileInfo, ancestors map[string]bool, files *[]FileInfo) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	relPath, err := filepath.Rel(l.rootPath, p)
+	if err != nil {
+		return nil
+	}
+
+	if !info.IsDir() {
+		*files = append(*files, l.fileInfo(p, relPath, info))
+		return nil
+	}
+
+	resolved, err := filepath.EvalSymlinks(p)
+	if err != nil {
+		return nil
+	}
+	if ancestors[resolved] {
+		// Link loop: the directory is already being walked
+		return nil
+	}
+	*files = append(*files, l.fileInfo(p, relPath, info))
+
+	entries, err := os.ReadDir(p)
+	if err != nil {
+		// Skip inaccessible directories rather than aborting
+		return nil
+	}
+
+	ancestors[resolved] = true
+	defer delete(ancestors, resolved)
+
+	for _, entry := range entries {
+		child := filepath.Join(p, entry.Name())
+		if entry.Type()&fs.ModeSymlink != 0 && l.checkFollow(child) != nil {
+			continue
+		}
+
+		childInfo, err := os.Stat(child)
+		if err != nil {
+			// Broken link or inaccessible entry
+			continue
+		}
+		if err := l.walkFollow(ctx, child, childInfo, ancestors, files); err != nil {
+			return err
+		}
+	}
+	return nil
+}
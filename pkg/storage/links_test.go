@@ -0,0 +1,212 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// newLinkTree creates a root holding regular files and links of every kind:
+// a link within the root, a link escaping it, a broken link and a directory loop
+func newLinkTree(t *testing.T) string {
+	t.Helper()
+
+	base := t.TempDir()
+	root := filepath.Join(base, "root")
+	outside := filepath.Join(base, "outside.txt")
+
+	if err := os.MkdirAll(filepath.Join(root, "sub"), 0755); err != nil {
+		t.Fatalf("failed to create dirs: %v", err)
+	}
+	for path, content := range map[string]string{
+		filepath.Join(root, "a.txt"):        "a",
+		filepath.Join(root, "sub", "b.txt"): "b",
+		outside:                             "secret",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+	}
+
+	links := map[string]string{
+		filepath.Join(root, "in"):          "a.txt",
+		filepath.Join(root, "out"):         outside,
+		filepath.Join(root, "broken"):      "missing.txt",
+		filepath.Join(root, "sub", "loop"): "..",
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, link); err != nil {
+			t.Skipf("Symlinks not supported on this system: %v", err)
+		}
+	}
+
+	return root
+}
+
+// listNames lists a backend and returns the relative paths, links suffixed with "@"
+func listNames(t *testing.T, local *Local) []string {
+	t.Helper()
+
+	files, err := local.List(context.Background(), "")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	var names []string
+	for _, f := range files {
+		name := filepath.ToSlash(f.RelativePath)
+		if f.IsSymlink {
+			name += "@"
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestParseLinkMode(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    LinkMode
+		wantErr bool
+	}{
+		{"", LinkPreserve, false},
+		{"preserve", LinkPreserve, false},
+		{"follow", LinkFollow, false},
+		{"skip", LinkSkip, false},
+		{"safe-follow", LinkSafeFollow, false},
+		{"copy", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLinkMode(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLinkMode(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLinkMode(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestLocalLinkModes(t *testing.T) {
+	root := newLinkTree(t)
+
+	tests := []struct {
+		mode LinkMode
+		want []string
+	}{
+		{LinkPreserve, []string{".", "a.txt", "broken@", "in@", "out@", "sub", "sub/b.txt", "sub/loop@"}},
+		{LinkSkip, []string{".", "a.txt", "sub", "sub/b.txt"}},
+		{LinkFollow, []string{".", "a.txt", "in", "out", "sub", "sub/b.txt"}},
+		{LinkSafeFollow, []string{".", "a.txt", "in", "sub", "sub/b.txt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			local, err := NewLocal(root)
+			if err != nil {
+				t.Fatalf("NewLocal() error = %v", err)
+			}
+			local.SetLinkMode(tt.mode)
+
+			got := listNames(t, local)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("List() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalPreservedLinks(t *testing.T) {
+	root := newLinkTree(t)
+	local, err := NewLocal(root)
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+	ctx := context.Background()
+
+	info, err := local.Stat(ctx, "in")
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if !info.IsSymlink || info.LinkTarget != "a.txt" {
+		t.Errorf("Stat() = symlink %v target %q, want symlink to a.txt", info.IsSymlink, info.LinkTarget)
+	}
+
+	if exists, err := local.Exists(ctx, "broken"); err != nil || !exists {
+		t.Errorf("Exists(broken) = %v, %v; want true (the link itself exists)", exists, err)
+	}
+
+	if _, err := local.Read(ctx, "out"); err == nil {
+		t.Error("Read() should refuse to follow a preserved link")
+	}
+}
+
+func TestLocalSafeFollowRefusesEscapes(t *testing.T) {
+	root := newLinkTree(t)
+	local, err := NewLocal(root)
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+	local.SetLinkMode(LinkSafeFollow)
+	ctx := context.Background()
+
+	if _, err := local.Read(ctx, "out"); err == nil {
+		t.Error("Read() should refuse a link pointing outside the root")
+	}
+	if _, err := local.Stat(ctx, "out"); err == nil {
+		t.Error("Stat() should refuse a link pointing outside the root")
+	}
+
+	reader, err := local.Read(ctx, "in")
+	if err != nil {
+		t.Fatalf("Read() of a link within the root error = %v", err)
+	}
+	reader.Close()
+}
+
+func TestLocalSymlink(t *testing.T) {
+	root := t.TempDir()
+	local, err := NewLocal(root)
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+	ctx := context.Background()
+
+	if err := os.WriteFile(filepath.Join(root, "target.txt"), []byte("target"), 0644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "link"), []byte("regular"), 0644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	// An existing file is replaced by the link
+	if err := local.Symlink(ctx, "link", "target.txt"); err != nil {
+		t.Skipf("Symlinks not supported on this system: %v", err)
+	}
+	if target, err := os.Readlink(filepath.Join(root, "link")); err != nil || target != "target.txt" {
+		t.Errorf("Readlink() = %q, %v; want target.txt", target, err)
+	}
+
+	// Parent directories are created
+	if err := local.Symlink(ctx, "nested/dir/link", "../../target.txt"); err != nil {
+		t.Fatalf("Symlink() error = %v", err)
+	}
+
+	// Writing over a link replaces it instead of writing through it
+	content := "new content"
+	if err := local.Write(ctx, "link", strings.NewReader(content), int64(len(content)), nil); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "target.txt")); string(data) != "target" {
+		t.Errorf("link target content = %q, want it unchanged", data)
+	}
+	if info, err := os.Lstat(filepath.Join(root, "link")); err != nil || info.Mode()&os.ModeSymlink != 0 {
+		t.Error("Write() should replace the link with a regular file")
+	}
+}
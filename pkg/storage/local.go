@@ -2,16 +2,27 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+
+	"github.com/sdejongh/syncnorris/pkg/hashcache"
 )
 
 // Local is a filesystem-based storage backend
 type Local struct {
-	rootPath string
+	rootPath  string
+	realRoot  string // rootPath with symbolic links resolved
+	linkMode  LinkMode
+	metadata  MetadataOptions
+	ids       *idNames
+	hashCache hashcache.Cache
+
+	durability Durability
+	dirty      dirtyDirs // Directories to fsync at the next flush (full durability)
 }
 
 // NewLocal creates a new local filesystem backend
@@ -30,13 +41,38 @@ func NewLocal(rootPath string) (*Local, error) {
 		return nil, fmt.Errorf("path is not a directory: %s", absPath)
 	}
 
-	return &Local{rootPath: absPath}, nil
+	realRoot, err := filepath.EvalSymlinks(absPath)
+	if err != nil {
+		realRoot = absPath
+	}
+
+	return &Local{rootPath: absPath, realRoot: realRoot, linkMode: LinkPreserve, ids: newIDNames(), durability: DurabilityNone}, nil
+}
+
+// SetLinkMode selects how symbolic links are handled (LinkPreserve by default)
+func (l *Local) SetLinkMode(mode LinkMode) {
+	l.linkMode = mode
+}
+
+// SetMetadataOptions selects the metadata preserved besides modification times and permissions
+func (l *Local) SetMetadataOptions(opts MetadataOptions) {
+	l.metadata = opts
+}
+
+// LinkMode returns how symbolic links are handled
+func (l *Local) LinkMode() LinkMode {
+	return l.linkMode
 }
 
 // List returns all files in the directory recursively
 // Continues on permission errors, skipping inaccessible files/directories
+// Symbolic links are reported, followed or skipped depending on the link mode
 func (l *Local) List(ctx context.Context, path string) ([]FileInfo, error) {
 	fullPath := filepath.Join(l.rootPath, path)
+	if l.linkMode.follows() {
+		return l.listFollow(ctx, fullPath)
+	}
+
 	var files []FileInfo
 
 	err := filepath.WalkDir(fullPath, func(p string, d fs.DirEntry, err error) error {
@@ -71,14 +107,11 @@ func (l *Local) List(ctx context.Context, path string) ([]FileInfo, error) {
 			return nil
 		}
 
-		files = append(files, FileInfo{
-			Path:         p,
-			Size:         info.Size(),
-			ModTime:      info.ModTime(),
-			IsDir:        info.IsDir(),
-			Permissions:  uint32(info.Mode().Perm()),
-			RelativePath: relPath,
-		})
+		if isSymlink(info) && l.linkMode == LinkSkip {
+			return nil
+		}
+
+		files = append(files, l.fileInfo(p, relPath, info))
 
 		return nil
 	})
@@ -94,11 +127,22 @@ func (l *Local) List(ctx context.Context, path string) ([]FileInfo, error) {
 func (l *Local) Read(ctx context.Context, path string) (io.ReadCloser, error) {
 	fullPath := filepath.Join(l.rootPath, path)
 
+	if err := l.checkRead(fullPath); err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
 	file, err := os.Open(fullPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 
+	// Holes of sparse files are returned as zeros without reading them
+	if info, err := file.Stat(); err == nil {
+		if allocated, ok := allocatedSize(info); ok && allocated < info.Size() {
+			return openSparse(file, info.Size()), nil
+		}
+	}
+
 	return file, nil
 }
 
@@ -106,44 +150,125 @@ func (l *Local) Read(ctx context.Context, path string) (io.ReadCloser, error) {
 func (l *Local) Write(ctx context.Context, path string, reader io.Reader, size int64, metadata *FileInfo) error {
 	fullPath := filepath.Join(l.rootPath, path)
 
+	file, err := createFile(fullPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	l.forgetHash(path)
+
+	// Holes of sparse files are recreated; large files are preallocated to reduce fragmentation
+	var written int64
+	if metadata != nil && metadata.Sparse {
+		written, err = writeSparse(file, reader)
+	} else {
+		if size >= PreallocateThreshold {
+			preallocate(file, size)
+		}
+		written, err = io.Copy(file, reader)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if written != size {
+		return fmt.Errorf("incomplete write: expected %d bytes, wrote %d", size, written)
+	}
+
+	return l.finishFile(file, fullPath, path, metadata)
+}
+
+// finishFile preserves the metadata of a written file, then makes it durable
+// The file is synced last so that its metadata is flushed with its content
+func (l *Local) finishFile(file *os.File, fullPath, path string, metadata *FileInfo) error {
+	var metadataErr error
+	if metadata != nil {
+		metadataErr = l.setMetadata(fullPath, path, metadata)
+		var softErr *MetadataError
+		if metadataErr != nil && !errors.As(metadataErr, &softErr) {
+			return metadataErr
+		}
+	}
+
+	if err := l.commitFile(file, fullPath); err != nil {
+		return err
+	}
+	return metadataErr
+}
+
+// createFile creates or truncates a file, creating its parent directory
+// Symbolic and hard links are replaced rather than written through to the other paths
+func createFile(fullPath string) (*os.File, error) {
 	// Ensure parent directory exists
 	dir := filepath.Dir(fullPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	if info, err := os.Lstat(fullPath); err == nil && (isSymlink(info) || linkCount(info) > 1) {
+		if err := os.Remove(fullPath); err != nil {
+			return nil, fmt.Errorf("failed to replace link: %w", err)
+		}
 	}
 
 	file, err := os.Create(fullPath)
 	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+		return nil, fmt.Errorf("failed to create file: %w", err)
 	}
-	defer file.Close()
+	return file, nil
+}
 
-	written, err := io.Copy(file, reader)
+// SetMetadata applies permissions, modification time and the preserved metadata to an existing file
+// Only the ownership of symbolic links is updated
+func (l *Local) SetMetadata(ctx context.Context, path string, metadata *FileInfo) error {
+	fullPath := filepath.Join(l.rootPath, path)
+
+	info, err := os.Lstat(fullPath)
 	if err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+		return fmt.Errorf("failed to stat file: %w", err)
+	}
+	if isSymlink(info) {
+		if err := l.applyMetadata(fullPath, &FileInfo{Owner: metadata.Owner, IsSymlink: true}); err != nil {
+			return &MetadataError{Path: path, Err: err}
+		}
+		return nil
 	}
 
-	if written != size {
-		return fmt.Errorf("incomplete write: expected %d bytes, wrote %d", size, written)
+	err = l.setMetadata(fullPath, path, metadata)
+	var softErr *MetadataError
+	if err != nil && !errors.As(err, &softErr) {
+		return err
+	}
+	if commitErr := l.commitMetadata(fullPath, info.IsDir()); commitErr != nil {
+		return commitErr
 	}
+	return err
+}
 
-	// Preserve metadata if provided
-	if metadata != nil {
-		// Preserve modification time
-		if !metadata.ModTime.IsZero() {
-			if err := os.Chtimes(fullPath, metadata.ModTime, metadata.ModTime); err != nil {
-				return fmt.Errorf("failed to set modification time: %w", err)
-			}
+// setMetadata applies metadata to a regular file
+// Ownership is set before permissions, since changing owners clears the setuid and setgid bits,
+// and the modification time last; ownership and extended attribute failures are returned as a
+// MetadataError once the other metadata is applied
+func (l *Local) setMetadata(fullPath, path string, metadata *FileInfo) error {
+	extendedErr := l.applyMetadata(fullPath, metadata)
+
+	// Preserve permissions
+	if metadata.Permissions != 0 {
+		if err := os.Chmod(fullPath, os.FileMode(metadata.Permissions)); err != nil {
+			return fmt.Errorf("failed to set permissions: %w", err)
 		}
+	}
 
-		// Preserve permissions
-		if metadata.Permissions != 0 {
-			if err := os.Chmod(fullPath, os.FileMode(metadata.Permissions)); err != nil {
-				return fmt.Errorf("failed to set permissions: %w", err)
-			}
+	// Preserve modification time
+	if !metadata.ModTime.IsZero() {
+		if err := os.Chtimes(fullPath, metadata.ModTime, metadata.ModTime); err != nil {
+			return fmt.Errorf("failed to set modification time: %w", err)
 		}
 	}
 
+	if extendedErr != nil {
+		return &MetadataError{Path: path, Err: extendedErr}
+	}
 	return nil
 }
 
@@ -155,6 +280,7 @@ func (l *Local) Delete(ctx context.Context, path string) error {
 	if err != nil {
 		return fmt.Errorf("failed to delete: %w", err)
 	}
+	l.changed(fullPath)
 
 	return nil
 }
@@ -163,7 +289,7 @@ func (l *Local) Delete(ctx context.Context, path string) error {
 func (l *Local) Exists(ctx context.Context, path string) (bool, error) {
 	fullPath := filepath.Join(l.rootPath, path)
 
-	_, err := os.Stat(fullPath)
+	_, err := l.stat(fullPath)
 	if err == nil {
 		return true, nil
 	}
@@ -177,7 +303,7 @@ func (l *Local) Exists(ctx context.Context, path string) (bool, error) {
 func (l *Local) Stat(ctx context.Context, path string) (*FileInfo, error) {
 	fullPath := filepath.Join(l.rootPath, path)
 
-	info, err := os.Stat(fullPath)
+	info, err := l.stat(fullPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to stat file: %w", err)
 	}
@@ -187,14 +313,8 @@ func (l *Local) Stat(ctx context.Context, path string) (*FileInfo, error) {
 		return nil, err
 	}
 
-	return &FileInfo{
-		Path:         fullPath,
-		Size:         info.Size(),
-		ModTime:      info.ModTime(),
-		IsDir:        info.IsDir(),
-		Permissions:  uint32(info.Mode().Perm()),
-		RelativePath: relPath,
-	}, nil
+	fileInfo := l.fileInfo(fullPath, relPath, info)
+	return &fileInfo, nil
 }
 
 // MkdirAll creates a directory and all necessary parents
@@ -205,10 +325,146 @@ func (l *Local) MkdirAll(ctx context.Context, path string) error {
 	if err != nil {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
+	l.changed(fullPath)
+
+	return nil
+}
+
+// Symlink creates a symbolic link pointing to target, replacing any existing file or link
+func (l *Local) Symlink(ctx context.Context, path string, target string) error {
+	fullPath := filepath.Join(l.rootPath, path)
+
+	// Ensure parent directory exists
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	if info, err := os.Lstat(fullPath); err == nil {
+		if info.IsDir() {
+			return fmt.Errorf("failed to create symbolic link: %s is a directory", path)
+		}
+		if err := os.Remove(fullPath); err != nil {
+			return fmt.Errorf("failed to replace file: %w", err)
+		}
+	}
+
+	if err := os.Symlink(target, fullPath); err != nil {
+		return fmt.Errorf("failed to create symbolic link: %w", err)
+	}
+	l.changed(fullPath)
+
+	return nil
+}
+
+// Link creates path as a hard link to the existing file, replacing any file or link at path
+// The link is created under a temporary name first, so a failure leaves path untouched
+func (l *Local) Link(ctx context.Context, path string, existing string) error {
+	fullPath := filepath.Join(l.rootPath, path)
+	existingPath := filepath.Join(l.rootPath, existing)
+
+	// Ensure parent directory exists
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	if info, err := os.Lstat(fullPath); err == nil && info.IsDir() {
+		return fmt.Errorf("failed to create hard link: %s is a directory", path)
+	}
+
+	tmpPath := fullPath + ".syncnorris-link"
+	os.Remove(tmpPath)
+	if err := os.Link(existingPath, tmpPath); err != nil {
+		return fmt.Errorf("failed to create hard link: %w", err)
+	}
+	if err := os.Rename(tmpPath, fullPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace file: %w", err)
+	}
+	// Renaming onto another link of the same file does nothing, leaving the temporary link
+	os.Remove(tmpPath)
+	l.changed(fullPath)
+	l.forgetHash(path)
 
 	return nil
 }
 
+// stat returns the info of a file, following symbolic links only when the link mode does
+func (l *Local) stat(fullPath string) (os.FileInfo, error) {
+	if !l.linkMode.follows() && fullPath != l.rootPath {
+		return os.Lstat(fullPath)
+	}
+	if err := l.checkFollow(fullPath); err != nil {
+		return nil, err
+	}
+	return os.Stat(fullPath)
+}
+
+// checkRead verifies that a file's content may be read with the link mode
+func (l *Local) checkRead(fullPath string) error {
+	if l.linkMode.follows() {
+		return l.checkFollow(fullPath)
+	}
+	info, err := os.Lstat(fullPath)
+	if err != nil {
+		return err
+	}
+	if isSymlink(info) {
+		return fmt.Errorf("%s is a symbolic link", fullPath)
+	}
+	return nil
+}
+
+// fileInfo converts file info to the backend's file metadata, reading link targets
+func (l *Local) fileInfo(fullPath, relPath string, info os.FileInfo) FileInfo {
+	inode, device := fileID(info)
+	fileInfo := FileInfo{
+		Path:         fullPath,
+		Size:         info.Size(),
+		ModTime:      info.ModTime(),
+		IsDir:        info.IsDir(),
+		Permissions:  uint32(info.Mode().Perm()),
+		RelativePath: relPath,
+		Inode:        inode,
+		Device:       device,
+		Links:        linkCount(info),
+	}
+	if allocated, ok := allocatedSize(info); ok && info.Mode().IsRegular() {
+		fileInfo.Allocated = allocated
+		fileInfo.Sparse = allocated < info.Size()
+	}
+	if isSymlink(info) {
+		fileInfo.IsSymlink = true
+		fileInfo.LinkTarget, _ = os.Readlink(fullPath)
+	}
+	if !l.metadata.IsZero() {
+		l.readMetadata(fullPath, info, &fileInfo)
+	}
+	return fileInfo
+}
+
+// RootPath returns the absolute root directory of the backend
+func (l *Local) RootPath() string {
+	return l.rootPath
+}
+
+// SetHashCache attaches a persistent hash cache to the backend
+func (l *Local) SetHashCache(cache hashcache.Cache) {
+	l.hashCache = cache
+}
+
+// HashCache returns the attached hash cache, or nil if caching is disabled
+func (l *Local) HashCache() hashcache.Cache {
+	return l.hashCache
+}
+
+// forgetHash drops the cached hashes of a file whose content is replaced
+// Overwriting keeps the inode and the synced modification time, so the old entry would still match
+func (l *Local) forgetHash(path string) {
+	if l.hashCache != nil {
+		l.hashCache.Remove(path)
+	}
+}
+
 // Close releases resources (no-op for local filesystem)
 func (l *Local) Close() error {
 	return nil
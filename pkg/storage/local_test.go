@@ -559,6 +559,129 @@ func TestLocalMkdirAll(t *testing.T) {
 	})
 }
 
+// TestLocalLink tests hard link creation and that writes break existing links
+func TestLocalLink(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "syncnorris-storage-test-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	local, err := NewLocal(tempDir)
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+	defer local.Close()
+	ctx := context.Background()
+
+	original := filepath.Join(tempDir, "original.txt")
+	if err := os.WriteFile(original, []byte("shared"), 0644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "copy.txt"), []byte("shared"), 0644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	// An existing file is replaced by the link
+	for _, path := range []string{"copy.txt", "sub/link.txt"} {
+		if err := local.Link(ctx, path, "original.txt"); err != nil {
+			t.Skipf("Hard links not supported on this system: %v", err)
+		}
+		a, _ := os.Stat(original)
+		b, err := os.Stat(filepath.Join(tempDir, path))
+		if err != nil || !os.SameFile(a, b) {
+			t.Errorf("%s should be a hard link to original.txt", path)
+		}
+	}
+
+	info, err := local.Stat(ctx, "original.txt")
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if info.Links != 0 && info.Links != 3 {
+		t.Errorf("Links = %d, want 3", info.Links)
+	}
+
+	// Linking a path to its own file keeps it
+	if err := local.Link(ctx, "copy.txt", "original.txt"); err != nil {
+		t.Errorf("Link() onto an existing link error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "copy.txt.syncnorris-link")); !os.IsNotExist(err) {
+		t.Error("Link() should not leave its temporary link behind")
+	}
+
+	// Writing a linked path replaces it instead of changing the other links
+	content := "changed"
+	if err := local.Write(ctx, "copy.txt", bytes.NewReader([]byte(content)), int64(len(content)), nil); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if data, _ := os.ReadFile(original); string(data) != "shared" {
+		t.Errorf("original content = %q, want it unchanged", data)
+	}
+}
+
+// TestLocalSparseFile tests that holes are preserved when copying a sparse file
+func TestLocalSparseFile(t *testing.T) {
+	root := t.TempDir()
+	const size = 8 * 1024 * 1024
+	data := bytes.Repeat([]byte("data"), 1024)
+
+	// Data at the start and in the middle, holes elsewhere (including the end)
+	file, err := os.Create(filepath.Join(root, "disk.img"))
+	if err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	if err := file.Truncate(size); err != nil {
+		t.Fatalf("failed to truncate: %v", err)
+	}
+	for _, offset := range []int64{0, size / 2} {
+		if _, err := file.WriteAt(data, offset); err != nil {
+			t.Fatalf("failed to write: %v", err)
+		}
+	}
+	file.Close()
+
+	local, err := NewLocal(root)
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+	ctx := context.Background()
+
+	info, err := local.Stat(ctx, "disk.img")
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if !info.Sparse {
+		t.Skipf("Filesystem does not create sparse files (allocated %d of %d bytes)", info.Allocated, info.Size)
+	}
+
+	reader, err := local.Read(ctx, "disk.img")
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	defer reader.Close()
+	if err := local.Write(ctx, "copy.img", reader, size, info); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	source, _ := os.ReadFile(filepath.Join(root, "disk.img"))
+	copied, _ := os.ReadFile(filepath.Join(root, "copy.img"))
+	if !bytes.Equal(source, copied) {
+		t.Fatal("copied content differs from the source")
+	}
+
+	copyInfo, err := local.Stat(ctx, "copy.img")
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if copyInfo.Size != size {
+		t.Errorf("copy size = %d, want %d", copyInfo.Size, size)
+	}
+	if !copyInfo.Sparse || copyInfo.Allocated > 4*int64(len(data))+1024*1024 {
+		t.Errorf("copy allocated %d bytes, want holes preserved (source allocated %d)", copyInfo.Allocated, info.Allocated)
+	}
+}
+
 // TestBackendInterface verifies Local implements Backend interface
 func TestBackendInterface(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "syncnorris-storage-test-*")
@@ -576,3 +699,35 @@ func TestBackendInterface(t *testing.T) {
 	// Verify interface implementation
 	var _ Backend = local
 }
+
+// TestLocalReadUncached tests reading back a file just written, flushed and evicted from the page cache
+func TestLocalReadUncached(t *testing.T) {
+	local, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+
+	ctx := context.Background()
+	content := bytes.Repeat([]byte("verify"), 10000)
+	if err := local.Write(ctx, "dir/file.bin", bytes.NewReader(content), int64(len(content)), nil); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	reader, err := local.ReadUncached(ctx, "dir/file.bin")
+	if err != nil {
+		t.Fatalf("ReadUncached() error = %v", err)
+	}
+	defer reader.Close()
+
+	got, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Error("ReadUncached() content differs from the written content")
+	}
+
+	if _, err := local.ReadUncached(ctx, "missing.bin"); err == nil {
+		t.Error("ReadUncached() of a missing file should fail")
+	}
+}
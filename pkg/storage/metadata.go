@@ -0,0 +1,324 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// specialBits are the setuid, setgid and sticky mode bits
+const specialBits = fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky
+
+// aclXattrPrefix is the namespace holding POSIX ACLs on Linux
+const aclXattrPrefix = "system.posix_acl_"
+
+// hashCacheXattrPrefix is the namespace of the xattr hash cache, which is never copied
+const hashCacheXattrPrefix = "user.syncnorris."
+
+// xattrNamespaces are the extended attribute namespaces copied with MetadataOptions.Xattrs
+var xattrNamespaces = []string{"user.", "trusted.", "security."}
+
+// ErrMetadataNotSupported is returned when a backend cannot update metadata without rewriting content
+var ErrMetadataNotSupported = errors.New("backend does not support metadata updates")
+
+// MetadataOptions selects the metadata preserved besides modification times and permission bits
+// Each option is opt-in: the metadata is only read, compared and applied when enabled
+type MetadataOptions struct {
+	Owner       bool // Owner and group, mapped by name
+	NumericIDs  bool // Map owners by numeric ID instead of name
+	SpecialBits bool // setuid, setgid and sticky bits
+	Xattrs      bool // user, trusted and security extended attributes
+	ACLs        bool // POSIX access and default ACLs
+}
+
+// IsZero reports whether no extended metadata is preserved
+func (o MetadataOptions) IsZero() bool {
+	return !o.Owner && !o.SpecialBits && !o.Xattrs && !o.ACLs
+}
+
+// Owner is the ownership of a file
+type Owner struct {
+	UID   uint32
+	GID   uint32
+	User  string // Empty when not resolved (numeric IDs, or unknown ID)
+	Group string
+}
+
+// MetadataSetter is an optional interface for backends that can update metadata without rewriting content
+type MetadataSetter interface {
+	// SetMetadata applies permissions, modification time and the preserved metadata to an existing file
+	SetMetadata(ctx context.Context, path string, metadata *FileInfo) error
+}
+
+// MetadataError reports metadata that could not be applied to a file whose content was written
+// Callers may treat it as a warning: the content is correct
+type MetadataError struct {
+	Path string
+	Err  error
+}
+
+func (e *MetadataError) Error() string {
+	return fmt.Sprintf("failed to preserve metadata of %s: %v", e.Path, e.Err)
+}
+
+func (e *MetadataError) Unwrap() error {
+	return e.Err
+}
+
+// MetadataDiff returns the preserved metadata differing between a source and destination entry
+// Metadata read on one side only (e.g. unsupported by a backend) is not compared
+func MetadataDiff(source, dest *FileInfo) []string {
+	var diffs []string
+
+	if source.Owner != nil && dest.Owner != nil && !sameOwner(source.Owner, dest.Owner) {
+		diffs = append(diffs, "owner")
+	}
+
+	sourceSpecial := fs.FileMode(source.Permissions) & specialBits
+	destSpecial := fs.FileMode(dest.Permissions) & specialBits
+	if sourceSpecial != destSpecial {
+		diffs = append(diffs, "special bits")
+	}
+
+	if source.Xattrs != nil && dest.Xattrs != nil {
+		isACL := func(name string) bool { return strings.HasPrefix(name, aclXattrPrefix) }
+		if !sameXattrs(source.Xattrs, dest.Xattrs, func(name string) bool { return !isACL(name) }) {
+			diffs = append(diffs, "xattrs")
+		}
+		if !sameXattrs(source.Xattrs, dest.Xattrs, isACL) {
+			diffs = append(diffs, "acls")
+		}
+	}
+
+	return diffs
+}
+
+// sameOwner compares ownership by name when both sides resolved it, by ID otherwise
+func sameOwner(a, b *Owner) bool {
+	sameUser := a.UID == b.UID
+	if a.User != "" && b.User != "" {
+		sameUser = a.User == b.User
+	}
+	sameGroup := a.GID == b.GID
+	if a.Group != "" && b.Group != "" {
+		sameGroup = a.Group == b.Group
+	}
+	return sameUser && sameGroup
+}
+
+// sameXattrs compares the attributes selected by match
+func sameXattrs(a, b map[string][]byte, match func(string) bool) bool {
+	count := 0
+	for name, value := range a {
+		if !match(name) {
+			continue
+		}
+		count++
+		other, ok := b[name]
+		if !ok || !bytes.Equal(value, other) {
+			return false
+		}
+	}
+	for name := range b {
+		if match(name) {
+			count--
+		}
+	}
+	return count == 0
+}
+
+// preservesXattr reports whether an extended attribute is preserved with the options
+func (o MetadataOptions) preservesXattr(name string) bool {
+	if strings.HasPrefix(name, aclXattrPrefix) {
+		return o.ACLs
+	}
+	if !o.Xattrs || strings.HasPrefix(name, hashCacheXattrPrefix) {
+		return false
+	}
+	for _, namespace := range xattrNamespaces {
+		if strings.HasPrefix(name, namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// idNames resolves user and group names, caching lookups
+type idNames struct {
+	mu     sync.Mutex
+	users  map[uint32]string
+	groups map[uint32]string
+	uids   map[string]uint32
+	gids   map[string]uint32
+}
+
+func newIDNames() *idNames {
+	return &idNames{
+		users:  make(map[uint32]string),
+		groups: make(map[uint32]string),
+		uids:   make(map[string]uint32),
+		gids:   make(map[string]uint32),
+	}
+}
+
+// names returns the user and group names of IDs (empty when unknown)
+func (n *idNames) names(uid, gid uint32) (string, string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	name, ok := n.users[uid]
+	if !ok {
+		if u, err := user.LookupId(strconv.FormatUint(uint64(uid), 10)); err == nil {
+			name = u.Username
+		}
+		n.users[uid] = name
+	}
+
+	group, ok := n.groups[gid]
+	if !ok {
+		if g, err := user.LookupGroupId(strconv.FormatUint(uint64(gid), 10)); err == nil {
+			group = g.Name
+		}
+		n.groups[gid] = group
+	}
+
+	return name, group
+}
+
+// ids maps an owner to local IDs by name, keeping the numeric IDs of unknown names
+func (n *idNames) ids(owner *Owner) (int, int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	uid := owner.UID
+	if owner.User != "" {
+		id, ok := n.uids[owner.User]
+		if !ok {
+			id = owner.UID
+			if u, err := user.Lookup(owner.User); err == nil {
+				if parsed, err := strconv.ParseUint(u.Uid, 10, 32); err == nil {
+					id = uint32(parsed)
+				}
+			}
+			n.uids[owner.User] = id
+		}
+		uid = id
+	}
+
+	gid := owner.GID
+	if owner.Group != "" {
+		id, ok := n.gids[owner.Group]
+		if !ok {
+			id = owner.GID
+			if g, err := user.LookupGroup(owner.Group); err == nil {
+				if parsed, err := strconv.ParseUint(g.Gid, 10, 32); err == nil {
+					id = uint32(parsed)
+				}
+			}
+			n.gids[owner.Group] = id
+		}
+		gid = id
+	}
+
+	return int(uid), int(gid)
+}
+
+// readMetadata fills the preserved metadata of a file
+func (l *Local) readMetadata(fullPath string, info os.FileInfo, fileInfo *FileInfo) {
+	opts := l.metadata
+	if opts.SpecialBits {
+		fileInfo.Permissions |= uint32(info.Mode() & specialBits)
+	}
+
+	if opts.Owner {
+		if uid, gid, ok := fileOwner(info); ok {
+			owner := &Owner{UID: uid, GID: gid}
+			if !opts.NumericIDs {
+				owner.User, owner.Group = l.ids.names(uid, gid)
+			}
+			fileInfo.Owner = owner
+		}
+	}
+
+	if (opts.Xattrs || opts.ACLs) && !fileInfo.IsSymlink {
+		if attrs, err := readXattrs(fullPath, opts.preservesXattr); err == nil {
+			fileInfo.Xattrs = attrs
+		}
+	}
+}
+
+// applyMetadata sets the ownership and extended attributes of a file
+// Filesystems without extended attribute support are ignored; other failures are returned
+func (l *Local) applyMetadata(fullPath string, metadata *FileInfo) error {
+	var failures []string
+
+	if l.metadata.Owner && metadata.Owner != nil {
+		uid, gid := int(metadata.Owner.UID), int(metadata.Owner.GID)
+		if !l.metadata.NumericIDs {
+			uid, gid = l.ids.ids(metadata.Owner)
+		}
+		if err := os.Lchown(fullPath, uid, gid); err != nil {
+			failures = append(failures, fmt.Sprintf("owner: %v", err))
+		}
+	}
+
+	if (l.metadata.Xattrs || l.metadata.ACLs) && metadata.Xattrs != nil && !metadata.IsSymlink {
+		if err := l.applyXattrs(fullPath, metadata.Xattrs); err != nil {
+			failures = append(failures, fmt.Sprintf("xattrs: %v", err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// applyXattrs makes the preserved extended attributes of a file match attrs
+func (l *Local) applyXattrs(fullPath string, attrs map[string][]byte) error {
+	current, err := readXattrs(fullPath, l.metadata.preservesXattr)
+	if err != nil {
+		if errors.Is(err, errXattrUnsupported) {
+			return nil
+		}
+		return err
+	}
+
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var failures []string
+	for _, name := range names {
+		if !l.metadata.preservesXattr(name) || bytes.Equal(current[name], attrs[name]) {
+			continue
+		}
+		if err := setXattr(fullPath, name, attrs[name]); err != nil && !errors.Is(err, errXattrUnsupported) {
+			failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	for name := range current {
+		if _, ok := attrs[name]; ok {
+			continue
+		}
+		if err := removeXattr(fullPath, name); err != nil && !errors.Is(err, errXattrUnsupported) {
+			failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+
+	if len(failures) > 0 {
+		sort.Strings(failures)
+		return errors.New(strings.Join(failures, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMetadataDiff(t *testing.T) {
+	owner := &Owner{UID: 1000, GID: 1000, User: "alice", Group: "staff"}
+
+	tests := []struct {
+		name   string
+		source FileInfo
+		dest   FileInfo
+		want   string
+	}{
+		{"nothing read", FileInfo{Permissions: 0644}, FileInfo{Permissions: 0644}, ""},
+		{"same owner", FileInfo{Owner: owner}, FileInfo{Owner: &Owner{UID: 1000, GID: 1000, User: "alice", Group: "staff"}}, ""},
+		{"same name, other ID", FileInfo{Owner: owner}, FileInfo{Owner: &Owner{UID: 1001, GID: 1001, User: "alice", Group: "staff"}}, ""},
+		{"other owner", FileInfo{Owner: owner}, FileInfo{Owner: &Owner{UID: 1000, GID: 1000, User: "bob", Group: "staff"}}, "owner"},
+		{"numeric IDs", FileInfo{Owner: &Owner{UID: 1}}, FileInfo{Owner: &Owner{UID: 2}}, "owner"},
+		{"owner read on one side", FileInfo{Owner: owner}, FileInfo{}, ""},
+		{"special bits", FileInfo{Permissions: uint32(0755 | fs.ModeSetuid)}, FileInfo{Permissions: 0755}, "special bits"},
+		{"xattr value", FileInfo{Xattrs: map[string][]byte{"user.a": []byte("1")}}, FileInfo{Xattrs: map[string][]byte{"user.a": []byte("2")}}, "xattrs"},
+		{"extra xattr", FileInfo{Xattrs: map[string][]byte{}}, FileInfo{Xattrs: map[string][]byte{"user.a": nil}}, "xattrs"},
+		{"acl", FileInfo{Xattrs: map[string][]byte{"system.posix_acl_access": []byte("x")}}, FileInfo{Xattrs: map[string][]byte{}}, "acls"},
+		{"xattrs read on one side", FileInfo{Xattrs: map[string][]byte{"user.a": nil}}, FileInfo{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.Join(MetadataDiff(&tt.source, &tt.dest), ",")
+			if got != tt.want {
+				t.Errorf("MetadataDiff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalPreservesSpecialBits(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "tool")
+	if err := os.WriteFile(path, []byte("binary"), 0755); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	if err := os.Chmod(path, 0755|os.ModeSetuid); err != nil {
+		t.Skipf("setuid bit not supported: %v", err)
+	}
+
+	local, err := NewLocal(root)
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+	ctx := context.Background()
+
+	// Special bits are only reported when preserved
+	info, err := local.Stat(ctx, "tool")
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if fs.FileMode(info.Permissions)&fs.ModeSetuid != 0 {
+		t.Error("Stat() should not report special bits by default")
+	}
+
+	local.SetMetadataOptions(MetadataOptions{SpecialBits: true})
+	info, err = local.Stat(ctx, "tool")
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if fs.FileMode(info.Permissions)&fs.ModeSetuid == 0 {
+		t.Fatal("Stat() should report the setuid bit when special bits are preserved")
+	}
+
+	if err := local.Write(ctx, "copy", strings.NewReader("binary"), 6, info); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	copyInfo, err := os.Stat(filepath.Join(root, "copy"))
+	if err != nil {
+		t.Fatalf("failed to stat copy: %v", err)
+	}
+	if copyInfo.Mode()&os.ModeSetuid == 0 {
+		t.Error("Write() should preserve the setuid bit")
+	}
+}
+
+func TestLocalOwner(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "file"), []byte("data"), 0644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	local, err := NewLocal(root)
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+	local.SetMetadataOptions(MetadataOptions{Owner: true})
+	ctx := context.Background()
+
+	info, err := local.Stat(ctx, "file")
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if info.Owner == nil {
+		t.Skip("Ownership not supported on this platform")
+	}
+	if info.Owner.UID != uint32(os.Getuid()) {
+		t.Errorf("Owner.UID = %d, want %d", info.Owner.UID, os.Getuid())
+	}
+
+	// Applying the current owner always succeeds
+	if err := local.SetMetadata(ctx, "file", info); err != nil {
+		t.Errorf("SetMetadata() error = %v", err)
+	}
+}
+
+func TestLocalXattrs(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"source", "dest"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("data"), 0644); err != nil {
+			t.Fatalf("failed to create file: %v", err)
+		}
+	}
+	if err := setXattr(filepath.Join(root, "source"), "user.comment", []byte("kept")); err != nil {
+		t.Skipf("Extended attributes not supported: %v", err)
+	}
+	if err := setXattr(filepath.Join(root, "source"), "user.syncnorris.hash.sha256", []byte("cache")); err != nil {
+		t.Fatalf("setXattr() error = %v", err)
+	}
+	if err := setXattr(filepath.Join(root, "dest"), "user.stale", []byte("removed")); err != nil {
+		t.Fatalf("setXattr() error = %v", err)
+	}
+
+	local, err := NewLocal(root)
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+	local.SetMetadataOptions(MetadataOptions{Xattrs: true})
+	ctx := context.Background()
+
+	source, err := local.Stat(ctx, "source")
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if len(source.Xattrs) != 1 || string(source.Xattrs["user.comment"]) != "kept" {
+		t.Fatalf("Xattrs = %v, want only user.comment (the hash cache is not preserved)", source.Xattrs)
+	}
+
+	if err := local.SetMetadata(ctx, "dest", source); err != nil {
+		var metadataErr *MetadataError
+		if !errors.As(err, &metadataErr) {
+			t.Fatalf("SetMetadata() error = %v", err)
+		}
+	}
+	dest, err := local.Stat(ctx, "dest")
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if diffs := MetadataDiff(source, dest); len(diffs) != 0 {
+		t.Errorf("MetadataDiff() after SetMetadata = %v, want none", diffs)
+	}
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// probeFileName is the temporary file written to measure timestamp granularity
+const probeFileName = ".syncnorris-mtime-probe"
+
+// probeModTime has an odd second and sub-second digits down to the nanosecond,
+// so any rounding by the filesystem shows in the time read back
+var probeModTime = time.Date(2001, 2, 3, 4, 5, 7, 123456789, time.UTC)
+
+// timeGranularities are the common filesystem timestamp resolutions, finest first
+// (ext4/APFS, NTFS, some network filesystems, exFAT, HFS+/ext3, FAT)
+var timeGranularities = []time.Duration{
+	time.Nanosecond,
+	100 * time.Nanosecond,
+	time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	time.Second,
+	2 * time.Second,
+}
+
+// ProbeTimeGranularity measures the modification time resolution of a backend
+// It writes a small temporary file with a known modification time, reads the time back
+// and removes the file again
+func ProbeTimeGranularity(ctx context.Context, backend Backend) (time.Duration, error) {
+	metadata := &FileInfo{ModTime: probeModTime}
+	if err := backend.Write(ctx, probeFileName, strings.NewReader(""), 0, metadata); err != nil {
+		return 0, fmt.Errorf("failed to write timestamp probe: %w", err)
+	}
+	defer backend.Delete(ctx, probeFileName)
+
+	info, err := backend.Stat(ctx, probeFileName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat timestamp probe: %w", err)
+	}
+
+	diff := info.ModTime.Sub(probeModTime)
+	if diff < 0 {
+		diff = -diff
+	}
+	coarsest := timeGranularities[len(timeGranularities)-1]
+	if diff >= coarsest {
+		return 0, fmt.Errorf("modification time is not preserved (off by %s)", diff)
+	}
+
+	// The coarsest resolution the time read back is a multiple of
+	nanos := info.ModTime.UnixNano()
+	for i := len(timeGranularities) - 1; i > 0; i-- {
+		if nanos%int64(timeGranularities[i]) == 0 {
+			return timeGranularities[i], nil
+		}
+	}
+	return timeGranularities[0], nil
+}
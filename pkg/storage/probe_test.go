@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+// roundingBackend rounds written modification times like a coarse filesystem would
+type roundingBackend struct {
+	*Local
+	round func(time.Time) time.Time
+}
+
+func (b *roundingBackend) Write(ctx context.Context, path string, reader io.Reader, size int64, metadata *FileInfo) error {
+	rounded := *metadata
+	rounded.ModTime = b.round(metadata.ModTime)
+	return b.Local.Write(ctx, path, reader, size, &rounded)
+}
+
+// TestProbeTimeGranularity tests timestamp resolution detection
+func TestProbeTimeGranularity(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		round func(time.Time) time.Time
+		want  time.Duration
+	}{
+		{"FAT truncates", func(t time.Time) time.Time { return t.Truncate(2 * time.Second) }, 2 * time.Second},
+		{"FAT rounds up", func(t time.Time) time.Time { return t.Truncate(2 * time.Second).Add(2 * time.Second) }, 2 * time.Second},
+		{"seconds", func(t time.Time) time.Time { return t.Truncate(time.Second) }, time.Second},
+		{"exFAT", func(t time.Time) time.Time { return t.Truncate(10 * time.Millisecond) }, 10 * time.Millisecond},
+		{"NTFS", func(t time.Time) time.Time { return t.Truncate(100 * time.Nanosecond) }, 100 * time.Nanosecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			local, err := NewLocal(t.TempDir())
+			if err != nil {
+				t.Fatalf("NewLocal() error = %v", err)
+			}
+			defer local.Close()
+
+			got, err := ProbeTimeGranularity(ctx, &roundingBackend{Local: local, round: tt.round})
+			if err != nil {
+				t.Fatalf("ProbeTimeGranularity() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ProbeTimeGranularity() = %s, want %s", got, tt.want)
+			}
+			if exists, _ := local.Exists(ctx, probeFileName); exists {
+				t.Error("probe file should be removed")
+			}
+		})
+	}
+
+	t.Run("NotPreserved", func(t *testing.T) {
+		local, err := NewLocal(t.TempDir())
+		if err != nil {
+			t.Fatalf("NewLocal() error = %v", err)
+		}
+		defer local.Close()
+
+		backend := &roundingBackend{Local: local, round: func(time.Time) time.Time { return time.Now() }}
+		if _, err := ProbeTimeGranularity(ctx, backend); err == nil {
+			t.Error("ProbeTimeGranularity() should fail when times are not preserved")
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// sparseBlockSize is the granularity of holes created when writing sparse files
+const sparseBlockSize = 4096
+
+// sparseBufferSize is the size of the chunks written to sparse files
+const sparseBufferSize = 256 * 1024
+
+// PreallocateThreshold is the size from which non-sparse files are preallocated before writing
+const PreallocateThreshold = 8 * 1024 * 1024
+
+// writeSparse writes content to a new file, seeking over zero blocks instead of writing them
+// so that they become holes; returns the number of bytes of content
+func writeSparse(file *os.File, reader io.Reader) (int64, error) {
+	buf := make([]byte, sparseBufferSize)
+	var offset int64
+
+	for {
+		n, readErr := io.ReadFull(reader, buf)
+		for start := 0; start < n; start += sparseBlockSize {
+			end := min(start+sparseBlockSize, n)
+			block := buf[start:end]
+			if isZero(block) {
+				continue
+			}
+			if _, err := file.WriteAt(block, offset+int64(start)); err != nil {
+				return offset, err
+			}
+		}
+		offset += int64(n)
+
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return offset, readErr
+		}
+	}
+
+	// A trailing hole is not written: set the file length explicitly
+	if err := file.Truncate(offset); err != nil {
+		return offset, fmt.Errorf("failed to set sparse file size: %w", err)
+	}
+	return offset, nil
+}
+
+// isZero reports whether a block only holds zero bytes
+func isZero(block []byte) bool {
+	for _, b := range block {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
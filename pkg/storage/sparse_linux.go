@@ -0,0 +1,93 @@
+//go:build linux
+
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// sparseReader reads a sparse file, returning zeros for holes without reading them
+// Data and hole regions are located with SEEK_DATA and SEEK_HOLE
+type sparseReader struct {
+	file    *os.File
+	size    int64
+	offset  int64
+	dataEnd int64 // End of the data region containing offset
+	holeEnd int64 // End of the hole containing offset
+}
+
+// openSparse returns a reader skipping the holes of a sparse file
+func openSparse(file *os.File, size int64) io.ReadCloser {
+	return &sparseReader{file: file, size: size}
+}
+
+func (r *sparseReader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+	if r.offset >= r.dataEnd && r.offset >= r.holeEnd {
+		if err := r.locate(); err != nil {
+			return 0, err
+		}
+	}
+
+	if r.offset < r.holeEnd {
+		n := int(min(int64(len(p)), r.holeEnd-r.offset))
+		clear(p[:n])
+		r.offset += int64(n)
+		return n, nil
+	}
+
+	n, err := r.file.ReadAt(p[:min(int64(len(p)), r.dataEnd-r.offset)], r.offset)
+	r.offset += int64(n)
+	if err == io.EOF && r.offset < r.size {
+		// The file was truncated while reading
+		return n, io.ErrUnexpectedEOF
+	}
+	if err == io.EOF {
+		err = nil
+	}
+	return n, err
+}
+
+// locate finds the data region or hole starting at the current offset
+func (r *sparseReader) locate() error {
+	fd := int(r.file.Fd())
+
+	data, err := unix.Seek(fd, r.offset, unix.SEEK_DATA)
+	if errors.Is(err, unix.ENXIO) {
+		// No data after offset: the rest of the file is a hole
+		r.holeEnd = r.size
+		return nil
+	}
+	if err != nil {
+		// SEEK_DATA unsupported: read the rest of the file as data
+		r.dataEnd = r.size
+		return nil
+	}
+	if data > r.offset {
+		r.holeEnd = min(data, r.size)
+		return nil
+	}
+
+	hole, err := unix.Seek(fd, r.offset, unix.SEEK_HOLE)
+	if err != nil || hole <= r.offset {
+		hole = r.size
+	}
+	r.dataEnd = min(hole, r.size)
+	return nil
+}
+
+func (r *sparseReader) Close() error {
+	return r.file.Close()
+}
+
+// preallocate reserves disk space for a file to reduce fragmentation
+// Filesystems without fallocate support are ignored
+func preallocate(file *os.File, size int64) {
+	_ = unix.Fallocate(int(file.Fd()), unix.FALLOC_FL_KEEP_SIZE, 0, size)
+}
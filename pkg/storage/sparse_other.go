@@ -0,0 +1,16 @@
+//go:build !linux
+
+package storage
+
+import (
+	"io"
+	"os"
+)
+
+// openSparse returns the file itself: holes are read as zeros by the filesystem
+func openSparse(file *os.File, size int64) io.ReadCloser {
+	return file
+}
+
+// preallocate does nothing on platforms without fallocate
+func preallocate(file *os.File, size int64) {}
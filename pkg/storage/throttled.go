@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"context"
+	"io"
+
+	"github.com/sdejongh/syncnorris/pkg/hashcache"
+	"github.com/sdejongh/syncnorris/pkg/ratelimit"
+)
+
+// Throttle holds the limits applied to a backend (nil = unlimited)
+// Limiters may be shared between backends to enforce a common budget
+type Throttle struct {
+	Read  *ratelimit.Limiter    // Bytes read from files
+	Write *ratelimit.Limiter    // Bytes written to files
+	Ops   *ratelimit.OpsLimiter // Backend calls (List, Stat, Exists, Read, Write, Delete, MkdirAll, Symlink, Link)
+}
+
+// IsZero reports whether the throttle sets no limit
+func (t Throttle) IsZero() bool {
+	return t.Read == nil && t.Write == nil && t.Ops == nil
+}
+
+// Throttled wraps a backend with read, write and operation rate limits
+type Throttled struct {
+	backend  Backend
+	throttle Throttle
+}
+
+// throttledChecksummer is a throttled backend whose wrapped backend records file hashes
+type throttledChecksummer struct {
+	*Throttled
+	checksummer Checksummer
+}
+
+// NewThrottled wraps a backend with the given limits
+// The backend is returned unchanged when the throttle sets no limit
+func NewThrottled(backend Backend, throttle Throttle) Backend {
+	if throttle.IsZero() {
+		return backend
+	}
+
+	t := &Throttled{
+		backend:  backend,
+		throttle: throttle,
+	}
+	if checksummer, ok := backend.(Checksummer); ok {
+		return &throttledChecksummer{Throttled: t, checksummer: checksummer}
+	}
+	return t
+}
+
+// List returns all files in the specified directory recursively
+func (t *Throttled) List(ctx context.Context, path string) ([]FileInfo, error) {
+	if err := t.throttle.Ops.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return t.backend.List(ctx, path)
+}
+
+// Read opens a file for reading, limiting the read bandwidth
+func (t *Throttled) Read(ctx context.Context, path string) (io.ReadCloser, error) {
+	if err := t.throttle.Ops.Wait(ctx); err != nil {
+		return nil, err
+	}
+	reader, err := t.backend.Read(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return ratelimit.NewReadCloser(ctx, reader, t.throttle.Read), nil
+}
+
+// ReadUncached opens a file read back from storage if the wrapped backend supports it, and
+// like Read otherwise, limiting the read bandwidth
+func (t *Throttled) ReadUncached(ctx context.Context, path string) (io.ReadCloser, error) {
+	uncached, ok := t.backend.(UncachedReader)
+	if !ok {
+		return t.Read(ctx, path)
+	}
+	if err := t.throttle.Ops.Wait(ctx); err != nil {
+		return nil, err
+	}
+	reader, err := uncached.ReadUncached(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return ratelimit.NewReadCloser(ctx, reader, t.throttle.Read), nil
+}
+
+// Write creates or overwrites a file, limiting the write bandwidth
+func (t *Throttled) Write(ctx context.Context, path string, reader io.Reader, size int64, metadata *FileInfo) error {
+	if err := t.throttle.Ops.Wait(ctx); err != nil {
+		return err
+	}
+	return t.backend.Write(ctx, path, ratelimit.NewReader(ctx, reader, t.throttle.Write), size, metadata)
+}
+
+// Delete removes a file or directory
+func (t *Throttled) Delete(ctx context.Context, path string) error {
+	if err := t.throttle.Ops.Wait(ctx); err != nil {
+		return err
+	}
+	return t.backend.Delete(ctx, path)
+}
+
+// Exists checks if a file or directory exists
+func (t *Throttled) Exists(ctx context.Context, path string) (bool, error) {
+	if err := t.throttle.Ops.Wait(ctx); err != nil {
+		return false, err
+	}
+	return t.backend.Exists(ctx, path)
+}
+
+// Stat returns file metadata
+func (t *Throttled) Stat(ctx context.Context, path string) (*FileInfo, error) {
+	if err := t.throttle.Ops.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return t.backend.Stat(ctx, path)
+}
+
+// MkdirAll creates a directory and all necessary parents
+func (t *Throttled) MkdirAll(ctx context.Context, path string) error {
+	if err := t.throttle.Ops.Wait(ctx); err != nil {
+		return err
+	}
+	return t.backend.MkdirAll(ctx, path)
+}
+
+// Symlink creates a symbolic link
+func (t *Throttled) Symlink(ctx context.Context, path string, target string) error {
+	if err := t.throttle.Ops.Wait(ctx); err != nil {
+		return err
+	}
+	return t.backend.Symlink(ctx, path, target)
+}
+
+// Link creates a hard link if the wrapped backend supports it
+func (t *Throttled) Link(ctx context.Context, path string, existing string) error {
+	linker, ok := t.backend.(HardLinker)
+	if !ok {
+		return ErrHardLinksNotSupported
+	}
+	if err := t.throttle.Ops.Wait(ctx); err != nil {
+		return err
+	}
+	return linker.Link(ctx, path, existing)
+}
+
+// SetMetadata updates a file's metadata if the wrapped backend supports it
+func (t *Throttled) SetMetadata(ctx context.Context, path string, metadata *FileInfo) error {
+	setter, ok := t.backend.(MetadataSetter)
+	if !ok {
+		return ErrMetadataNotSupported
+	}
+	if err := t.throttle.Ops.Wait(ctx); err != nil {
+		return err
+	}
+	return setter.SetMetadata(ctx, path, metadata)
+}
+
+// Flush makes the changes written so far durable if the wrapped backend defers it
+// Flushing is not throttled: it transfers no file data
+func (t *Throttled) Flush(ctx context.Context) error {
+	if flusher, ok := t.backend.(Flusher); ok {
+		return flusher.Flush(ctx)
+	}
+	return nil
+}
+
+// FastCopy copies a file without streaming it if the wrapped backend supports it
+// Kernel copies are paced by the write limit
+func (t *Throttled) FastCopy(ctx context.Context, path string, source Backend, sourcePath string, metadata *FileInfo, hooks FastCopyHooks) (CopyMethod, error) {
+	copier, ok := t.backend.(FastCopier)
+	if !ok {
+		return "", ErrFastCopyNotSupported
+	}
+	if err := t.throttle.Ops.Wait(ctx); err != nil {
+		return "", err
+	}
+	return copier.FastCopy(ctx, path, source, sourcePath, metadata, hooks.limit(ctx, t.throttle.Write))
+}
+
+// unwrap returns the wrapped backend and the limits applied to it
+func (t *Throttled) unwrap() (Backend, Throttle) {
+	return t.backend, t.throttle
+}
+
+// Close releases any resources held by the wrapped backend
+func (t *Throttled) Close() error {
+	return t.backend.Close()
+}
+
+// HashCache returns the wrapped backend's hash cache, or nil if it has none
+func (t *Throttled) HashCache() hashcache.Cache {
+	if hc, ok := t.backend.(HashCacher); ok {
+		return hc.HashCache()
+	}
+	return nil
+}
+
+// Checksum returns the recorded hash of a file
+func (t *throttledChecksummer) Checksum(path string) (Checksum, bool) {
+	return t.checksummer.Checksum(path)
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/ratelimit"
+)
+
+// checksumBackend is a backend recording file hashes, like a checksum manifest
+type checksumBackend struct {
+	*Local
+}
+
+func (b checksumBackend) Checksum(path string) (Checksum, bool) {
+	return Checksum{Algorithm: "sha256", Hash: "abc", Size: -1}, true
+}
+
+// TestNewThrottled tests wrapping backends with limits
+func TestNewThrottled(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "syncnorris-throttled-test-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	local, err := NewLocal(tempDir)
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+
+	t.Run("NoLimits", func(t *testing.T) {
+		if backend := NewThrottled(local, Throttle{}); backend != Backend(local) {
+			t.Error("NewThrottled() without limits should return the backend unchanged")
+		}
+	})
+
+	t.Run("PreservesChecksummer", func(t *testing.T) {
+		throttle := Throttle{Ops: ratelimit.NewOpsLimiter(1000)}
+
+		if _, ok := NewThrottled(local, throttle).(Checksummer); ok {
+			t.Error("throttled Local should not implement Checksummer")
+		}
+
+		backend := NewThrottled(checksumBackend{local}, throttle)
+		checksummer, ok := backend.(Checksummer)
+		if !ok {
+			t.Fatal("throttled checksum backend should implement Checksummer")
+		}
+		if sum, ok := checksummer.Checksum("file.txt"); !ok || sum.Hash != "abc" {
+			t.Errorf("Checksum() = %v, %v; want hash abc", sum, ok)
+		}
+	})
+
+	t.Run("ReadWrite", func(t *testing.T) {
+		backend := NewThrottled(local, Throttle{
+			Read:  ratelimit.NewLimiter(10 * 1024 * 1024),
+			Write: ratelimit.NewLimiter(10 * 1024 * 1024),
+		})
+		ctx := context.Background()
+		content := []byte("throttled content")
+
+		if err := backend.Write(ctx, "file.txt", bytes.NewReader(content), int64(len(content)), nil); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		reader, err := backend.Read(ctx, "file.txt")
+		if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+		defer reader.Close()
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("ReadAll() error = %v", err)
+		}
+		if !bytes.Equal(data, content) {
+			t.Errorf("read %q, want %q", data, content)
+		}
+	})
+}
+
+// TestThrottledOps tests that backend calls are limited per second
+func TestThrottledOps(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "syncnorris-throttled-test-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	local, err := NewLocal(tempDir)
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+
+	// 20 ops/s with a burst of 2: 6 calls need at least 200ms
+	backend := NewThrottled(local, Throttle{Ops: ratelimit.NewOpsLimiter(20)})
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		if _, err := backend.Exists(ctx, "missing.txt"); err != nil {
+			t.Fatalf("Exists() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond {
+		t.Errorf("6 operations at 20 ops/s took %v, want at least 200ms", elapsed)
+	}
+
+	// Waiting operations give up when the context is canceled
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	for i := 0; i < 3; i++ {
+		if _, err = backend.Stat(canceled, "."); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		t.Error("Stat() with a canceled context should fail once the burst is used")
+	}
+}
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// UncachedReader is an optional interface for backends that can read a file back from the storage
+// device instead of the copy of recently written data kept in memory
+type UncachedReader interface {
+	// ReadUncached flushes a file to storage, drops its cached content and opens it for reading
+	ReadUncached(ctx context.Context, path string) (io.ReadCloser, error)
+}
+
+// ReadUncached opens a file after flushing it and evicting it from the page cache, so reading it
+// detects data corrupted on its way to the device or by the device itself
+// Eviction is supported on Linux; elsewhere the file is only flushed
+func (l *Local) ReadUncached(ctx context.Context, path string) (io.ReadCloser, error) {
+	fullPath := filepath.Join(l.rootPath, path)
+
+	if err := l.checkRead(fullPath); err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	file, err := os.Open(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	// Dirty pages cannot be evicted: they must reach the device first
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to sync file: %w", err)
+	}
+	if err := dropCache(file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to drop cached file content: %w", err)
+	}
+
+	return file, nil
+}
@@ -0,0 +1,14 @@
+//go:build linux
+
+package storage
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// dropCache evicts the cached pages of a flushed file, so it is read again from the device
+func dropCache(file *os.File) error {
+	return unix.Fadvise(int(file.Fd()), 0, 0, unix.FADV_DONTNEED)
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package storage
+
+import "os"
+
+// dropCache does nothing: cached pages cannot be evicted portably
+func dropCache(file *os.File) error {
+	return nil
+}
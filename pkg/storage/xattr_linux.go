@@ -0,0 +1,76 @@
+//go:build linux
+
+package storage
+
+import (
+	"bytes"
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+// errXattrUnsupported is returned when the filesystem or platform has no extended attributes
+var errXattrUnsupported = errors.New("extended attributes not supported")
+
+// readXattrs returns the extended attributes of a file (not following links) selected by match
+func readXattrs(path string, match func(string) bool) (map[string][]byte, error) {
+	size, err := unix.Llistxattr(path, nil)
+	if err != nil {
+		return nil, xattrError(err)
+	}
+
+	attrs := make(map[string][]byte)
+	if size == 0 {
+		return attrs, nil
+	}
+	buf := make([]byte, size)
+	n, err := unix.Llistxattr(path, buf)
+	if err != nil {
+		return nil, xattrError(err)
+	}
+
+	for _, name := range bytes.Split(buf[:n], []byte{0}) {
+		if len(name) == 0 || !match(string(name)) {
+			continue
+		}
+		value, err := getXattr(path, string(name))
+		if err != nil {
+			// Attributes the process may not read (e.g. trusted.* without privileges) are skipped
+			continue
+		}
+		attrs[string(name)] = value
+	}
+	return attrs, nil
+}
+
+// getXattr reads an extended attribute, growing the buffer as needed
+func getXattr(path, name string) ([]byte, error) {
+	size, err := unix.Lgetxattr(path, name, nil)
+	if err != nil {
+		return nil, xattrError(err)
+	}
+	buf := make([]byte, size)
+	n, err := unix.Lgetxattr(path, name, buf)
+	if err != nil {
+		return nil, xattrError(err)
+	}
+	return buf[:n], nil
+}
+
+// setXattr writes an extended attribute
+func setXattr(path, name string, value []byte) error {
+	return xattrError(unix.Lsetxattr(path, name, value, 0))
+}
+
+// removeXattr deletes an extended attribute
+func removeXattr(path, name string) error {
+	return xattrError(unix.Lremovexattr(path, name))
+}
+
+// xattrError maps "not supported" errors to errXattrUnsupported
+func xattrError(err error) error {
+	if errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EOPNOTSUPP) {
+		return errXattrUnsupported
+	}
+	return err
+}
@@ -0,0 +1,21 @@
+//go:build !linux
+
+package storage
+
+import "errors"
+
+// errXattrUnsupported is returned when the filesystem or platform has no extended attributes
+var errXattrUnsupported = errors.New("extended attributes not supported")
+
+// Extended attributes and POSIX ACLs are only preserved on Linux
+func readXattrs(path string, match func(string) bool) (map[string][]byte, error) {
+	return nil, errXattrUnsupported
+}
+
+func setXattr(path, name string, value []byte) error {
+	return errXattrUnsupported
+}
+
+func removeXattr(path, name string) error {
+	return errXattrUnsupported
+}
@@ -0,0 +1,277 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/logging"
+	"github.com/sdejongh/syncnorris/pkg/models"
+	"github.com/sdejongh/syncnorris/pkg/ratelimit"
+)
+
+const (
+	// defaultAdaptiveInterval is the time between adjustments when none is configured
+	defaultAdaptiveInterval = 2 * time.Second
+
+	// adaptiveBandwidthSteps is the number of healthy intervals needed to grow from the minimum to the maximum bandwidth
+	adaptiveBandwidthSteps = 10
+)
+
+// workerGate limits how many pipeline workers process tasks at the same time
+type workerGate struct {
+	mu      sync.Mutex
+	limit   int
+	active  int
+	changed chan struct{} // Closed and replaced whenever a slot may have become available
+}
+
+// newWorkerGate creates a gate letting limit workers process tasks
+func newWorkerGate(limit int) *workerGate {
+	return &workerGate{
+		limit:   limit,
+		changed: make(chan struct{}),
+	}
+}
+
+// acquire blocks until a slot is free, returning false if the context is canceled first
+func (g *workerGate) acquire(ctx context.Context) bool {
+	for {
+		g.mu.Lock()
+		if g.active < g.limit {
+			g.active++
+			g.mu.Unlock()
+			return true
+		}
+		changed := g.changed
+		g.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-changed:
+		}
+	}
+}
+
+// release frees the slot taken by acquire
+func (g *workerGate) release() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.active--
+	g.notify()
+}
+
+// Limit returns the number of workers allowed to process tasks
+func (g *workerGate) Limit() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.limit
+}
+
+// setLimit changes the number of workers allowed to process tasks
+// A lower limit takes effect as running tasks complete
+func (g *workerGate) setLimit(limit int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.limit = limit
+	g.notify()
+}
+
+// notify wakes up waiting workers (must be called with lock held)
+func (g *workerGate) notify() {
+	close(g.changed)
+	g.changed = make(chan struct{})
+}
+
+// adaptiveSample is the activity observed during one adjustment interval
+type adaptiveSample struct {
+	writes       int64         // Chunks written to the destination
+	writeLatency time.Duration // Total time the destination took to write them
+	files        int64         // Files processed
+	errors       int64         // Files that failed
+}
+
+// adaptiveController grows and shrinks the active workers and the bandwidth limit of a pipeline
+// It backs off (halving both) when the destination is slow, files fail or the host is loaded,
+// and grows again step by step while everything stays healthy
+type adaptiveController struct {
+	settings    models.AdaptiveThrottling
+	gate        *workerGate
+	limiter     *ratelimit.Limiter // nil = bandwidth is not adapted
+	logger      logging.Logger
+	loadAverage func() (float64, bool)
+	cpus        int
+
+	mu     sync.Mutex
+	sample adaptiveSample
+}
+
+// newAdaptiveController creates a controller starting with the given number of workers (clamped to the bounds)
+// limiter is the bandwidth limiter to adapt, nil when the bandwidth is not adapted
+func newAdaptiveController(settings models.AdaptiveThrottling, workers int, limiter *ratelimit.Limiter, logger logging.Logger) *adaptiveController {
+	if settings.Interval <= 0 {
+		settings.Interval = defaultAdaptiveInterval
+	}
+	if settings.MinWorkers < 1 {
+		settings.MinWorkers = 1
+	}
+	if settings.MaxWorkers < settings.MinWorkers {
+		settings.MaxWorkers = settings.MinWorkers
+	}
+	if settings.MinBandwidth < 1 {
+		// A zero rate would mean unlimited
+		settings.MinBandwidth = 1
+	}
+
+	return &adaptiveController{
+		settings:    settings,
+		gate:        newWorkerGate(min(max(workers, settings.MinWorkers), settings.MaxWorkers)),
+		limiter:     limiter,
+		logger:      logger,
+		loadAverage: loadAverage,
+		cpus:        runtime.NumCPU(),
+	}
+}
+
+// run adjusts the limits every interval until the context is canceled
+func (c *adaptiveController) run(ctx context.Context) {
+	ticker := time.NewTicker(c.settings.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.adjust(ctx)
+		}
+	}
+}
+
+// observeFile records a processed file
+func (c *adaptiveController) observeFile(failed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sample.files++
+	if failed {
+		c.sample.errors++
+	}
+}
+
+// observeWrite records the time the destination took to write one chunk
+func (c *adaptiveController) observeWrite(latency time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sample.writes++
+	c.sample.writeLatency += latency
+}
+
+// writeReader wraps the reader passed to a destination write to measure write latency
+func (c *adaptiveController) writeReader(reader io.Reader) io.Reader {
+	return &latencyReader{
+		reader:     reader,
+		controller: c,
+		returned:   time.Now(),
+	}
+}
+
+// adjust takes the sample of the elapsed interval and changes the limits accordingly
+func (c *adaptiveController) adjust(ctx context.Context) {
+	c.mu.Lock()
+	sample := c.sample
+	c.sample = adaptiveSample{}
+	c.mu.Unlock()
+
+	fields := logging.Fields{}
+	var reasons []string
+
+	if sample.writes > 0 {
+		latency := sample.writeLatency / time.Duration(sample.writes)
+		fields["write_latency"] = latency.String()
+		if c.settings.TargetLatency > 0 && latency > c.settings.TargetLatency {
+			reasons = append(reasons, fmt.Sprintf("write latency %s above %s", latency, c.settings.TargetLatency))
+		}
+	}
+
+	if sample.files > 0 {
+		errorRate := float64(sample.errors) / float64(sample.files)
+		fields["error_rate"] = errorRate
+		if errorRate > c.settings.MaxErrorRate {
+			reasons = append(reasons, fmt.Sprintf("error rate %.0f%% above %.0f%%", errorRate*100, c.settings.MaxErrorRate*100))
+		}
+	}
+
+	if c.settings.MaxLoad > 0 {
+		if load, ok := c.loadAverage(); ok {
+			perCPU := load / float64(c.cpus)
+			fields["load_per_cpu"] = perCPU
+			if perCPU > c.settings.MaxLoad {
+				reasons = append(reasons, fmt.Sprintf("load average %.2f per CPU above %.2f", perCPU, c.settings.MaxLoad))
+			}
+		}
+	}
+
+	workers := c.gate.Limit()
+	newWorkers := workers
+	var bandwidth, newBandwidth int64
+	if c.limiter != nil {
+		bandwidth = c.limiter.Rate()
+		newBandwidth = bandwidth
+	}
+
+	var msg string
+	switch {
+	case len(reasons) > 0:
+		msg = "Adaptive throttling backing off"
+		newWorkers = max(workers/2, c.settings.MinWorkers)
+		newBandwidth = max(bandwidth/2, c.settings.MinBandwidth)
+		fields["reasons"] = reasons
+	case sample.writes > 0 || sample.files > 0:
+		msg = "Adaptive throttling increasing limits"
+		newWorkers = min(workers+1, c.settings.MaxWorkers)
+		step := max((c.settings.MaxBandwidth-c.settings.MinBandwidth)/adaptiveBandwidthSteps, 1)
+		newBandwidth = min(bandwidth+step, c.settings.MaxBandwidth)
+	default:
+		// Nothing happened during the interval: keep the limits
+		return
+	}
+
+	if c.limiter == nil {
+		newBandwidth = bandwidth
+	}
+	if newWorkers == workers && newBandwidth == bandwidth {
+		return
+	}
+
+	c.gate.setLimit(newWorkers)
+	fields["workers"] = newWorkers
+	if c.limiter != nil {
+		c.limiter.SetRate(newBandwidth)
+		fields["bandwidth"] = newBandwidth
+	}
+
+	if c.logger != nil {
+		c.logger.Info(ctx, msg, fields)
+	}
+}
+
+// latencyReader measures how long the destination takes to write each chunk it reads
+// The time between two reads is spent by the destination writing the previous chunk
+// (or creating the file, for the first read); time spent reading the source is excluded
+type latencyReader struct {
+	reader     io.Reader
+	controller *adaptiveController
+	returned   time.Time // When the previous read returned
+}
+
+// Read implements io.Reader, recording the destination's latency since the previous read
+func (r *latencyReader) Read(p []byte) (int, error) {
+	r.controller.observeWrite(time.Since(r.returned))
+	n, err := r.reader.Read(p)
+	r.returned = time.Now()
+	return n, err
+}
@@ -0,0 +1,170 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/compare"
+	"github.com/sdejongh/syncnorris/pkg/models"
+	"github.com/sdejongh/syncnorris/pkg/ratelimit"
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+// slowBackend delays every write, like an overloaded destination
+type slowBackend struct {
+	*storage.Local
+	delay time.Duration
+}
+
+func (b *slowBackend) Write(ctx context.Context, path string, reader io.Reader, size int64, metadata *storage.FileInfo) error {
+	time.Sleep(b.delay)
+	return b.Local.Write(ctx, path, reader, size, metadata)
+}
+
+func (b *slowBackend) FastCopy(ctx context.Context, path string, source storage.Backend, sourcePath string, metadata *storage.FileInfo, hooks storage.FastCopyHooks) (storage.CopyMethod, error) {
+	time.Sleep(b.delay)
+	return b.Local.FastCopy(ctx, path, source, sourcePath, metadata, hooks)
+}
+
+func TestWorkerGate(t *testing.T) {
+	gate := newWorkerGate(1)
+
+	if !gate.acquire(context.Background()) {
+		t.Fatal("acquire() should succeed below the limit")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if gate.acquire(ctx) {
+		t.Fatal("acquire() should block at the limit until the context expires")
+	}
+
+	acquired := make(chan bool)
+	go func() {
+		acquired <- gate.acquire(context.Background())
+	}()
+	gate.setLimit(2)
+	select {
+	case ok := <-acquired:
+		if !ok {
+			t.Error("acquire() should succeed after the limit was raised")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("acquire() still blocked after the limit was raised")
+	}
+
+	gate.release()
+	gate.release()
+	if gate.active != 0 {
+		t.Errorf("active = %d, want 0", gate.active)
+	}
+}
+
+func TestAdaptiveController_Adjust(t *testing.T) {
+	settings := models.AdaptiveThrottling{
+		MinWorkers:    1,
+		MaxWorkers:    8,
+		MinBandwidth:  1000,
+		MaxBandwidth:  11000,
+		TargetLatency: 50 * time.Millisecond,
+		MaxErrorRate:  0.1,
+		MaxLoad:       2,
+	}
+
+	tests := []struct {
+		name          string
+		latency       time.Duration
+		files         int
+		errors        int
+		load          float64
+		wantWorkers   int
+		wantBandwidth int64
+	}{
+		{"Healthy", 10 * time.Millisecond, 10, 0, 1, 5, 9000},
+		{"SlowWrites", 80 * time.Millisecond, 10, 0, 1, 2, 4000},
+		{"Errors", 10 * time.Millisecond, 10, 2, 1, 2, 4000},
+		{"Loaded", 10 * time.Millisecond, 10, 0, 3, 2, 4000},
+		{"Idle", 0, 0, 0, 1, 4, 8000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := ratelimit.NewLimiter(8000)
+			c := newAdaptiveController(settings, 4, limiter, nil)
+			c.cpus = 1
+			c.loadAverage = func() (float64, bool) { return tt.load, true }
+
+			if tt.latency > 0 {
+				c.observeWrite(tt.latency)
+			}
+			for i := 0; i < tt.files; i++ {
+				c.observeFile(i < tt.errors)
+			}
+			c.adjust(context.Background())
+
+			if got := c.gate.Limit(); got != tt.wantWorkers {
+				t.Errorf("workers = %d, want %d", got, tt.wantWorkers)
+			}
+			if got := limiter.Rate(); got != tt.wantBandwidth {
+				t.Errorf("bandwidth = %d, want %d", got, tt.wantBandwidth)
+			}
+		})
+	}
+
+	t.Run("Bounds", func(t *testing.T) {
+		limiter := ratelimit.NewLimiter(1500)
+		c := newAdaptiveController(settings, 1, limiter, nil)
+		c.loadAverage = func() (float64, bool) { return 0, false }
+
+		c.observeWrite(time.Second)
+		c.adjust(context.Background())
+		if c.gate.Limit() != 1 || limiter.Rate() != 1000 {
+			t.Errorf("after back-off: workers = %d, bandwidth = %d; want 1, 1000", c.gate.Limit(), limiter.Rate())
+		}
+
+		for i := 0; i < 20; i++ {
+			c.observeFile(false)
+			c.adjust(context.Background())
+		}
+		if c.gate.Limit() != 8 || limiter.Rate() != 11000 {
+			t.Errorf("after growth: workers = %d, bandwidth = %d; want 8, 11000", c.gate.Limit(), limiter.Rate())
+		}
+	})
+}
+
+func TestPipeline_Adaptive(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	for i := 0; i < 20; i++ {
+		h.CreateSourceFile(fmt.Sprintf("file%02d.txt", i), []byte(fmt.Sprintf("content %d", i)))
+	}
+
+	op := h.NewOperation()
+	op.Mode = models.ModeOneWay
+	op.MaxWorkers = 4
+	op.Adaptive = &models.AdaptiveThrottling{
+		MinWorkers:    1,
+		MaxWorkers:    4,
+		TargetLatency: time.Millisecond,
+		MaxErrorRate:  0.1,
+		Interval:      10 * time.Millisecond,
+	}
+
+	dest := &slowBackend{Local: h.dest, delay: 5 * time.Millisecond}
+	pipeline := NewPipeline(h.source, dest, compare.NewHashComparator(4096), &nullFormatter{}, nil, op, PipelineConfig{MaxWorkers: 4, QueueSize: 100})
+	report, err := pipeline.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if report.Status != models.StatusSuccess || report.Stats.FilesCopied.Load() != 20 {
+		t.Errorf("Status = %s, copied = %d; want success, 20", report.Status, report.Stats.FilesCopied.Load())
+	}
+	if got := pipeline.adaptive.gate.Limit(); got >= 4 {
+		t.Errorf("active workers = %d, want fewer than 4 after slow writes", got)
+	}
+}
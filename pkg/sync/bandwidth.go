@@ -0,0 +1,113 @@
+package sync
+
+import (
+	"context"
+	"io"
+
+	"github.com/sdejongh/syncnorris/pkg/compare"
+	"github.com/sdejongh/syncnorris/pkg/models"
+	"github.com/sdejongh/syncnorris/pkg/output"
+	"github.com/sdejongh/syncnorris/pkg/ratelimit"
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+// newRateLimiter creates the operation's bandwidth limiter (nil = unlimited)
+// A bandwidth schedule takes precedence over the fixed bandwidth limit
+func newRateLimiter(operation *models.SyncOperation) *ratelimit.Limiter {
+	if operation.BandwidthSchedule != "" {
+		// The schedule was validated with the configuration
+		if schedule, err := ratelimit.ParseSchedule(operation.BandwidthSchedule); err == nil {
+			return ratelimit.NewScheduledLimiter(schedule)
+		}
+	}
+	if operation.BandwidthLimit > 0 {
+		return ratelimit.NewLimiter(operation.BandwidthLimit)
+	}
+	return nil
+}
+
+// readerWrapper returns a reader wrapper applying the bandwidth limit (nil = unlimited)
+func readerWrapper(ctx context.Context, limiter *ratelimit.Limiter) compare.ReaderWrapper {
+	if limiter == nil {
+		return nil
+	}
+	return func(rc io.ReadCloser) io.ReadCloser {
+		return ratelimit.NewReadCloser(ctx, rc, limiter)
+	}
+}
+
+// reportRateLimit sends the effective bandwidth limit to the formatter, and again whenever it changes
+func reportRateLimit(limiter *ratelimit.Limiter, formatter output.Formatter) {
+	if limiter == nil || formatter == nil {
+		return
+	}
+
+	report := func(bytesPerSecond int64) {
+		formatter.Progress(output.ProgressUpdate{
+			Type:      "rate_limit",
+			Limit:     output.LimitBandwidth,
+			RateLimit: bytesPerSecond,
+		})
+	}
+	limiter.SetOnRateChange(report)
+	report(limiter.Rate())
+}
+
+// backendThrottles holds the independent read, write and operation limits of a sync
+// The write and operation limiters are shared by both sides
+type backendThrottles struct {
+	sourceRead *ratelimit.Limiter
+	destRead   *ratelimit.Limiter
+	write      *ratelimit.Limiter
+	ops        *ratelimit.OpsLimiter
+}
+
+// newBackendThrottles creates the operation's per-backend limiters (nil = unlimited)
+func newBackendThrottles(operation *models.SyncOperation) backendThrottles {
+	return backendThrottles{
+		sourceRead: ratelimit.NewLimiter(operation.SourceReadLimit),
+		destRead:   ratelimit.NewLimiter(operation.DestReadLimit),
+		write:      ratelimit.NewLimiter(operation.WriteLimit),
+		ops:        ratelimit.NewOpsLimiter(operation.OpsLimit),
+	}
+}
+
+// wrap returns the source and destination backends with their limits applied
+func (t backendThrottles) wrap(source, dest storage.Backend) (storage.Backend, storage.Backend) {
+	source = storage.NewThrottled(source, storage.Throttle{Read: t.sourceRead, Write: t.write, Ops: t.ops})
+	dest = storage.NewThrottled(dest, storage.Throttle{Read: t.destRead, Write: t.write, Ops: t.ops})
+	return source, dest
+}
+
+// report sends the limits that are set to the formatter
+func (t backendThrottles) report(formatter output.Formatter) {
+	if formatter == nil {
+		return
+	}
+
+	limits := []struct {
+		name    string
+		limiter *ratelimit.Limiter
+	}{
+		{output.LimitSourceRead, t.sourceRead},
+		{output.LimitDestRead, t.destRead},
+		{output.LimitWrite, t.write},
+	}
+	for _, limit := range limits {
+		if limit.limiter != nil {
+			formatter.Progress(output.ProgressUpdate{
+				Type:      "rate_limit",
+				Limit:     limit.name,
+				RateLimit: limit.limiter.Rate(),
+			})
+		}
+	}
+
+	if t.ops != nil {
+		formatter.Progress(output.ProgressUpdate{
+			Type:      "rate_limit",
+			Limit:     output.LimitOps,
+			RateLimit: t.ops.Rate(),
+		})
+	}
+}
@@ -28,7 +28,10 @@ type BidirectionalPipeline struct {
 	operation   *models.SyncOperation
 	config      PipelineConfig
 	state       *SyncState
+	tolerance   compare.TimeTolerance
 	rateLimiter *ratelimit.Limiter
+	throttles   backendThrottles
+	metrics     MetricsObserver
 
 	// Synchronization
 	resultsMu sync.Mutex
@@ -43,6 +46,10 @@ func NewBidirectionalPipeline(
 	operation *models.SyncOperation,
 	config PipelineConfig,
 ) *BidirectionalPipeline {
+	// Apply the independent read, write and operation limits to the backends
+	throttles := newBackendThrottles(operation)
+	source, dest = throttles.wrap(source, dest)
+
 	return &BidirectionalPipeline{
 		source:     source,
 		dest:       dest,
@@ -51,6 +58,7 @@ func NewBidirectionalPipeline(
 		logger:     logger,
 		operation:  operation,
 		config:     config,
+		throttles:  throttles,
 	}
 }
 
@@ -70,6 +78,10 @@ func (p *BidirectionalPipeline) Run(ctx context.Context) (*models.SyncReport, er
 		Status:      models.StatusSuccess,
 	}
 
+	if p.metrics != nil {
+		p.metrics.Track(report)
+	}
+
 	// Log start of bidirectional sync
 	if p.logger != nil {
 		p.logger.Info(ctx, "Starting bidirectional sync", logging.Fields{
@@ -93,11 +105,12 @@ func (p *BidirectionalPipeline) Run(ctx context.Context) (*models.SyncReport, er
 		// Stateless mode: create empty state (treats everything as first sync)
 		p.state = NewSyncState(p.operation.SourcePath, p.operation.DestPath)
 	}
+	p.tolerance = TimeTolerance(p.operation)
+	p.state.SetTimeTolerance(p.tolerance)
+	p.state.SetDurability(stateDurability(p.operation))
 
-	// Initialize rate limiter if bandwidth limit is set
-	if p.operation.BandwidthLimit > 0 {
-		p.rateLimiter = ratelimit.NewLimiter(p.operation.BandwidthLimit)
-	}
+	// Initialize rate limiter if a bandwidth limit or schedule is set
+	p.rateLimiter = newRateLimiter(p.operation)
 
 	// Configure comparator with rate limiter if supported
 	if p.rateLimiter != nil {
@@ -110,16 +123,21 @@ func (p *BidirectionalPipeline) Run(ctx context.Context) (*models.SyncReport, er
 
 	// Start formatter (we'll update totals after scanning)
 	p.formatter.Start(os.Stdout, 0, 0, p.config.MaxWorkers)
+	reportRateLimit(p.rateLimiter, p.formatter)
+	p.throttles.report(p.formatter)
 
 	// Phase 1: Scan both sides and detect changes
 	if p.logger != nil {
 		p.logger.Debug(ctx, "Phase 1: Scanning source and destination", nil)
 	}
+	scanStart := time.Now()
 	sourceFiles, destFiles, err := p.scanBothSides(ctx, report)
+	p.observePhase(PhaseScan, scanStart)
 	if err != nil {
 		if p.logger != nil {
 			p.logger.Error(ctx, "Scan failed", err, nil)
 		}
+		report.Status = models.StatusFailed
 		p.formatter.Complete(report)
 		return report, fmt.Errorf("scan failed: %w", err)
 	}
@@ -134,7 +152,9 @@ func (p *BidirectionalPipeline) Run(ctx context.Context) (*models.SyncReport, er
 	if p.logger != nil {
 		p.logger.Debug(ctx, "Phase 2: Analyzing changes and detecting conflicts", nil)
 	}
+	compareStart := time.Now()
 	actions, conflicts := p.analyzeChanges(ctx, sourceFiles, destFiles, report)
+	p.observePhase(PhaseCompare, compareStart)
 
 	// Phase 3: Handle conflicts according to resolution strategy
 	if p.logger != nil && len(conflicts) > 0 {
@@ -145,6 +165,7 @@ func (p *BidirectionalPipeline) Run(ctx context.Context) (*models.SyncReport, er
 	}
 	resolvedActions := p.resolveConflicts(ctx, conflicts, report)
 	actions = append(actions, resolvedActions...)
+	p.reportTotals(actions)
 
 	// Phase 4: Execute sync actions
 	if p.logger != nil {
@@ -161,6 +182,16 @@ func (p *BidirectionalPipeline) Run(ctx context.Context) (*models.SyncReport, er
 		report.Status = models.StatusPartial
 	}
 
+	// Make the changes on both sides durable before recording them in the state
+	if !p.operation.DryRun {
+		for _, err := range flushWrites(ctx, report, p.source, p.dest) {
+			if p.logger != nil {
+				p.logger.Error(ctx, "Failed to flush writes", err, nil)
+			}
+			report.Status = models.StatusPartial
+		}
+	}
+
 	// Update state if stateful mode and not dry-run
 	if p.operation.Stateful && !p.operation.DryRun {
 		p.state.MarkSyncComplete()
@@ -271,6 +302,8 @@ func (p *BidirectionalPipeline) scanSide(ctx context.Context, backend storage.Ba
 			ModTime:      storageEntry.ModTime,
 			IsDir:        storageEntry.IsDir,
 			Permissions:  storageEntry.Permissions,
+			IsSymlink:    storageEntry.IsSymlink,
+			LinkTarget:   storageEntry.LinkTarget,
 		}
 
 		if entry.IsDir {
@@ -447,13 +480,32 @@ func (p *BidirectionalPipeline) analyzeFirstSync(path string, sourceEntry, destE
 	// File in both - always treat as potential conflict on first sync
 	// since we don't know which version is "correct"
 	if sourceExists && destExists {
+		// Symbolic links are identical when both sides point to the same target
+		if sourceEntry.IsSymlink || destEntry.IsSymlink {
+			if sourceEntry.IsSymlink && destEntry.IsSymlink && sourceEntry.LinkTarget == destEntry.LinkTarget {
+				return &SyncAction{
+					Path:        path,
+					ActionType:  models.ActionSkip,
+					SourceEntry: sourceEntry,
+					DestEntry:   destEntry,
+					Reason:      "symlink targets match",
+				}, nil
+			}
+			return nil, &models.Conflict{
+				Path:        path,
+				SourceEntry: sourceEntry,
+				DestEntry:   destEntry,
+				Type:        models.ConflictCreateCreate,
+				DetectedAt:  time.Now(),
+			}
+		}
+
 		// If same size, check if content is actually the same
 		// by returning a skip action that will be verified
 		if sourceEntry.Size == destEntry.Size {
-			timeDiff := sourceEntry.ModTime.Sub(destEntry.ModTime)
-			// Only skip if timestamps are very close (within 1 second)
+			// Only skip if timestamps match within the modify window
 			// AND sizes match - but mark for verification
-			if timeDiff < time.Second && timeDiff > -time.Second {
+			if p.tolerance.Equal(sourceEntry.ModTime, destEntry.ModTime) {
 				return &SyncAction{
 					Path:        path,
 					ActionType:  models.ActionSkip,
@@ -477,6 +529,15 @@ func (p *BidirectionalPipeline) analyzeFirstSync(path string, sourceEntry, destE
 	return nil, nil
 }
 
+// TimeTolerance returns the modification time tolerance configured for an operation
+func TimeTolerance(operation *models.SyncOperation) compare.TimeTolerance {
+	window := operation.ModifyWindow
+	if window == 0 {
+		window = compare.DefaultModifyWindow
+	}
+	return compare.TimeTolerance{Window: window, IgnoreHourOffsets: operation.IgnoreHourOffsets}
+}
+
 // detectChangeType determines what kind of change occurred on one side
 func (p *BidirectionalPipeline) detectChangeType(entry *models.FileEntry, oldState *FileState, isSource bool) ChangeType {
 	exists := entry != nil
@@ -501,7 +562,7 @@ func (p *BidirectionalPipeline) detectChangeType(entry *models.FileEntry, oldSta
 		if entry.Size != oldState.Size {
 			return ChangeModified
 		}
-		if entry.ModTime.After(oldState.ModTime.Add(time.Second)) {
+		if p.tolerance.After(entry.ModTime, oldState.ModTime) {
 			return ChangeModified
 		}
 	}
@@ -590,6 +651,14 @@ func (p *BidirectionalPipeline) resolveConflicts(ctx context.Context, conflicts
 
 		// Add the resolved conflict to the report
 		report.Conflicts = append(report.Conflicts, *conflict)
+
+		if p.formatter != nil {
+			p.formatter.Progress(output.ProgressUpdate{
+				Type:     "conflict",
+				FilePath: conflict.Path,
+				Conflict: conflict,
+			})
+		}
 	}
 
 	return actions
@@ -741,6 +810,34 @@ func (p *BidirectionalPipeline) resolveConflict(conflict *models.Conflict) *Sync
 	return nil
 }
 
+// reportTotals sends the number and size of the files to process to the formatter
+// Totals are only known once both sides are analyzed, so a single scan_progress event is sent
+func (p *BidirectionalPipeline) reportTotals(actions []*SyncAction) {
+	if p.formatter == nil {
+		return
+	}
+
+	var files int
+	var bytes int64
+	for _, action := range actions {
+		if (action.SourceEntry != nil && action.SourceEntry.IsDir) || (action.DestEntry != nil && action.DestEntry.IsDir) {
+			continue
+		}
+		files++
+		if action.SourceEntry != nil {
+			bytes += action.SourceEntry.Size
+		} else if action.DestEntry != nil {
+			bytes += action.DestEntry.Size
+		}
+	}
+
+	p.formatter.Progress(output.ProgressUpdate{
+		Type:       "scan_progress",
+		TotalFiles: files,
+		TotalBytes: bytes,
+	})
+}
+
 // executeActions performs all sync actions
 func (p *BidirectionalPipeline) executeActions(ctx context.Context, actions []*SyncAction, report *models.SyncReport) error {
 	// Sort actions: directories first, then files (for proper creation order)
@@ -769,20 +866,36 @@ func (p *BidirectionalPipeline) executeActions(ctx context.Context, actions []*S
 		if err != nil {
 			hasErrors = true
 			report.Stats.FilesErrored.Add(1)
+			operation := action.ActionType
+			diff := models.FileDifference{
+				RelativePath: action.Path,
+				Reason:       models.ReasonCopyError,
+				Details:      err.Error(),
+			}
+			if verr, ok := isVerifyError(err); ok {
+				operation = models.ActionVerify
+				diff.Reason = models.ReasonVerifyFailed
+				diff.SourceInfo = &models.FileInfo{Hash: verr.SourceHash, HashAlgorithm: verifyAlgorithm}
+				diff.DestInfo = &models.FileInfo{Hash: verr.DestHash, HashAlgorithm: verifyAlgorithm}
+			}
 			report.Errors = append(report.Errors, models.SyncError{
 				FilePath:  action.Path,
-				Operation: action.ActionType,
+				Operation: operation,
 				Error:     err.Error(),
 				Timestamp: time.Now(),
 			})
 
 			p.resultsMu.Lock()
-			report.Differences = append(report.Differences, models.FileDifference{
-				RelativePath: action.Path,
-				Reason:       models.ReasonCopyError,
-				Details:      err.Error(),
-			})
+			report.Differences = append(report.Differences, diff)
 			p.resultsMu.Unlock()
+
+			if p.formatter != nil {
+				p.formatter.Progress(output.ProgressUpdate{
+					Type:     "file_error",
+					FilePath: action.Path,
+					Error:    err,
+				})
+			}
 		}
 	}
 
@@ -794,6 +907,22 @@ func (p *BidirectionalPipeline) executeActions(ctx context.Context, actions []*S
 
 // executeAction performs a single sync action
 func (p *BidirectionalPipeline) executeAction(ctx context.Context, action *SyncAction, report *models.SyncReport) error {
+	if p.formatter != nil {
+		var size int64
+		if action.SourceEntry != nil {
+			size = action.SourceEntry.Size
+		} else if action.DestEntry != nil {
+			size = action.DestEntry.Size
+		}
+		p.formatter.Progress(output.ProgressUpdate{
+			Type:       "file_decision",
+			FilePath:   action.Path,
+			TotalBytes: size,
+			Action:     action.ActionType,
+			Reason:     action.Reason,
+		})
+	}
+
 	if p.operation.DryRun {
 		// Just report what would happen
 		p.reportDryRunAction(action, report)
@@ -900,7 +1029,28 @@ func (p *BidirectionalPipeline) executeCopy(ctx context.Context, action *SyncAct
 				"path": action.Path,
 			})
 		}
+	} else if srcEntry.IsSymlink {
+		// Recreate the link rather than copying its target
+		if err := dstBackend.Symlink(ctx, action.Path, srcEntry.LinkTarget); err != nil {
+			if p.logger != nil {
+				p.logger.Error(ctx, "Failed to create symbolic link", err, logging.Fields{
+					"path":   action.Path,
+					"target": srcEntry.LinkTarget,
+				})
+			}
+			return fmt.Errorf("failed to create symbolic link: %w", err)
+		}
+		report.Stats.FilesCopied.Add(1)
+		if p.logger != nil {
+			p.logger.Debug(ctx, "Symbolic link created", logging.Fields{
+				"path":      action.Path,
+				"target":    srcEntry.LinkTarget,
+				"direction": action.Direction,
+			})
+		}
 	} else {
+		defer p.observePhase(PhaseTransfer, time.Now())
+
 		// Copy file
 		reader, err := srcBackend.Read(ctx, action.Path)
 		if err != nil {
@@ -925,6 +1075,14 @@ func (p *BidirectionalPipeline) executeCopy(ctx context.Context, action *SyncAct
 			Permissions: srcEntry.Permissions,
 		}
 
+		// Hash the content while streaming it so the written file can be verified
+		// and its hash recorded in the sync state
+		var hr *hashingReader
+		if p.operation.Verify || p.operation.Stateful {
+			hr = newHashingReader(readerToUse)
+			readerToUse = hr
+		}
+
 		err = dstBackend.Write(ctx, action.Path, readerToUse, srcEntry.Size, metadata)
 		if err != nil {
 			if p.logger != nil {
@@ -936,9 +1094,30 @@ func (p *BidirectionalPipeline) executeCopy(ctx context.Context, action *SyncAct
 			return fmt.Errorf("failed to write destination: %w", err)
 		}
 
+		var hash string
+		if hr != nil {
+			hash = hr.Sum()
+		}
+		if p.operation.Verify {
+			if hash, err = p.verifyCopy(ctx, srcBackend, dstBackend, action.Path, metadata, hash); err != nil {
+				if p.logger != nil {
+					p.logger.Error(ctx, "Failed to verify destination file", err, logging.Fields{
+						"path": action.Path,
+						"size": srcEntry.Size,
+					})
+				}
+				return err
+			}
+		}
+
 		report.Stats.FilesCopied.Add(1)
 		report.Stats.BytesTransferred.Add(srcEntry.Size)
 
+		if hash != "" {
+			srcEntry.Hash = hash
+			srcEntry.HashAlgorithm = verifyAlgorithm
+		}
+
 		if p.logger != nil {
 			p.logger.Debug(ctx, "File copied successfully", logging.Fields{
 				"path":      action.Path,
@@ -954,6 +1133,32 @@ func (p *BidirectionalPipeline) executeCopy(ctx context.Context, action *SyncAct
 	return nil
 }
 
+// verifyCopy checks a written file against the hash of the streamed source content,
+// copying it again up to the configured number of retries. Returns the verified hash
+func (p *BidirectionalPipeline) verifyCopy(ctx context.Context, srcBackend, dstBackend storage.Backend, path string, metadata *storage.FileInfo, sourceHash string) (string, error) {
+	verifier := &transferVerifier{
+		source:  srcBackend,
+		dest:    dstBackend,
+		retries: p.operation.VerifyRetries,
+	}
+	if p.rateLimiter != nil {
+		verifier.wrap = func(ctx context.Context, reader io.Reader) io.Reader {
+			return ratelimit.NewReader(ctx, reader, p.rateLimiter)
+		}
+	}
+
+	return verifier.verify(ctx, path, metadata, sourceHash, func(attempt int, destHash string) {
+		if p.logger != nil {
+			p.logger.Warn(ctx, "Verification failed, copying file again", logging.Fields{
+				"path":        path,
+				"attempt":     attempt,
+				"source_hash": sourceHash,
+				"dest_hash":   destHash,
+			})
+		}
+	})
+}
+
 // executeUpdate updates an existing file
 func (p *BidirectionalPipeline) executeUpdate(ctx context.Context, action *SyncAction, report *models.SyncReport) error {
 	if p.logger != nil {
@@ -1017,7 +1222,9 @@ func (p *BidirectionalPipeline) executeDelete(ctx context.Context, action *SyncA
 		})
 	}
 
+	deleteStart := time.Now()
 	err := backend.Delete(ctx, action.Path)
+	p.observePhase(PhaseDelete, deleteStart)
 	if err != nil {
 		if p.logger != nil {
 			p.logger.Error(ctx, "Failed to delete file", err, logging.Fields{
@@ -1068,6 +1275,11 @@ func (p *BidirectionalPipeline) executeConflictBoth(ctx context.Context, action
 		return nil
 	}
 
+	// Conflict copies are made by reading content, which links do not have
+	if (action.SourceEntry != nil && action.SourceEntry.IsSymlink) || (action.DestEntry != nil && action.DestEntry.IsSymlink) {
+		return fmt.Errorf("keeping both versions is not supported for symbolic links")
+	}
+
 	basePath := action.Path
 	ext := filepath.Ext(basePath)
 	nameWithoutExt := basePath[:len(basePath)-len(ext)]
@@ -1253,6 +1465,13 @@ func (p *BidirectionalPipeline) reportDryRunAction(action *SyncAction, report *m
 	p.resultsMu.Unlock()
 }
 
+// observePhase reports the time elapsed since start to the metrics observer
+func (p *BidirectionalPipeline) observePhase(phase string, start time.Time) {
+	if p.metrics != nil {
+		p.metrics.ObservePhase(phase, time.Since(start))
+	}
+}
+
 // updateStateForFile updates the state after a successful file operation
 func (p *BidirectionalPipeline) updateStateForFile(path string, entry *models.FileEntry, existsInSource, existsInDest bool) {
 	if entry == nil {
@@ -1265,6 +1484,7 @@ func (p *BidirectionalPipeline) updateStateForFile(path string, entry *models.Fi
 		entry.Size,
 		entry.ModTime,
 		entry.Hash,
+		entry.HashAlgorithm,
 		existsInSource,
 		existsInDest,
 		entry.IsDir,
@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
@@ -989,3 +990,82 @@ func TestBidirectionalPipeline_BidirectionalWithStateful(t *testing.T) {
 	// Clean up state file
 	ClearState(h.sourceDir, h.destDir)
 }
+
+// recordingFormatter records progress updates for testing
+type recordingFormatter struct {
+	nullFormatter
+	mu      sync.Mutex
+	updates []output.ProgressUpdate
+}
+
+func (f *recordingFormatter) Progress(update output.ProgressUpdate) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.updates = append(f.updates, update)
+	return nil
+}
+
+func (f *recordingFormatter) ofType(updateType string) []output.ProgressUpdate {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var result []output.ProgressUpdate
+	for _, u := range f.updates {
+		if u.Type == updateType {
+			result = append(result, u)
+		}
+	}
+	return result
+}
+
+func TestBidirectionalPipeline_ProgressEvents(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.CreateSourceFile("new.txt", []byte("only in source"))
+	h.CreateSourceFile("conflict.txt", []byte("source version"))
+	h.CreateDestFile("conflict.txt", []byte("dest version"))
+	h.SetFileModTime(true, "conflict.txt", time.Now())
+	h.SetFileModTime(false, "conflict.txt", time.Now().Add(-5*time.Second))
+
+	op := h.NewOperation()
+	config := PipelineConfig{MaxWorkers: 2, QueueSize: 100}
+	formatter := &recordingFormatter{}
+	comparator := compare.NewHashComparator(4096)
+
+	pipeline := NewBidirectionalPipeline(h.source, h.dest, comparator, formatter, nil, op, config)
+	if _, err := pipeline.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// Totals are reported once, before the first decision
+	scans := formatter.ofType("scan_progress")
+	if len(scans) != 1 || scans[0].TotalFiles != 2 || scans[0].TotalBytes != int64(len("only in source")+len("source version")) {
+		t.Errorf("scan_progress updates = %+v, want one with 2 files and 28 bytes", scans)
+	}
+	for _, u := range formatter.updates {
+		if u.Type == "file_decision" {
+			t.Error("file_decision reported before scan_progress")
+		}
+		if u.Type == "scan_progress" {
+			break
+		}
+	}
+
+	decisions := make(map[string]output.ProgressUpdate)
+	for _, u := range formatter.ofType("file_decision") {
+		decisions[u.FilePath] = u
+	}
+	if d, ok := decisions["new.txt"]; !ok || d.Action != models.ActionCopy || d.Reason == "" {
+		t.Errorf("decision for new.txt = %+v, want copy with reason", d)
+	}
+
+	var found bool
+	for _, u := range formatter.ofType("conflict") {
+		if u.FilePath == "conflict.txt" && u.Conflict != nil && u.Conflict.Type == models.ConflictCreateCreate {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Should have reported a conflict event for conflict.txt")
+	}
+}
@@ -0,0 +1,45 @@
+package sync
+
+import (
+	"context"
+
+	"github.com/sdejongh/syncnorris/pkg/compare"
+	"github.com/sdejongh/syncnorris/pkg/logging"
+	"github.com/sdejongh/syncnorris/pkg/models"
+)
+
+// describeDifferences attaches a unified diff (text files) or the first differing byte offset
+// (binary files) to every difference between files present on both sides
+func (p *Pipeline) describeDifferences(ctx context.Context, report *models.SyncReport) {
+	// --show-diff re-reads both files, so it honours the bandwidth limit like the comparison did
+	wrapper := readerWrapper(ctx, p.rateLimiter)
+
+	for i := range report.Differences {
+		diff := &report.Differences[i]
+		if diff.SourceInfo == nil || diff.DestInfo == nil {
+			continue
+		}
+		switch diff.Reason {
+		case models.ReasonContentDiff, models.ReasonHashDiff, models.ReasonSizeDiff:
+		default:
+			continue
+		}
+
+		description, err := compare.Describe(ctx, p.source, p.dest, diff.RelativePath, diff.RelativePath, compare.DefaultMaxDiffSize, p.operation.BufferSize, wrapper)
+		if err != nil {
+			if p.logger != nil {
+				p.logger.Warn(ctx, "Failed to describe difference", logging.Fields{
+					"path":  diff.RelativePath,
+					"error": err.Error(),
+				})
+			}
+			continue
+		}
+
+		diff.Diff = description.Diff
+		if description.Offset >= 0 {
+			offset := description.Offset
+			diff.DiffOffset = &offset
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package sync
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/compare"
+	"github.com/sdejongh/syncnorris/pkg/logging"
+	"github.com/sdejongh/syncnorris/pkg/models"
+	"github.com/sdejongh/syncnorris/pkg/output"
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+// syncDirectories creates missing destination directories, including empty ones, and applies
+// the source mode and modification time to every directory
+// Directories are processed deepest first once all files are written, links created and orphans
+// deleted, so no later change in a directory alters its modification time
+func (p *Pipeline) syncDirectories(ctx context.Context, report *models.SyncReport) {
+	dirs := make([]*storage.FileInfo, len(p.sourceDirs))
+	copy(dirs, p.sourceDirs)
+	sort.SliceStable(dirs, func(i, j int) bool {
+		return depth(dirs[i].RelativePath) > depth(dirs[j].RelativePath)
+	})
+
+	for _, dir := range dirs {
+		if ctx.Err() != nil {
+			return
+		}
+		p.processDirectory(ctx, dir, report)
+	}
+}
+
+// processDirectory creates a directory if needed and updates its metadata when it differs
+func (p *Pipeline) processDirectory(ctx context.Context, dir *storage.FileInfo, report *models.SyncReport) {
+	p.destFilesMu.RLock()
+	destInfo, existed := p.destDirs[dir.RelativePath]
+	p.destFilesMu.RUnlock()
+
+	var diffs []string
+	if existed {
+		// Writes and deletions during this run changed the modification time seen by the scan
+		if current, err := p.dest.Stat(ctx, dir.RelativePath); err == nil {
+			destInfo = current
+		}
+		if diffs = compare.MetadataDiff(dir, destInfo, p.tolerance); len(diffs) == 0 {
+			return
+		}
+		p.reportDecision(dir.RelativePath, models.ActionUpdateMetadata, "directory metadata differs ("+strings.Join(diffs, ", ")+")", 0)
+	} else {
+		p.reportDecision(dir.RelativePath, models.ActionCopy, "directory does not exist in destination", 0)
+	}
+
+	if !p.operation.DryRun {
+		start := time.Now()
+		err := p.applyDirectory(ctx, dir)
+		p.observePhase(PhaseTransfer, start)
+		if err != nil {
+			action := models.ActionCopy
+			if existed {
+				action = models.ActionUpdateMetadata
+			}
+			p.resultsMu.Lock()
+			report.Errors = append(report.Errors, models.SyncError{
+				FilePath:  dir.RelativePath,
+				Operation: action,
+				Error:     err.Error(),
+				Timestamp: time.Now(),
+			})
+			p.resultsMu.Unlock()
+
+			if p.logger != nil {
+				p.logger.Error(ctx, "Failed to synchronize directory", err, logging.Fields{
+					"path": dir.RelativePath,
+				})
+			}
+
+			if p.formatter != nil {
+				p.formatter.Progress(output.ProgressUpdate{
+					Type:     "file_error",
+					FilePath: dir.RelativePath,
+					Error:    err,
+				})
+			}
+			return
+		}
+	}
+
+	if existed {
+		report.Stats.DirsUpdated.Add(1)
+	} else {
+		report.Stats.DirsCreated.Add(1)
+	}
+
+	if p.logger != nil {
+		p.logger.Debug(ctx, "Directory synchronized", logging.Fields{
+			"path":     dir.RelativePath,
+			"created":  !existed,
+			"metadata": strings.Join(diffs, ", "),
+			"dry_run":  p.operation.DryRun,
+		})
+	}
+}
+
+// applyDirectory creates a destination directory and applies the source metadata to it
+// Metadata that cannot be preserved is logged as a warning
+func (p *Pipeline) applyDirectory(ctx context.Context, dir *storage.FileInfo) error {
+	if err := p.dest.MkdirAll(ctx, dir.RelativePath); err != nil {
+		return err
+	}
+
+	setter, ok := p.dest.(storage.MetadataSetter)
+	if !ok {
+		return nil
+	}
+	if err := setter.SetMetadata(ctx, dir.RelativePath, dir); err != nil && !p.metadataWarning(ctx, dir.RelativePath, err) {
+		return err
+	}
+	return nil
+}
+
+// depth returns the number of path elements below the root
+func depth(path string) int {
+	return strings.Count(filepath.ToSlash(path), "/")
+}
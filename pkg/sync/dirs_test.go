@@ -0,0 +1,84 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/compare"
+	"github.com/sdejongh/syncnorris/pkg/models"
+)
+
+func TestPipeline_Directories(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.CreateSourceFile("docs/guide/intro.txt", []byte("intro"))
+	if err := os.MkdirAll(filepath.Join(h.sourceDir, "empty", "nested"), 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	h.CreateDestFile("docs/stale.txt", []byte("stale"))
+
+	modTime := time.Now().Add(-72 * time.Hour).Truncate(time.Second)
+	modes := map[string]os.FileMode{
+		"docs":         0750,
+		"docs/guide":   0700,
+		"empty":        0755,
+		"empty/nested": 0711,
+	}
+	for dir, mode := range modes {
+		path := filepath.Join(h.sourceDir, dir)
+		if err := os.Chmod(path, mode); err != nil {
+			t.Fatalf("failed to chmod: %v", err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("failed to set times: %v", err)
+		}
+	}
+
+	op := h.NewOperation()
+	op.Mode = models.ModeOneWay
+	op.DeleteOrphans = true
+	run := func() *models.SyncReport {
+		pipeline := NewPipeline(h.source, h.dest, compare.NewHashComparator(4096), &nullFormatter{}, nil, op, PipelineConfig{MaxWorkers: 2, QueueSize: 100})
+		report, err := pipeline.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if len(report.Errors) != 0 {
+			t.Fatalf("errors = %v, want none", report.Errors)
+		}
+		return report
+	}
+
+	report := run()
+	if got := report.Stats.DirsCreated.Load(); got != 3 {
+		t.Errorf("DirsCreated = %d, want 3 (docs/guide, empty, empty/nested)", got)
+	}
+	if got := report.Stats.DirsUpdated.Load(); got != 1 {
+		t.Errorf("DirsUpdated = %d, want 1 (docs)", got)
+	}
+
+	for dir, mode := range modes {
+		info, err := os.Stat(filepath.Join(h.destDir, dir))
+		if err != nil {
+			t.Errorf("%s should exist in destination: %v", dir, err)
+			continue
+		}
+		if info.Mode().Perm() != mode {
+			t.Errorf("%s mode = %v, want %v", dir, info.Mode().Perm(), mode)
+		}
+		// Directory times are set after their contents are written or deleted
+		if !info.ModTime().Equal(modTime) {
+			t.Errorf("%s mtime = %v, want %v", dir, info.ModTime(), modTime)
+		}
+	}
+
+	// The second run finds the directories in place
+	report = run()
+	if created, updated := report.Stats.DirsCreated.Load(), report.Stats.DirsUpdated.Load(); created != 0 || updated != 0 {
+		t.Errorf("second run DirsCreated = %d, DirsUpdated = %d; want 0", created, updated)
+	}
+}
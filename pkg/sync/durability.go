@@ -0,0 +1,43 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/models"
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+// flushWrites makes the changes written to backends durable, for backends deferring part of it
+// (full durability fsyncs the changed directories once, at the end of the run)
+// Failures are recorded as report errors: written files may not survive a power loss
+func flushWrites(ctx context.Context, report *models.SyncReport, backends ...storage.Backend) []error {
+	var errs []error
+	for _, backend := range backends {
+		flusher, ok := backend.(storage.Flusher)
+		if !ok {
+			continue
+		}
+		if err := flusher.Flush(ctx); err != nil {
+			err = fmt.Errorf("failed to flush writes: %w", err)
+			errs = append(errs, err)
+			report.Errors = append(report.Errors, models.SyncError{
+				FilePath:  ".",
+				Operation: models.ActionCopy,
+				Error:     err.Error(),
+				Timestamp: time.Now(),
+			})
+		}
+	}
+	return errs
+}
+
+// stateDurability returns the durability level of the sync state file
+func stateDurability(operation *models.SyncOperation) storage.Durability {
+	durability, err := storage.ParseDurability(operation.Durability)
+	if err != nil {
+		return storage.DurabilityNone
+	}
+	return durability
+}
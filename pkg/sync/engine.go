@@ -3,6 +3,7 @@ package sync
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/sdejongh/syncnorris/pkg/compare"
 	"github.com/sdejongh/syncnorris/pkg/logging"
@@ -19,8 +20,26 @@ type Engine struct {
 	formatter  output.Formatter
 	logger     logging.Logger
 	operation  *models.SyncOperation
+	metrics    MetricsObserver
 }
 
+// MetricsObserver receives instrumentation events from the sync pipelines
+// Implemented by metrics.Collector
+type MetricsObserver interface {
+	// Track registers the report whose statistics are updated during the run
+	Track(report *models.SyncReport)
+	// ObservePhase records the duration of one unit of work in a phase
+	ObservePhase(phase string, d time.Duration)
+}
+
+// Phase names reported to the MetricsObserver
+const (
+	PhaseScan     = "scan"
+	PhaseCompare  = "compare"
+	PhaseTransfer = "transfer"
+	PhaseDelete   = "delete"
+)
+
 // NewEngine creates a new sync engine
 func NewEngine(
 	source, dest storage.Backend,
@@ -39,19 +58,31 @@ func NewEngine(
 	}
 }
 
+// SetMetricsObserver sets an optional observer for run statistics and phase durations
+func (e *Engine) SetMetricsObserver(observer MetricsObserver) {
+	e.metrics = observer
+}
+
 // Run executes the sync operation using the pipeline architecture
+// A run interrupted by cancelling ctx reports StatusCancelled
 func (e *Engine) Run(ctx context.Context) (*models.SyncReport, error) {
-	// Use the new pipeline-based approach for one-way sync
-	if e.operation.Mode == models.ModeOneWay {
-		return e.runPipeline(ctx)
-	}
+	var report *models.SyncReport
+	var err error
 
-	// Bidirectional sync
-	if e.operation.Mode == models.ModeBidirectional {
-		return e.runBidirectional(ctx)
+	switch e.operation.Mode {
+	case models.ModeOneWay:
+		// Use the new pipeline-based approach for one-way sync
+		report, err = e.runPipeline(ctx)
+	case models.ModeBidirectional:
+		report, err = e.runBidirectional(ctx)
+	default:
+		return nil, fmt.Errorf("unknown sync mode: %s", e.operation.Mode)
 	}
 
-	return nil, fmt.Errorf("unknown sync mode: %s", e.operation.Mode)
+	if report != nil && ctx.Err() != nil {
+		report.Status = models.StatusCancelled
+	}
+	return report, err
 }
 
 // runPipeline executes sync using the producer-consumer pipeline
@@ -70,12 +101,18 @@ func (e *Engine) runPipeline(ctx context.Context) (*models.SyncReport, error) {
 		e.operation,
 		config,
 	)
+	pipeline.metrics = e.metrics
 
 	return pipeline.Run(ctx)
 }
 
 // runBidirectional executes sync using the bidirectional pipeline
 func (e *Engine) runBidirectional(ctx context.Context) (*models.SyncReport, error) {
+	// Bidirectional sync processes files sequentially, there are no workers to adapt
+	if e.operation.Adaptive != nil && e.logger != nil {
+		e.logger.Warn(ctx, "Adaptive throttling is only supported in one-way mode", nil)
+	}
+
 	config := PipelineConfig{
 		MaxWorkers: e.operation.MaxWorkers,
 		QueueSize:  1000,
@@ -90,6 +127,7 @@ func (e *Engine) runBidirectional(ctx context.Context) (*models.SyncReport, erro
 		e.operation,
 		config,
 	)
+	pipeline.metrics = e.metrics
 
 	return pipeline.Run(ctx)
 }
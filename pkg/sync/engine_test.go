@@ -0,0 +1,58 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sdejongh/syncnorris/pkg/compare"
+	"github.com/sdejongh/syncnorris/pkg/models"
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+// unlistableBackend fails every listing, like an unmounted or unreachable destination
+type unlistableBackend struct {
+	*storage.Local
+}
+
+func (b *unlistableBackend) List(ctx context.Context, path string) ([]storage.FileInfo, error) {
+	return nil, errors.New("destination unreachable")
+}
+
+func TestEngine_RunStatus(t *testing.T) {
+	for _, mode := range []models.SyncMode{models.ModeOneWay, models.ModeBidirectional} {
+		t.Run(string(mode)+"/ScanFailure", func(t *testing.T) {
+			h := NewTestHelper(t)
+			defer h.Cleanup()
+			h.CreateSourceFile("file.txt", []byte("content"))
+
+			op := h.NewOperation()
+			op.Mode = mode
+			engine := NewEngine(h.source, &unlistableBackend{Local: h.dest}, compare.NewHashComparator(4096), &nullFormatter{}, nil, op)
+			report, err := engine.Run(context.Background())
+			if err == nil {
+				t.Fatal("Run() error = nil, want a scan error")
+			}
+			if report == nil || report.Status != models.StatusFailed {
+				t.Errorf("Run() report = %+v, want a failed report", report)
+			}
+		})
+
+		t.Run(string(mode)+"/Cancelled", func(t *testing.T) {
+			h := NewTestHelper(t)
+			defer h.Cleanup()
+			h.CreateSourceFile("file.txt", []byte("content"))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			op := h.NewOperation()
+			op.Mode = mode
+			engine := NewEngine(h.source, h.dest, compare.NewHashComparator(4096), &nullFormatter{}, nil, op)
+			report, _ := engine.Run(ctx)
+			if report == nil || report.Status != models.StatusCancelled {
+				t.Errorf("Run() report = %+v, want a cancelled report", report)
+			}
+		})
+	}
+}
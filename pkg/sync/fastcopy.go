@@ -0,0 +1,114 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/logging"
+	"github.com/sdejongh/syncnorris/pkg/models"
+	"github.com/sdejongh/syncnorris/pkg/output"
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+// fastCopy copies a file with a reflink clone or in the kernel when both backends support it
+// Returns false when the file must be streamed instead; the task is completed otherwise
+// Verified transfers and sparse files are always streamed, and the fast path is disabled for the
+// rest of the run once the backends turn out not to support it
+func (p *Pipeline) fastCopy(ctx context.Context, task *FileTask, report *models.SyncReport, fileIndex int, startTime time.Time, result TaskResult) bool {
+	copier, ok := p.dest.(storage.FastCopier)
+	if !ok || p.verifier != nil || p.fastCopyUnsupported.Load() {
+		return false
+	}
+	if task.SourceInfo != nil && task.SourceInfo.Sparse {
+		return false
+	}
+
+	sourceInfo, err := p.source.Stat(ctx, task.RelativePath)
+	if err != nil {
+		return false
+	}
+
+	// Like streamed writes, the adaptive controller samples the time taken by each copied chunk
+	transferStart := time.Now()
+	chunkStart := transferStart
+	hooks := storage.FastCopyHooks{
+		Progress: func(copied int64) {
+			if p.adaptive != nil {
+				p.adaptive.observeWrite(time.Since(chunkStart))
+				chunkStart = time.Now()
+			}
+			if p.formatter != nil {
+				p.formatter.Progress(output.ProgressUpdate{
+					Type:         "file_progress",
+					FilePath:     task.RelativePath,
+					BytesWritten: copied,
+					TotalBytes:   task.Size,
+					CurrentFile:  fileIndex,
+				})
+			}
+		},
+	}
+	if p.rateLimiter != nil {
+		hooks.Pace = func(n int) (int, error) {
+			return p.rateLimiter.Take(ctx, n)
+		}
+	}
+
+	method, err := copier.FastCopy(ctx, task.RelativePath, p.source, task.RelativePath, sourceInfo, hooks)
+	if errors.Is(err, storage.ErrFastCopyNotSupported) {
+		p.fastCopyUnsupported.Store(true)
+		if p.logger != nil {
+			p.logger.Debug(ctx, "Fast copy not supported, streaming files", logging.Fields{
+				"path": task.RelativePath,
+			})
+		}
+		return false
+	}
+	if err != nil && !p.metadataWarning(ctx, task.RelativePath, err) {
+		task.MarkError(err, time.Since(startTime))
+		report.Stats.FilesErrored.Add(1)
+		p.recordError(report, task)
+		p.addResult(task)
+
+		if p.logger != nil {
+			p.logger.Error(ctx, "Failed to copy file", err, logging.Fields{
+				"path": task.RelativePath,
+			})
+		}
+
+		if p.formatter != nil {
+			p.formatter.Progress(output.ProgressUpdate{
+				Type:        "file_error",
+				FilePath:    task.RelativePath,
+				CurrentFile: fileIndex,
+				Error:       err,
+			})
+		}
+		return true
+	}
+
+	task.MarkCompleted(result, task.Size, time.Since(startTime))
+	if result == ResultUpdated {
+		report.Stats.FilesUpdated.Add(1)
+	} else {
+		report.Stats.FilesCopied.Add(1)
+	}
+	report.Stats.FastCopies.Add(1)
+	report.Stats.BytesTransferred.Add(task.Size)
+	p.recordAllocation(ctx, task, sourceInfo, report)
+	p.processedBytes.Add(task.Size)
+	p.addResult(task)
+
+	if p.logger != nil {
+		p.logger.Debug(ctx, "File copied without streaming", logging.Fields{
+			"path":     task.RelativePath,
+			"size":     task.Size,
+			"method":   string(method),
+			"duration": time.Since(transferStart).String(),
+		})
+	}
+
+	p.completeFile(task, fileIndex)
+	return true
+}
@@ -0,0 +1,161 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/logging"
+	"github.com/sdejongh/syncnorris/pkg/models"
+	"github.com/sdejongh/syncnorris/pkg/output"
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+// hardLinkKey identifies a file by device and inode
+type hardLinkKey struct {
+	device uint64
+	inode  uint64
+}
+
+// hardLinkGroups tracks the source files sharing an inode during the scan
+type hardLinkGroups map[hardLinkKey]string // First path seen for each linked inode
+
+// primary returns the first scanned path sharing the file's inode, if another file was seen
+// The file is recorded as the primary of its inode when it is the first one
+// A nil group detects nothing (hard links are not preserved)
+func (g hardLinkGroups) primary(f *storage.FileInfo) (string, bool) {
+	if g == nil || f.Links < 2 || f.Inode == 0 || f.IsDir || f.IsSymlink {
+		return "", false
+	}
+
+	key := hardLinkKey{device: f.Device, inode: f.Inode}
+	if first, ok := g[key]; ok {
+		return first, true
+	}
+	g[key] = f.RelativePath
+	return "", false
+}
+
+// sameFile reports whether two entries are hard links to the same file
+func sameFile(a, b *storage.FileInfo) bool {
+	return a.Inode != 0 && a.Inode == b.Inode && a.Device == b.Device
+}
+
+// linkHardLinks recreates the deferred hard links once the files they point to are copied
+func (p *Pipeline) linkHardLinks(ctx context.Context, report *models.SyncReport) {
+	if len(p.hardLinks) > 0 && p.logger != nil {
+		p.logger.Info(ctx, "Recreating hard links", logging.Fields{
+			"count": len(p.hardLinks),
+		})
+	}
+
+	for _, task := range p.hardLinks {
+		if ctx.Err() != nil {
+			return
+		}
+		p.processHardLink(ctx, task, report)
+	}
+}
+
+// processHardLink links a file to the destination copy of its primary
+// The file is copied instead when the link cannot be created
+func (p *Pipeline) processHardLink(ctx context.Context, task *FileTask, report *models.SyncReport) {
+	startTime := time.Now()
+	task.MarkProcessing(0)
+	fileIndex := int(p.processedFiles.Add(1))
+
+	p.destFilesMu.RLock()
+	destInfo, destExists := p.destFiles[task.RelativePath]
+	p.destFilesMu.RUnlock()
+
+	primary, primaryErr := p.dest.Stat(ctx, task.HardLinkTo)
+	if primaryErr == nil && destExists && sameFile(primary, destInfo) {
+		// Destination already links both paths
+		task.MarkCompleted(ResultSynchronized, 0, time.Since(startTime))
+		report.Stats.FilesSynchronized.Add(1)
+		report.Stats.HardLinks.Add(1)
+		p.processedBytes.Add(task.Size)
+		p.addResult(task)
+		p.reportDecision(task.RelativePath, models.ActionSkip, "hard link already in place", task.Size)
+		p.completeFile(task, fileIndex)
+		return
+	}
+
+	result, action := ResultCopied, models.ActionCopy
+	if destExists {
+		result, action = ResultUpdated, models.ActionUpdate
+	}
+	p.reportDecision(task.RelativePath, action, fmt.Sprintf("hard link to %s", task.HardLinkTo), task.Size)
+	if p.formatter != nil {
+		p.formatter.Progress(output.ProgressUpdate{
+			Type:        "file_start",
+			FilePath:    task.RelativePath,
+			TotalBytes:  task.Size,
+			CurrentFile: fileIndex,
+		})
+	}
+
+	if !p.operation.DryRun {
+		var err error
+		linker, ok := p.dest.(storage.HardLinker)
+		switch {
+		case primaryErr != nil:
+			// The primary was not copied: there is nothing to link to
+			err = primaryErr
+		case !ok:
+			err = storage.ErrHardLinksNotSupported
+		default:
+			err = linker.Link(ctx, task.RelativePath, task.HardLinkTo)
+		}
+
+		if err != nil {
+			if p.logger != nil {
+				p.logger.Warn(ctx, "Failed to create hard link, copying file instead", logging.Fields{
+					"path":  task.RelativePath,
+					"link":  task.HardLinkTo,
+					"error": err.Error(),
+				})
+			}
+			task.HardLinkTo = ""
+			if destExists {
+				p.updateFile(ctx, 0, task, report, fileIndex, startTime)
+			} else {
+				p.copyFile(ctx, 0, task, report, fileIndex, startTime)
+			}
+			return
+		}
+	}
+
+	task.MarkCompleted(result, 0, time.Since(startTime))
+	if result == ResultUpdated {
+		report.Stats.FilesUpdated.Add(1)
+	} else {
+		report.Stats.FilesCopied.Add(1)
+	}
+	report.Stats.HardLinks.Add(1)
+	p.processedBytes.Add(task.Size)
+	p.addResult(task)
+
+	if p.logger != nil {
+		p.logger.Debug(ctx, "Hard link created", logging.Fields{
+			"path":    task.RelativePath,
+			"link":    task.HardLinkTo,
+			"dry_run": p.operation.DryRun,
+		})
+	}
+
+	p.completeFile(task, fileIndex)
+}
+
+// completeFile notifies the formatter that a file is done
+func (p *Pipeline) completeFile(task *FileTask, fileIndex int) {
+	if p.formatter != nil {
+		p.formatter.Progress(output.ProgressUpdate{
+			Type:         "file_complete",
+			FilePath:     task.RelativePath,
+			BytesWritten: task.Size,
+			TotalBytes:   task.Size,
+			CurrentFile:  fileIndex,
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sdejongh/syncnorris/pkg/compare"
+	"github.com/sdejongh/syncnorris/pkg/models"
+)
+
+// sameDestFile reports whether two destination paths are hard links to the same file
+func sameDestFile(t *testing.T, h *TestHelper, a, b string) bool {
+	t.Helper()
+	infoA, errA := os.Stat(filepath.Join(h.destDir, a))
+	infoB, errB := os.Stat(filepath.Join(h.destDir, b))
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
+
+func TestPipeline_HardLinks(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.CreateSourceFile("snapshots/daily.0/data.bin", []byte("snapshot content"))
+	for _, name := range []string{"snapshots/daily.1/data.bin", "snapshots/daily.2/data.bin"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(h.sourceDir, name)), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.Link(filepath.Join(h.sourceDir, "snapshots/daily.0/data.bin"), filepath.Join(h.sourceDir, name)); err != nil {
+			t.Skipf("Hard links not supported on this system: %v", err)
+		}
+	}
+	h.CreateSourceFile("other.txt", []byte("not linked"))
+
+	op := h.NewOperation()
+	op.Mode = models.ModeOneWay
+	op.HardLinks = true
+
+	run := func() *models.SyncReport {
+		pipeline := NewPipeline(h.source, h.dest, compare.NewHashComparator(4096), &nullFormatter{}, nil, op, PipelineConfig{MaxWorkers: 2, QueueSize: 100})
+		report, err := pipeline.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if report.Stats.FilesErrored.Load() != 0 {
+			t.Fatalf("errors = %d, want 0", report.Stats.FilesErrored.Load())
+		}
+		return report
+	}
+
+	report := run()
+	if got := report.Stats.FilesCopied.Load(); got != 4 {
+		t.Errorf("FilesCopied = %d, want 4", got)
+	}
+	if got := report.Stats.HardLinks.Load(); got != 2 {
+		t.Errorf("HardLinks = %d, want 2", got)
+	}
+	if got := report.Stats.BytesTransferred.Load(); got != int64(len("snapshot content")+len("not linked")) {
+		t.Errorf("BytesTransferred = %d, want the linked content transferred once", got)
+	}
+	for _, name := range []string{"snapshots/daily.1/data.bin", "snapshots/daily.2/data.bin"} {
+		if !sameDestFile(t, h, "snapshots/daily.0/data.bin", name) {
+			t.Errorf("%s should be a hard link in destination", name)
+		}
+	}
+
+	// A second run finds the links in place
+	report = run()
+	if got := report.Stats.FilesSynchronized.Load(); got != 4 {
+		t.Errorf("second run FilesSynchronized = %d, want 4", got)
+	}
+	if got := report.Stats.HardLinks.Load(); got != 2 {
+		t.Errorf("second run HardLinks = %d, want 2", got)
+	}
+}
+
+func TestPipeline_HardLinksDisabled(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.CreateSourceFile("a.txt", []byte("content"))
+	if err := os.Link(filepath.Join(h.sourceDir, "a.txt"), filepath.Join(h.sourceDir, "b.txt")); err != nil {
+		t.Skipf("Hard links not supported on this system: %v", err)
+	}
+
+	op := h.NewOperation()
+	op.Mode = models.ModeOneWay
+	pipeline := NewPipeline(h.source, h.dest, compare.NewHashComparator(4096), &nullFormatter{}, nil, op, PipelineConfig{MaxWorkers: 2, QueueSize: 100})
+	report, err := pipeline.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if report.Stats.HardLinks.Load() != 0 || sameDestFile(t, h, "a.txt", "b.txt") {
+		t.Error("files should be copied separately when hard links are not preserved")
+	}
+}
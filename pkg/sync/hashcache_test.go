@@ -0,0 +1,70 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/compare"
+	"github.com/sdejongh/syncnorris/pkg/hashcache"
+	"github.com/sdejongh/syncnorris/pkg/models"
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+// TestPipeline_HashCacheRefreshedOnOverwrite tests that overwriting a file drops its cached hash
+// The overwrite keeps the inode, size and modification time, so a stale entry would still match
+func TestPipeline_HashCacheRefreshedOnOverwrite(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	for i, backend := range []*storage.Local{h.source, h.dest} {
+		cache, err := hashcache.OpenDB(filepath.Join(h.tempDir, fmt.Sprintf("cache-%d.db", i)), backend.RootPath())
+		if err != nil {
+			t.Fatalf("failed to open hash cache: %v", err)
+		}
+		defer cache.Close()
+		backend.SetHashCache(cache)
+	}
+
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	h.CreateSourceFile("data.bin", []byte("version 1"))
+	h.SetFileModTime(true, "data.bin", modTime)
+
+	op := h.NewOperation()
+	op.Mode = models.ModeOneWay
+	run := func() *models.SyncReport {
+		pipeline := NewPipeline(h.source, h.dest, compare.NewHashComparator(4096), &nullFormatter{}, nil, op, PipelineConfig{MaxWorkers: 2, QueueSize: 100})
+		report, err := pipeline.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		return report
+	}
+
+	// Copy, then compare once so both sides have a cached hash
+	run()
+	if report := run(); report.Stats.FilesSynchronized.Load() != 1 {
+		t.Fatalf("FilesSynchronized = %d, want 1", report.Stats.FilesSynchronized.Load())
+	}
+
+	// Same size and modification time, different content, saved as a new file like editors do
+	h.CreateSourceFile("data.bin.tmp", []byte("version 2"))
+	h.SetFileModTime(true, "data.bin.tmp", modTime)
+	if err := os.Rename(filepath.Join(h.sourceDir, "data.bin.tmp"), filepath.Join(h.sourceDir, "data.bin")); err != nil {
+		t.Fatalf("failed to replace source file: %v", err)
+	}
+	if report := run(); report.Stats.FilesUpdated.Load() != 1 {
+		t.Fatalf("FilesUpdated = %d, want 1", report.Stats.FilesUpdated.Load())
+	}
+
+	report := run()
+	if got := report.Stats.FilesSynchronized.Load(); got != 1 {
+		t.Errorf("FilesSynchronized = %d, want 1 after the update", got)
+	}
+	if got := report.Stats.FilesUpdated.Load(); got != 0 {
+		t.Errorf("FilesUpdated = %d, want 0 after the update", got)
+	}
+}
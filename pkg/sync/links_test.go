@@ -0,0 +1,76 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sdejongh/syncnorris/pkg/compare"
+	"github.com/sdejongh/syncnorris/pkg/models"
+)
+
+func TestPipeline_PreservesSymlinks(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.CreateSourceFile("real.txt", []byte("real content"))
+	if err := os.Symlink("real.txt", filepath.Join(h.sourceDir, "new-link")); err != nil {
+		t.Skipf("Symlinks not supported on this system: %v", err)
+	}
+	if err := os.Symlink("real.txt", filepath.Join(h.sourceDir, "changed-link")); err != nil {
+		t.Fatalf("failed to create link: %v", err)
+	}
+	if err := os.Symlink("real.txt", filepath.Join(h.sourceDir, "same-link")); err != nil {
+		t.Fatalf("failed to create link: %v", err)
+	}
+	if err := os.Symlink("real.txt", filepath.Join(h.sourceDir, "was-file")); err != nil {
+		t.Fatalf("failed to create link: %v", err)
+	}
+
+	// Destination: a link to another target, an identical link and a regular file
+	// replaced by a source link
+	h.CreateDestFile("other.txt", []byte("other"))
+	h.CreateDestFile("was-file", []byte("regular"))
+	if err := os.Symlink("other.txt", filepath.Join(h.destDir, "changed-link")); err != nil {
+		t.Fatalf("failed to create link: %v", err)
+	}
+	if err := os.Symlink("real.txt", filepath.Join(h.destDir, "same-link")); err != nil {
+		t.Fatalf("failed to create link: %v", err)
+	}
+
+	op := h.NewOperation()
+	op.Mode = models.ModeOneWay
+	pipeline := NewPipeline(h.source, h.dest, compare.NewHashComparator(4096), &nullFormatter{}, nil, op, PipelineConfig{MaxWorkers: 2, QueueSize: 100})
+	report, err := pipeline.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if report.Stats.FilesErrored.Load() != 0 {
+		t.Fatalf("errors = %d, want 0", report.Stats.FilesErrored.Load())
+	}
+	if got := report.Stats.FilesCopied.Load(); got != 2 {
+		t.Errorf("FilesCopied = %d, want 2 (real.txt, new-link)", got)
+	}
+	if got := report.Stats.FilesUpdated.Load(); got != 2 {
+		t.Errorf("FilesUpdated = %d, want 2 (changed-link, was-file)", got)
+	}
+	if got := report.Stats.FilesSynchronized.Load(); got != 1 {
+		t.Errorf("FilesSynchronized = %d, want 1 (same-link)", got)
+	}
+
+	for _, name := range []string{"new-link", "changed-link", "same-link", "was-file"} {
+		target, err := os.Readlink(filepath.Join(h.destDir, name))
+		if err != nil {
+			t.Errorf("%s should be a link in destination: %v", name, err)
+			continue
+		}
+		if target != "real.txt" {
+			t.Errorf("%s target = %q, want real.txt", name, target)
+		}
+	}
+	if data, _ := h.ReadDestFile("other.txt"); string(data) != "other" {
+		t.Errorf("previous link target content = %q, want it unchanged", data)
+	}
+}
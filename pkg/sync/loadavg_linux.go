@@ -0,0 +1,26 @@
+//go:build linux
+
+package sync
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// loadAverage returns the host's one-minute load average
+func loadAverage() (float64, bool) {
+	data, err := os.ReadFile("/proc/loadavg")
+	if err != nil {
+		return 0, false
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0, false
+	}
+	load, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, false
+	}
+	return load, true
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package sync
+
+// loadAverage returns the host's one-minute load average
+// The load average is not read on this platform, so adaptive throttling ignores it
+func loadAverage() (float64, bool) {
+	return 0, false
+}
@@ -0,0 +1,102 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/logging"
+	"github.com/sdejongh/syncnorris/pkg/models"
+	"github.com/sdejongh/syncnorris/pkg/output"
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+// metadataWarning logs metadata that could not be preserved on a written file
+// Returns false when err is not a metadata error and must be handled as a failure
+func (p *Pipeline) metadataWarning(ctx context.Context, path string, err error) bool {
+	var metadataErr *storage.MetadataError
+	if !errors.As(err, &metadataErr) {
+		return false
+	}
+
+	if p.logger != nil {
+		p.logger.Warn(ctx, "Failed to preserve metadata", logging.Fields{
+			"path":  path,
+			"error": metadataErr.Err.Error(),
+		})
+	}
+	return true
+}
+
+// updateMetadata applies the source metadata to a destination file whose content is identical
+// The file is copied instead when the destination cannot update metadata alone
+func (p *Pipeline) updateMetadata(ctx context.Context, workerID int, task *FileTask, report *models.SyncReport, fileIndex int, startTime time.Time, reason string) {
+	setter, ok := p.dest.(storage.MetadataSetter)
+	if !ok {
+		p.reportDecision(task.RelativePath, models.ActionUpdate, reason, task.Size)
+		p.updateFile(ctx, workerID, task, report, fileIndex, startTime)
+		return
+	}
+	p.reportDecision(task.RelativePath, models.ActionUpdateMetadata, reason, task.Size)
+
+	if p.logger != nil {
+		p.logger.Debug(ctx, "Updating file metadata", logging.Fields{
+			"path":    task.RelativePath,
+			"reason":  reason,
+			"dry_run": p.operation.DryRun,
+		})
+	}
+
+	if !p.operation.DryRun {
+		err := setter.SetMetadata(ctx, task.RelativePath, task.SourceInfo)
+		if errors.Is(err, storage.ErrMetadataNotSupported) {
+			p.updateFile(ctx, workerID, task, report, fileIndex, startTime)
+			return
+		}
+		if err != nil && !p.metadataWarning(ctx, task.RelativePath, err) {
+			task.MarkError(err, time.Since(startTime))
+			report.Stats.FilesErrored.Add(1)
+			p.recordError(report, task)
+			p.addResult(task)
+
+			if p.logger != nil {
+				p.logger.Error(ctx, "Failed to update file metadata", err, logging.Fields{
+					"path": task.RelativePath,
+				})
+			}
+
+			if p.formatter != nil {
+				p.formatter.Progress(output.ProgressUpdate{
+					Type:        "file_error",
+					FilePath:    task.RelativePath,
+					CurrentFile: fileIndex,
+					Error:       err,
+				})
+			}
+			return
+		}
+	}
+
+	task.MarkCompleted(ResultMetadataUpdated, 0, time.Since(startTime))
+	report.Stats.MetadataUpdated.Add(1)
+	p.processedBytes.Add(task.Size)
+	p.addResult(task)
+	p.completeFile(task, fileIndex)
+}
+
+// linkOwner sets the ownership of a recreated symbolic link when owners are preserved
+func (p *Pipeline) linkOwner(ctx context.Context, task *FileTask) {
+	if task.SourceInfo == nil || task.SourceInfo.Owner == nil {
+		return
+	}
+	setter, ok := p.dest.(storage.MetadataSetter)
+	if !ok {
+		return
+	}
+	if err := setter.SetMetadata(ctx, task.RelativePath, task.SourceInfo); err != nil && !p.metadataWarning(ctx, task.RelativePath, err) && p.logger != nil {
+		p.logger.Warn(ctx, "Failed to preserve symbolic link owner", logging.Fields{
+			"path":  task.RelativePath,
+			"error": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/compare"
+	"github.com/sdejongh/syncnorris/pkg/models"
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+func TestPipeline_MetadataOnlyUpdate(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.CreateSourceFile("tool", []byte("binary"))
+	h.CreateDestFile("tool", []byte("binary"))
+	if err := os.Chmod(filepath.Join(h.sourceDir, "tool"), 0755|os.ModeSetuid); err != nil {
+		t.Skipf("setuid bit not supported: %v", err)
+	}
+	// A marker proves the destination content is not rewritten
+	marker := filepath.Join(h.destDir, "tool")
+	destBefore, err := os.Stat(marker)
+	if err != nil {
+		t.Fatalf("failed to stat destination: %v", err)
+	}
+
+	for _, backend := range []*storage.Local{h.source, h.dest} {
+		backend.SetMetadataOptions(storage.MetadataOptions{SpecialBits: true})
+	}
+
+	op := h.NewOperation()
+	op.Mode = models.ModeOneWay
+	run := func() *models.SyncReport {
+		pipeline := NewPipeline(h.source, h.dest, compare.NewHashComparator(4096), &nullFormatter{}, nil, op, PipelineConfig{MaxWorkers: 2, QueueSize: 100})
+		report, err := pipeline.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if report.Stats.FilesErrored.Load() != 0 {
+			t.Fatalf("errors = %d, want 0", report.Stats.FilesErrored.Load())
+		}
+		return report
+	}
+
+	report := run()
+	if got := report.Stats.MetadataUpdated.Load(); got != 1 {
+		t.Errorf("MetadataUpdated = %d, want 1", got)
+	}
+	if got := report.Stats.BytesTransferred.Load(); got != 0 {
+		t.Errorf("BytesTransferred = %d, want 0 for a metadata-only update", got)
+	}
+
+	destAfter, err := os.Stat(marker)
+	if err != nil {
+		t.Fatalf("failed to stat destination: %v", err)
+	}
+	if destAfter.Mode()&os.ModeSetuid == 0 {
+		t.Error("destination should have the setuid bit")
+	}
+	if !os.SameFile(destBefore, destAfter) {
+		t.Error("destination file should be updated in place")
+	}
+
+	// The second run finds nothing to do
+	report = run()
+	if got := report.Stats.FilesSynchronized.Load(); got != 1 {
+		t.Errorf("second run FilesSynchronized = %d, want 1", got)
+	}
+}
+
+func TestPipeline_PermissionsAndModTimeUpdate(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.CreateSourceFile("script.sh", []byte("#!/bin/sh"))
+	h.CreateDestFile("script.sh", []byte("#!/bin/sh"))
+	h.CreateSourceFile("same.txt", []byte("same"))
+	h.CreateDestFile("same.txt", []byte("same"))
+
+	sourcePath := filepath.Join(h.sourceDir, "script.sh")
+	destPath := filepath.Join(h.destDir, "script.sh")
+	modTime := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+	if err := os.Chmod(sourcePath, 0750); err != nil {
+		t.Fatalf("failed to chmod: %v", err)
+	}
+	if err := os.Chtimes(sourcePath, modTime, modTime); err != nil {
+		t.Fatalf("failed to set times: %v", err)
+	}
+	if err := os.Chmod(destPath, 0644); err != nil {
+		t.Fatalf("failed to chmod: %v", err)
+	}
+
+	op := h.NewOperation()
+	op.Mode = models.ModeOneWay
+	pipeline := NewPipeline(h.source, h.dest, compare.NewHashComparator(4096), &nullFormatter{}, nil, op, PipelineConfig{MaxWorkers: 2, QueueSize: 100})
+	report, err := pipeline.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if got := report.Stats.MetadataUpdated.Load(); got != 1 {
+		t.Errorf("MetadataUpdated = %d, want 1", got)
+	}
+	if got := report.Stats.FilesUpdated.Load(); got != 0 {
+		t.Errorf("FilesUpdated = %d, want 0", got)
+	}
+	if got := report.Stats.FilesSynchronized.Load(); got != 1 {
+		t.Errorf("FilesSynchronized = %d, want 1", got)
+	}
+	if got := report.Stats.BytesTransferred.Load(); got != 0 {
+		t.Errorf("BytesTransferred = %d, want 0", got)
+	}
+
+	info, err := os.Stat(destPath)
+	if err != nil {
+		t.Fatalf("failed to stat destination: %v", err)
+	}
+	if info.Mode().Perm() != 0750 {
+		t.Errorf("destination mode = %v, want 0750", info.Mode().Perm())
+	}
+	if !info.ModTime().Equal(modTime) {
+		t.Errorf("destination mtime = %v, want %v", info.ModTime(), modTime)
+	}
+
+	var found bool
+	for _, diff := range report.Differences {
+		if diff.RelativePath == "script.sh" {
+			found = diff.Reason == models.ReasonMetadataDiff && diff.Details == "metadata differs (permissions, mtime)"
+		}
+	}
+	if !found {
+		t.Errorf("Differences = %+v, want a metadata difference for script.sh", report.Differences)
+	}
+}
+
+func TestPipeline_UnverifiedContentNotStamped(t *testing.T) {
+	for _, comparator := range []compare.Comparator{compare.NewTimestampComparator(), compare.NewNameSizeComparator()} {
+		t.Run(comparator.Name(), func(t *testing.T) {
+			h := NewTestHelper(t)
+			defer h.Cleanup()
+
+			// Same size, different content, and the destination was edited after the source
+			h.CreateSourceFile("notes.txt", []byte("source"))
+			h.CreateDestFile("notes.txt", []byte("edited"))
+
+			sourcePath := filepath.Join(h.sourceDir, "notes.txt")
+			destPath := filepath.Join(h.destDir, "notes.txt")
+			sourceTime := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+			destTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+			if err := os.Chtimes(sourcePath, sourceTime, sourceTime); err != nil {
+				t.Fatalf("failed to set times: %v", err)
+			}
+			if err := os.Chtimes(destPath, destTime, destTime); err != nil {
+				t.Fatalf("failed to set times: %v", err)
+			}
+			if err := os.Chmod(sourcePath, 0600); err != nil {
+				t.Fatalf("failed to chmod: %v", err)
+			}
+			if err := os.Chmod(destPath, 0644); err != nil {
+				t.Fatalf("failed to chmod: %v", err)
+			}
+
+			op := h.NewOperation()
+			op.Mode = models.ModeOneWay
+			pipeline := NewPipeline(h.source, h.dest, comparator, &nullFormatter{}, nil, op, PipelineConfig{MaxWorkers: 2, QueueSize: 100})
+			report, err := pipeline.Run(context.Background())
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			if got := report.Stats.MetadataUpdated.Load(); got != 0 {
+				t.Errorf("MetadataUpdated = %d, want 0", got)
+			}
+
+			info, err := os.Stat(destPath)
+			if err != nil {
+				t.Fatalf("failed to stat destination: %v", err)
+			}
+			if !info.ModTime().Equal(destTime) || info.Mode().Perm() != 0644 {
+				t.Errorf("destination mtime = %v, mode = %v; want %v, 0644 (untouched)", info.ModTime(), info.Mode().Perm(), destTime)
+			}
+			if data, _ := os.ReadFile(destPath); string(data) != "edited" {
+				t.Errorf("destination content = %q, want %q", data, "edited")
+			}
+		})
+	}
+}
+
+func TestPipeline_TouchedFileGetsMetadataUpdate(t *testing.T) {
+	for _, comparator := range []compare.Comparator{compare.NewTimestampComparator(), compare.NewNameSizeComparator()} {
+		t.Run(comparator.Name(), func(t *testing.T) {
+			h := NewTestHelper(t)
+			defer h.Cleanup()
+
+			// Same content, the source was touched after the last sync
+			h.CreateSourceFile("report.pdf", []byte("unchanged content"))
+			h.CreateDestFile("report.pdf", []byte("unchanged content"))
+			destPath := filepath.Join(h.destDir, "report.pdf")
+			sourceTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+			destTime := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+			h.SetFileModTime(true, "report.pdf", sourceTime)
+			h.SetFileModTime(false, "report.pdf", destTime)
+			destBefore, err := os.Stat(destPath)
+			if err != nil {
+				t.Fatalf("failed to stat destination: %v", err)
+			}
+
+			op := h.NewOperation()
+			op.Mode = models.ModeOneWay
+			pipeline := NewPipeline(h.source, h.dest, comparator, &nullFormatter{}, nil, op, PipelineConfig{MaxWorkers: 2, QueueSize: 100})
+			report, err := pipeline.Run(context.Background())
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			if got := report.Stats.MetadataUpdated.Load(); got != 1 {
+				t.Errorf("MetadataUpdated = %d, want 1", got)
+			}
+			if got := report.Stats.BytesTransferred.Load(); got != 0 {
+				t.Errorf("BytesTransferred = %d, want 0", got)
+			}
+			if len(report.Operations) != 1 || report.Operations[0].Action != models.ActionUpdateMetadata {
+				t.Errorf("operations = %+v, want one %s", report.Operations, models.ActionUpdateMetadata)
+			}
+
+			destAfter, err := os.Stat(destPath)
+			if err != nil {
+				t.Fatalf("failed to stat destination: %v", err)
+			}
+			if !destAfter.ModTime().Equal(sourceTime) {
+				t.Errorf("destination mtime = %v, want %v", destAfter.ModTime(), sourceTime)
+			}
+			if !os.SameFile(destBefore, destAfter) {
+				t.Error("destination file should be updated in place")
+			}
+		})
+	}
+}
@@ -53,6 +53,30 @@ type Pipeline struct {
 
 	// Rate limiter for bandwidth limiting (nil = unlimited)
 	rateLimiter *ratelimit.Limiter
+
+	// Independent read, write and operation limits applied to the backends
+	throttles backendThrottles
+
+	// Adaptive worker and bandwidth controller (nil = fixed)
+	adaptive *adaptiveController
+
+	// Optional metrics observer (nil = disabled)
+	metrics MetricsObserver
+
+	// Post-copy verification (nil = disabled)
+	verifier *transferVerifier
+
+	// Source files sharing an inode with a file queued before, linked after the workers finish
+	hardLinks []*FileTask
+
+	// Modification time tolerance used to detect metadata-only differences
+	tolerance compare.TimeTolerance
+
+	// Source directories, created and updated after their contents
+	sourceDirs []*storage.FileInfo
+
+	// Set once the backends turn out not to support reflink or in-kernel copies
+	fastCopyUnsupported atomic.Bool
 }
 
 // PipelineConfig holds configuration for the pipeline
@@ -85,13 +109,33 @@ func NewPipeline(
 		config.QueueSize = 100
 	}
 
-	// Create rate limiter if bandwidth limit is set
-	var rateLimiter *ratelimit.Limiter
-	if operation.BandwidthLimit > 0 {
-		rateLimiter = ratelimit.NewLimiter(operation.BandwidthLimit)
+	// Create rate limiter if a bandwidth limit or schedule is set
+	rateLimiter := newRateLimiter(operation)
+
+	// Apply the independent read, write and operation limits to the backends
+	throttles := newBackendThrottles(operation)
+	source, dest = throttles.wrap(source, dest)
+
+	// Adaptive throttling adjusts the active workers, and the bandwidth limit if bounds are set
+	var adaptive *adaptiveController
+	if operation.Adaptive != nil {
+		var adaptedLimiter *ratelimit.Limiter
+		if operation.Adaptive.MaxBandwidth > 0 {
+			bandwidth := operation.Adaptive.MaxBandwidth
+			if operation.BandwidthLimit > 0 && operation.BandwidthLimit < bandwidth {
+				bandwidth = operation.BandwidthLimit
+			}
+			if rateLimiter == nil {
+				rateLimiter = ratelimit.NewLimiter(bandwidth)
+			} else {
+				rateLimiter.SetRate(bandwidth)
+			}
+			adaptedLimiter = rateLimiter
+		}
+		adaptive = newAdaptiveController(*operation.Adaptive, config.MaxWorkers, adaptedLimiter, logger)
 	}
 
-	return &Pipeline{
+	p := &Pipeline{
 		source:      source,
 		dest:        dest,
 		comparator:  comparator,
@@ -105,7 +149,21 @@ func NewPipeline(
 		activeFiles: make(map[string]int),
 		results:     make([]*FileTask, 0),
 		rateLimiter: rateLimiter,
+		throttles:   throttles,
+		adaptive:    adaptive,
+		tolerance:   TimeTolerance(operation),
+	}
+
+	if operation.Verify {
+		p.verifier = &transferVerifier{
+			source:  source,
+			dest:    dest,
+			retries: operation.VerifyRetries,
+			wrap:    p.limitReader,
+		}
 	}
+
+	return p
 }
 
 // Run executes the pipeline and returns a sync report
@@ -121,6 +179,10 @@ func (p *Pipeline) Run(ctx context.Context) (*models.SyncReport, error) {
 		Status:      models.StatusSuccess,
 	}
 
+	if p.metrics != nil {
+		p.metrics.Track(report)
+	}
+
 	if p.logger != nil {
 		p.logger.Info(ctx, "Starting pipeline sync operation", logging.Fields{
 			"operation_id": p.operation.ID,
@@ -138,9 +200,12 @@ func (p *Pipeline) Run(ctx context.Context) (*models.SyncReport, error) {
 	if p.logger != nil {
 		p.logger.Info(ctx, "Scanning destination directory", nil)
 	}
+	scanStart := time.Now()
 	if err := p.scanDestination(ctx); err != nil {
-		return nil, err
+		report.Status = models.StatusFailed
+		return report, err
 	}
+	p.observePhase(PhaseScan, scanStart)
 
 	report.Stats.DestFilesScanned.Store(int32(len(p.destFiles)))
 
@@ -181,12 +246,28 @@ func (p *Pipeline) Run(ctx context.Context) (*models.SyncReport, error) {
 	if workerCount < 1 {
 		workerCount = 5
 	}
+	if p.adaptive != nil {
+		// Start enough workers for the upper bound, the controller decides how many are active
+		workerCount = p.adaptive.settings.MaxWorkers
+	}
 
 	for i := 0; i < workerCount; i++ {
 		workersWg.Add(1)
 		go p.runWorker(ctx, i, report, &workersWg)
 	}
 
+	if p.adaptive != nil {
+		if p.logger != nil {
+			p.logger.Info(ctx, "Adaptive throttling enabled", logging.Fields{
+				"workers":       p.adaptive.gate.Limit(),
+				"min_workers":   p.adaptive.settings.MinWorkers,
+				"max_workers":   p.adaptive.settings.MaxWorkers,
+				"max_bandwidth": p.adaptive.settings.MaxBandwidth,
+			})
+		}
+		go p.adaptive.run(ctx)
+	}
+
 	// Phase 4: Scan source and populate queue (producer)
 	if p.logger != nil {
 		p.logger.Info(ctx, "Scanning source directory and populating queue", nil)
@@ -196,8 +277,12 @@ func (p *Pipeline) Run(ctx context.Context) (*models.SyncReport, error) {
 	if p.formatter != nil {
 		p.formatter.Start(nil, 0, 0, workerCount)
 	}
+	reportRateLimit(p.rateLimiter, p.formatter)
+	p.throttles.report(p.formatter)
 
+	scanStart = time.Now()
 	scanErr := p.scanSourceAndQueue(ctx, report)
+	p.observePhase(PhaseScan, scanStart)
 
 	// Signal that scanning is complete
 	p.scanComplete.Store(true)
@@ -211,13 +296,31 @@ func (p *Pipeline) Run(ctx context.Context) (*models.SyncReport, error) {
 		return report, scanErr
 	}
 
-	// Phase 5: Delete orphan files if requested
+	// Phase 5: Recreate hard links now that the files they point to are copied
+	p.linkHardLinks(ctx, report)
+
+	// Phase 6: Delete orphan files if requested
 	if p.operation.DeleteOrphans {
 		p.deleteOrphanFiles(ctx, report)
 	}
 
-	// Phase 6: Collect results and build report
+	// Phase 7: Create directories and apply their metadata, deepest first
+	p.syncDirectories(ctx, report)
+
+	// Phase 8: Make the destination changes durable
+	if !p.operation.DryRun {
+		for _, err := range flushWrites(ctx, report, p.dest) {
+			if p.logger != nil {
+				p.logger.Error(ctx, "Failed to flush destination", err, nil)
+			}
+		}
+	}
+
+	// Phase 9: Collect results and build report
 	p.buildReport(report)
+	if p.operation.ShowDiff {
+		p.describeDifferences(ctx, report)
+	}
 
 	// Finalize report timing
 	report.EndTime = time.Now()
@@ -287,15 +390,24 @@ func (p *Pipeline) scanSourceAndQueue(ctx context.Context, report *models.SyncRe
 		return err
 	}
 
+	var links hardLinkGroups
+	if p.operation.HardLinks {
+		links = make(hardLinkGroups)
+	}
+
 	for _, f := range sourceFiles {
-		// Skip directories
+		// Directories are synchronized once their contents are written
 		if f.IsDir {
+			if f.RelativePath != "." && !shouldExclude(f.RelativePath, p.operation.ExcludePatterns) {
+				p.sourceDirs = append(p.sourceDirs, &f)
+			}
 			continue
 		}
 
 		// Apply exclude patterns
 		if shouldExclude(f.RelativePath, p.operation.ExcludePatterns) {
 			report.Stats.FilesSkipped.Add(1)
+			p.reportDecision(f.RelativePath, models.ActionSkip, "excluded by pattern", f.Size)
 
 			if p.logger != nil {
 				p.logger.Debug(ctx, "File skipped (excluded by pattern)", logging.Fields{
@@ -334,6 +446,16 @@ func (p *Pipeline) scanSourceAndQueue(ctx context.Context, report *models.SyncRe
 
 		// Create task and add to queue
 		task := NewFileTask(f.RelativePath, f.Size, f.ModTime)
+		task.IsSymlink = f.IsSymlink
+		task.LinkTarget = f.LinkTarget
+		task.SourceInfo = &f
+
+		// Files sharing an inode with a queued file wait for its copy to be linked to it
+		if primary, ok := links.primary(&f); ok {
+			task.HardLinkTo = primary
+			p.hardLinks = append(p.hardLinks, task)
+			continue
+		}
 
 		select {
 		case <-ctx.Done():
@@ -360,6 +482,15 @@ func (p *Pipeline) runWorker(ctx context.Context, workerID int, report *models.S
 				// Queue is closed and empty, worker exits
 				return
 			}
+			if p.adaptive != nil {
+				// Wait until the controller lets this worker be active
+				if !p.adaptive.gate.acquire(ctx) {
+					return
+				}
+				p.processTask(ctx, workerID, task, report)
+				p.adaptive.gate.release()
+				continue
+			}
 			p.processTask(ctx, workerID, task, report)
 		}
 	}
@@ -383,6 +514,10 @@ func (p *Pipeline) processTask(ctx context.Context, workerID int, task *FileTask
 		p.activeFilesMu.Lock()
 		delete(p.activeFiles, task.RelativePath)
 		p.activeFilesMu.Unlock()
+
+		if p.adaptive != nil {
+			p.adaptive.observeFile(task.Error != nil)
+		}
 	}()
 
 	// Step 1: Check if destination file exists (from pre-scanned data)
@@ -401,6 +536,7 @@ func (p *Pipeline) processTask(ctx context.Context, workerID int, task *FileTask
 
 	if !destExists {
 		// File doesn't exist in destination - copy it
+		p.reportDecision(task.RelativePath, models.ActionCopy, "file does not exist in destination", task.Size)
 		if p.formatter != nil {
 			p.formatter.Progress(output.ProgressUpdate{
 				Type:        "file_start",
@@ -428,7 +564,15 @@ func (p *Pipeline) processTask(ctx context.Context, workerID int, task *FileTask
 	var comparison *compare.Comparison
 	var err error
 
-	if p.comparator.Name() == "namesize" {
+	compareStart := time.Now()
+	if task.IsSymlink || destInfo.IsSymlink {
+		// Links are compared by target, never by content
+		comparison = compare.CompareLinks(&storage.FileInfo{
+			RelativePath: task.RelativePath,
+			IsSymlink:    task.IsSymlink,
+			LinkTarget:   task.LinkTarget,
+		}, destInfo)
+	} else if p.comparator.Name() == "namesize" {
 		// Fast path: use pre-scanned metadata
 		if task.Size == destInfo.Size {
 			comparison = &compare.Comparison{
@@ -449,6 +593,11 @@ func (p *Pipeline) processTask(ctx context.Context, workerID int, task *FileTask
 		// Standard path: use comparator (for hash, md5, binary)
 		comparison, err = p.comparator.Compare(ctx, p.source, p.dest, task.RelativePath, task.RelativePath)
 	}
+	if err == nil && (p.comparator.Name() == "namesize" || p.comparator.Name() == "timestamp" || comparison.Stage == "mtime") {
+		// Files of the same size judged by modification time are hashed, so a touched file only gets its metadata updated
+		comparison, err = compare.ConfirmContent(ctx, comparison, p.source, p.dest, task.SourceInfo, destInfo, p.tolerance, readerWrapper(ctx, p.rateLimiter))
+	}
+	p.observePhase(PhaseCompare, compareStart)
 	if err != nil {
 		task.MarkError(err, time.Since(startTime))
 		report.Stats.FilesErrored.Add(1)
@@ -473,8 +622,26 @@ func (p *Pipeline) processTask(ctx context.Context, workerID int, task *FileTask
 		return
 	}
 
+	task.HashAlgorithm = comparison.HashAlgorithm
+	task.SourceHash = comparison.SourceHash
+	task.DestHash = comparison.DestHash
+	task.CompareStage = comparison.Stage
+	if comparison.Stage != "" {
+		task.CompareReason = comparison.Reason
+	}
+	task.CompareDetails = comparison.Details
+
+	// Identical content with differing metadata only needs a metadata update
+	comparison = compare.WithMetadata(comparison, task.SourceInfo, destInfo, p.tolerance)
+	if comparison.Result == compare.MetadataDiffers {
+		task.CompareReason = comparison.Reason
+		p.updateMetadata(ctx, workerID, task, report, fileIndex, startTime, comparison.Reason)
+		return
+	}
+
 	if comparison.Result == compare.Same {
 		// Files are identical - mark as synchronized
+		p.reportDecision(task.RelativePath, models.ActionSkip, comparison.Reason, task.Size)
 		task.MarkCompleted(ResultSynchronized, 0, time.Since(startTime))
 		report.Stats.FilesSynchronized.Add(1)
 		p.processedBytes.Add(task.Size)
@@ -501,6 +668,7 @@ func (p *Pipeline) processTask(ctx context.Context, workerID int, task *FileTask
 	}
 
 	// Files are different - update (copy with overwrite)
+	p.reportDecision(task.RelativePath, models.ActionUpdate, comparison.Reason, task.Size)
 	_ = destInfo // Used for future metadata comparison if needed
 	p.updateFile(ctx, workerID, task, report, fileIndex, startTime)
 }
@@ -540,6 +708,17 @@ func (p *Pipeline) copyFile(ctx context.Context, workerID int, task *FileTask, r
 		return
 	}
 
+	if task.IsSymlink {
+		p.linkFile(ctx, task, report, fileIndex, startTime, ResultCopied)
+		return
+	}
+
+	if p.fastCopy(ctx, task, report, fileIndex, startTime, ResultCopied) {
+		return
+	}
+
+	defer p.observePhase(PhaseTransfer, time.Now())
+
 	// Read from source
 	reader, err := p.source.Read(ctx, task.RelativePath)
 	if err != nil {
@@ -614,8 +793,19 @@ func (p *Pipeline) copyFile(ctx context.Context, workerID int, task *FileTask, r
 		},
 	}
 
+	// Hash the content while streaming it so the written file can be verified
+	var writeReader io.Reader = pr
+	var hr *hashingReader
+	if p.verifier != nil {
+		hr = newHashingReader(pr)
+		writeReader = hr
+	}
+	if p.adaptive != nil {
+		writeReader = p.adaptive.writeReader(writeReader)
+	}
+
 	// Write to destination
-	if err := p.dest.Write(ctx, task.RelativePath, pr, task.Size, sourceInfo); err != nil {
+	if err := p.dest.Write(ctx, task.RelativePath, writeReader, task.Size, sourceInfo); err != nil && !p.metadataWarning(ctx, task.RelativePath, err) {
 		task.MarkError(err, time.Since(startTime))
 		report.Stats.FilesErrored.Add(1)
 		p.recordError(report, task)
@@ -639,9 +829,14 @@ func (p *Pipeline) copyFile(ctx context.Context, workerID int, task *FileTask, r
 		return
 	}
 
+	if hr != nil && !p.verifyTransfer(ctx, task, report, fileIndex, startTime, sourceInfo, hr.Sum()) {
+		return
+	}
+
 	task.MarkCompleted(ResultCopied, task.Size, time.Since(startTime))
 	report.Stats.FilesCopied.Add(1)
 	report.Stats.BytesTransferred.Add(task.Size)
+	p.recordAllocation(ctx, task, sourceInfo, report)
 	p.processedBytes.Add(task.Size)
 	p.addResult(task)
 
@@ -710,6 +905,17 @@ func (p *Pipeline) updateFile(ctx context.Context, workerID int, task *FileTask,
 		return
 	}
 
+	if task.IsSymlink {
+		p.linkFile(ctx, task, report, fileIndex, startTime, ResultUpdated)
+		return
+	}
+
+	if p.fastCopy(ctx, task, report, fileIndex, startTime, ResultUpdated) {
+		return
+	}
+
+	defer p.observePhase(PhaseTransfer, time.Now())
+
 	// Same as copy, but we record it as an update
 	reader, err := p.source.Read(ctx, task.RelativePath)
 	if err != nil {
@@ -782,7 +988,18 @@ func (p *Pipeline) updateFile(ctx context.Context, workerID int, task *FileTask,
 		},
 	}
 
-	if err := p.dest.Write(ctx, task.RelativePath, pr, task.Size, sourceInfo); err != nil {
+	// Hash the content while streaming it so the written file can be verified
+	var writeReader io.Reader = pr
+	var hr *hashingReader
+	if p.verifier != nil {
+		hr = newHashingReader(pr)
+		writeReader = hr
+	}
+	if p.adaptive != nil {
+		writeReader = p.adaptive.writeReader(writeReader)
+	}
+
+	if err := p.dest.Write(ctx, task.RelativePath, writeReader, task.Size, sourceInfo); err != nil && !p.metadataWarning(ctx, task.RelativePath, err) {
 		task.MarkError(err, time.Since(startTime))
 		report.Stats.FilesErrored.Add(1)
 		p.recordError(report, task)
@@ -806,9 +1023,14 @@ func (p *Pipeline) updateFile(ctx context.Context, workerID int, task *FileTask,
 		return
 	}
 
+	if hr != nil && !p.verifyTransfer(ctx, task, report, fileIndex, startTime, sourceInfo, hr.Sum()) {
+		return
+	}
+
 	task.MarkCompleted(ResultUpdated, task.Size, time.Since(startTime))
 	report.Stats.FilesUpdated.Add(1)
 	report.Stats.BytesTransferred.Add(task.Size)
+	p.recordAllocation(ctx, task, sourceInfo, report)
 	p.processedBytes.Add(task.Size)
 	p.addResult(task)
 
@@ -831,6 +1053,139 @@ func (p *Pipeline) updateFile(ctx context.Context, workerID int, task *FileTask,
 	}
 }
 
+// linkFile recreates a source symbolic link in destination
+// result is ResultCopied for a new link and ResultUpdated for a replaced entry
+func (p *Pipeline) linkFile(ctx context.Context, task *FileTask, report *models.SyncReport, fileIndex int, startTime time.Time, result TaskResult) {
+	defer p.observePhase(PhaseTransfer, time.Now())
+
+	if err := p.dest.Symlink(ctx, task.RelativePath, task.LinkTarget); err != nil {
+		task.MarkError(err, time.Since(startTime))
+		report.Stats.FilesErrored.Add(1)
+		p.recordError(report, task)
+		p.addResult(task)
+
+		if p.logger != nil {
+			p.logger.Error(ctx, "Failed to create symbolic link", err, logging.Fields{
+				"path":   task.RelativePath,
+				"target": task.LinkTarget,
+			})
+		}
+
+		if p.formatter != nil {
+			p.formatter.Progress(output.ProgressUpdate{
+				Type:        "file_error",
+				FilePath:    task.RelativePath,
+				CurrentFile: fileIndex,
+				Error:       err,
+			})
+		}
+		return
+	}
+	p.linkOwner(ctx, task)
+
+	task.MarkCompleted(result, 0, time.Since(startTime))
+	if result == ResultUpdated {
+		report.Stats.FilesUpdated.Add(1)
+	} else {
+		report.Stats.FilesCopied.Add(1)
+	}
+	p.processedBytes.Add(task.Size)
+	p.addResult(task)
+
+	if p.logger != nil {
+		p.logger.Debug(ctx, "Symbolic link created", logging.Fields{
+			"path":     task.RelativePath,
+			"target":   task.LinkTarget,
+			"duration": time.Since(startTime).String(),
+		})
+	}
+
+	if p.formatter != nil {
+		p.formatter.Progress(output.ProgressUpdate{
+			Type:         "file_complete",
+			FilePath:     task.RelativePath,
+			BytesWritten: task.Size,
+			TotalBytes:   task.Size,
+			CurrentFile:  fileIndex,
+		})
+	}
+}
+
+// verifyTransfer checks a written file against the hash of the streamed source content
+// Returns false if verification failed; the task is then recorded as failed
+func (p *Pipeline) verifyTransfer(ctx context.Context, task *FileTask, report *models.SyncReport, fileIndex int, startTime time.Time, sourceInfo *storage.FileInfo, sourceHash string) bool {
+	onRetry := func(attempt int, destHash string) {
+		if p.logger != nil {
+			p.logger.Warn(ctx, "Verification failed, copying file again", logging.Fields{
+				"path":        task.RelativePath,
+				"attempt":     attempt,
+				"source_hash": sourceHash,
+				"dest_hash":   destHash,
+			})
+		}
+	}
+
+	_, err := p.verifier.verify(ctx, task.RelativePath, sourceInfo, sourceHash, onRetry)
+	if err == nil {
+		return true
+	}
+
+	if verr, ok := isVerifyError(err); ok {
+		task.HashAlgorithm = verifyAlgorithm
+		task.SourceHash = verr.SourceHash
+		task.DestHash = verr.DestHash
+	}
+	task.MarkError(err, time.Since(startTime))
+	report.Stats.FilesErrored.Add(1)
+	p.recordError(report, task)
+	p.addResult(task)
+
+	if p.logger != nil {
+		p.logger.Error(ctx, "Failed to verify destination file", err, logging.Fields{
+			"path": task.RelativePath,
+			"size": task.Size,
+		})
+	}
+
+	if p.formatter != nil {
+		p.formatter.Progress(output.ProgressUpdate{
+			Type:        "file_error",
+			FilePath:    task.RelativePath,
+			CurrentFile: fileIndex,
+			Error:       err,
+		})
+	}
+	return false
+}
+
+// limitReader wraps a reader with the rate limiter, if bandwidth limiting is enabled
+func (p *Pipeline) limitReader(ctx context.Context, reader io.Reader) io.Reader {
+	if p.rateLimiter == nil {
+		return reader
+	}
+	return ratelimit.NewReader(ctx, reader, p.rateLimiter)
+}
+
+// reportDecision notifies the formatter of the action chosen for a file
+func (p *Pipeline) reportDecision(path string, action models.Action, reason string, size int64) {
+	if p.formatter != nil {
+		p.formatter.Progress(output.ProgressUpdate{
+			Type:       "file_decision",
+			FilePath:   path,
+			TotalBytes: size,
+			Action:     action,
+			Reason:     reason,
+		})
+	}
+}
+
+// observePhase reports the time elapsed since start to the metrics observer
+func (p *Pipeline) observePhase(phase string, start time.Time) {
+	if p.metrics != nil {
+		p.metrics.ObservePhase(phase, time.Since(start))
+	}
+}
+
 // addResult safely adds a completed task to the results
 func (p *Pipeline) addResult(task *FileTask) {
 	p.resultsMu.Lock()
@@ -849,6 +1204,9 @@ func (p *Pipeline) recordError(report *models.SyncReport, task *FileTask) {
 	default:
 		action = models.ActionSkip
 	}
+	if _, ok := isVerifyError(task.Error); ok {
+		action = models.ActionVerify
+	}
 
 	p.resultsMu.Lock()
 	report.Errors = append(report.Errors, models.SyncError{
@@ -886,6 +1244,9 @@ func (p *Pipeline) buildReport(report *models.SyncReport) {
 		case ResultUpdated:
 			action = models.ActionUpdate
 			reason = "file updated from source"
+		case ResultMetadataUpdated:
+			action = models.ActionUpdateMetadata
+			reason = "metadata updated from source"
 		case ResultSynchronized:
 			action = models.ActionSkip
 			reason = "files are identical"
@@ -908,6 +1269,13 @@ func (p *Pipeline) buildReport(report *models.SyncReport) {
 			Error:       task.Error,
 			BytesCopied: task.BytesTransferred,
 			Duration:    task.ProcessingDuration,
+			Stage:       task.CompareStage,
+		}
+		if task.CompareReason != "" && (task.Result == ResultSynchronized || task.Result == ResultUpdated || task.Result == ResultMetadataUpdated) {
+			op.Reason = task.CompareReason
+		}
+		if task.HardLinkTo != "" && task.Result != ResultFailed {
+			op.Reason = "hard link to " + task.HardLinkTo
 		}
 		report.Operations = append(report.Operations, op)
 
@@ -923,6 +1291,17 @@ func (p *Pipeline) buildReport(report *models.SyncReport) {
 					ModTime: task.ModTime,
 				},
 			}
+			if _, ok := isVerifyError(task.Error); ok {
+				diff.Reason = models.ReasonVerifyFailed
+				diff.SourceInfo.Hash = task.SourceHash
+				diff.SourceInfo.HashAlgorithm = task.HashAlgorithm
+				diff.DestInfo = &models.FileInfo{
+					Size:          task.Size,
+					ModTime:       task.ModTime,
+					Hash:          task.DestHash,
+					HashAlgorithm: task.HashAlgorithm,
+				}
+			}
 			report.Differences = append(report.Differences, diff)
 
 		case ResultCopied:
@@ -944,12 +1323,44 @@ func (p *Pipeline) buildReport(report *models.SyncReport) {
 				RelativePath: task.RelativePath,
 				Reason:       models.ReasonContentDiff,
 				Details:      "file content differs",
+				SourceInfo: &models.FileInfo{
+					Size:          task.Size,
+					ModTime:       task.ModTime,
+					Hash:          task.SourceHash,
+					HashAlgorithm: task.HashAlgorithm,
+				},
+				Stage: task.CompareStage,
+			}
+			if task.CompareReason != "" {
+				diff.Details = task.CompareReason
+			}
+			if task.CompareDetails != "" {
+				diff.Details = task.CompareDetails
+			}
+			// Add dest info if available
+			p.destFilesMu.RLock()
+			if destInfo, exists := p.destFiles[task.RelativePath]; exists {
+				diff.DestInfo = &models.FileInfo{
+					Size:          destInfo.Size,
+					ModTime:       destInfo.ModTime,
+					Hash:          task.DestHash,
+					HashAlgorithm: task.HashAlgorithm,
+				}
+			}
+			p.destFilesMu.RUnlock()
+			report.Differences = append(report.Differences, diff)
+
+		case ResultMetadataUpdated:
+			// Content is identical, metadata differs
+			diff := models.FileDifference{
+				RelativePath: task.RelativePath,
+				Reason:       models.ReasonMetadataDiff,
+				Details:      task.CompareReason,
 				SourceInfo: &models.FileInfo{
 					Size:    task.Size,
 					ModTime: task.ModTime,
 				},
 			}
-			// Add dest info if available
 			p.destFilesMu.RLock()
 			if destInfo, exists := p.destFiles[task.RelativePath]; exists {
 				diff.DestInfo = &models.FileInfo{
@@ -983,6 +1394,9 @@ func (p *Pipeline) deleteOrphanFiles(ctx context.Context, report *models.SyncRep
 		}
 	}
 	p.resultsMu.Unlock()
+	for _, dir := range p.sourceDirs {
+		sourceDirs[dir.RelativePath] = true
+	}
 
 	// Find orphan files
 	p.destFilesMu.RLock()
@@ -1014,6 +1428,12 @@ func (p *Pipeline) deleteOrphanFiles(ctx context.Context, report *models.SyncRep
 		fileInfo := p.destFiles[path]
 		p.destFilesMu.RUnlock()
 
+		var size int64
+		if fileInfo != nil {
+			size = fileInfo.Size
+		}
+		p.reportDecision(path, models.ActionDelete, "file does not exist in source", size)
+
 		if p.operation.DryRun {
 			report.Stats.FilesDeleted.Add(1)
 			// Add to differences report
@@ -1040,7 +1460,10 @@ func (p *Pipeline) deleteOrphanFiles(ctx context.Context, report *models.SyncRep
 			continue
 		}
 
-		if err := p.dest.Delete(ctx, path); err != nil {
+		deleteStart := time.Now()
+		err := p.dest.Delete(ctx, path)
+		p.observePhase(PhaseDelete, deleteStart)
+		if err != nil {
 			report.Stats.FilesErrored.Add(1)
 			p.resultsMu.Lock()
 			report.Errors = append(report.Errors, models.SyncError{
@@ -1056,6 +1479,14 @@ func (p *Pipeline) deleteOrphanFiles(ctx context.Context, report *models.SyncRep
 					"path": path,
 				})
 			}
+
+			if p.formatter != nil {
+				p.formatter.Progress(output.ProgressUpdate{
+					Type:     "file_error",
+					FilePath: path,
+					Error:    err,
+				})
+			}
 		} else {
 			report.Stats.FilesDeleted.Add(1)
 			// Add to differences report
@@ -0,0 +1,24 @@
+package sync
+
+import (
+	"context"
+
+	"github.com/sdejongh/syncnorris/pkg/models"
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+// recordAllocation adds the destination space allocated by a transferred file to the report
+// Sparse files are measured on the destination; other files allocate their apparent size
+func (p *Pipeline) recordAllocation(ctx context.Context, task *FileTask, sourceInfo *storage.FileInfo, report *models.SyncReport) {
+	if sourceInfo == nil || !sourceInfo.Sparse {
+		report.Stats.BytesAllocated.Add(task.Size)
+		return
+	}
+
+	report.Stats.SparseFiles.Add(1)
+	allocated := sourceInfo.Allocated
+	if destInfo, err := p.dest.Stat(ctx, task.RelativePath); err == nil && destInfo.Allocated > 0 {
+		allocated = destInfo.Allocated
+	}
+	report.Stats.BytesAllocated.Add(allocated)
+}
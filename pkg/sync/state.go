@@ -6,6 +6,9 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/compare"
+	"github.com/sdejongh/syncnorris/pkg/storage"
 )
 
 // SyncState represents the state of a synchronization pair
@@ -23,6 +26,12 @@ type SyncState struct {
 
 	// Files tracks the state of each file at last sync
 	Files map[string]*FileState `json:"files"`
+
+	// tolerance decides when a modification time counts as changed (exact by default)
+	tolerance compare.TimeTolerance
+
+	// durability decides whether saving fsyncs the state file and its directory
+	durability storage.Durability
 }
 
 // FileState represents the state of a single file at last sync
@@ -39,6 +48,9 @@ type FileState struct {
 	// Hash at last sync (optional, may be empty)
 	Hash string `json:"hash,omitempty"`
 
+	// HashAlgorithm names the algorithm Hash was computed with (empty if Hash is empty)
+	HashAlgorithm string `json:"hash_algorithm,omitempty"`
+
 	// ExistsInSource at last sync
 	ExistsInSource bool `json:"exists_in_source"`
 
@@ -144,7 +156,8 @@ func (s *SyncState) Save() error {
 
 	// Write atomically using temp file
 	tmpPath := statePath + ".tmp"
-	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+	if err := s.writeFile(tmpPath, data); err != nil {
+		os.Remove(tmpPath) // Clean up temp file
 		return fmt.Errorf("failed to write state file: %w", err)
 	}
 
@@ -153,22 +166,56 @@ func (s *SyncState) Save() error {
 		return fmt.Errorf("failed to finalize state file: %w", err)
 	}
 
+	// With full durability, the rename itself must survive a power loss
+	if s.durability == storage.DurabilityFull {
+		if err := storage.SyncDir(dir); err != nil {
+			return fmt.Errorf("failed to finalize state file: %w", err)
+		}
+	}
+
 	return nil
 }
 
+// writeFile writes the state data, fsyncing it with full durability
+func (s *SyncState) writeFile(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if s.durability == storage.DurabilityFull {
+		if err := file.Sync(); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	return file.Close()
+}
+
 // UpdateFile updates the state for a single file after sync
-func (s *SyncState) UpdateFile(relativePath string, size int64, modTime time.Time, hash string, existsInSource, existsInDest, isDir bool) {
+func (s *SyncState) UpdateFile(relativePath string, size int64, modTime time.Time, hash, hashAlgorithm string, existsInSource, existsInDest, isDir bool) {
 	if !existsInSource && !existsInDest {
 		// File was deleted from both sides, remove from state
 		delete(s.Files, relativePath)
 		return
 	}
 
+	// Keep the hash recorded for this file version if no new hash is known
+	if old := s.Files[relativePath]; hash == "" && old != nil && old.Size == size && old.ModTime.Equal(modTime) {
+		hash = old.Hash
+		hashAlgorithm = old.HashAlgorithm
+	}
+
 	s.Files[relativePath] = &FileState{
 		RelativePath:   relativePath,
 		Size:           size,
 		ModTime:        modTime,
 		Hash:           hash,
+		HashAlgorithm:  hashAlgorithm,
 		ExistsInSource: existsInSource,
 		ExistsInDest:   existsInDest,
 		IsDir:          isDir,
@@ -195,6 +242,17 @@ func (s *SyncState) IsFirstSync() bool {
 	return s.LastSyncTime.IsZero()
 }
 
+// SetTimeTolerance sets the tolerance used to detect modified files
+// Coarse destination timestamps (FAT/exFAT) would otherwise be detected as changes
+func (s *SyncState) SetTimeTolerance(tolerance compare.TimeTolerance) {
+	s.tolerance = tolerance
+}
+
+// SetDurability sets how the state file is flushed to stable storage when saved
+func (s *SyncState) SetDurability(durability storage.Durability) {
+	s.durability = durability
+}
+
 // DetectChange determines what kind of change occurred for a file
 func (s *SyncState) DetectChange(relativePath string, currentSize int64, currentModTime time.Time, exists bool, side ChangeSide) ChangeType {
 	oldState := s.GetFileState(relativePath)
@@ -225,7 +283,7 @@ func (s *SyncState) DetectChange(relativePath string, currentSize int64, current
 
 	if existedBefore && exists {
 		// Check for modification
-		if currentSize != oldState.Size || currentModTime.After(oldState.ModTime) {
+		if currentSize != oldState.Size || s.tolerance.After(currentModTime, oldState.ModTime) {
 			return ChangeModified
 		}
 	}
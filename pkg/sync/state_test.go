@@ -5,6 +5,9 @@ import (
 	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/compare"
+	"github.com/sdejongh/syncnorris/pkg/storage"
 )
 
 // ============== SyncState Tests ==============
@@ -33,7 +36,7 @@ func TestSyncState_UpdateFile(t *testing.T) {
 	state := NewSyncState("/source", "/dest")
 	modTime := time.Now()
 
-	state.UpdateFile("test.txt", 1024, modTime, "hash123", true, true, false)
+	state.UpdateFile("test.txt", 1024, modTime, "hash123", "sha256", true, true, false)
 
 	fileState := state.GetFileState("test.txt")
 	if fileState == nil {
@@ -51,6 +54,9 @@ func TestSyncState_UpdateFile(t *testing.T) {
 	if fileState.Hash != "hash123" {
 		t.Errorf("Hash = %s, want hash123", fileState.Hash)
 	}
+	if fileState.HashAlgorithm != "sha256" {
+		t.Errorf("HashAlgorithm = %s, want sha256", fileState.HashAlgorithm)
+	}
 	if !fileState.ExistsInSource {
 		t.Error("ExistsInSource should be true")
 	}
@@ -66,20 +72,39 @@ func TestSyncState_UpdateFile_DeletesBothSides(t *testing.T) {
 	state := NewSyncState("/source", "/dest")
 
 	// Add a file
-	state.UpdateFile("test.txt", 1024, time.Now(), "", true, true, false)
+	state.UpdateFile("test.txt", 1024, time.Now(), "", "", true, true, false)
 
 	// Update to not exist on either side - should be removed
-	state.UpdateFile("test.txt", 0, time.Time{}, "", false, false, false)
+	state.UpdateFile("test.txt", 0, time.Time{}, "", "", false, false, false)
 
 	if state.GetFileState("test.txt") != nil {
 		t.Error("File should be removed when not existing on either side")
 	}
 }
 
+func TestSyncState_UpdateFile_KeepsHash(t *testing.T) {
+	state := NewSyncState("/source", "/dest")
+	modTime := time.Now()
+
+	state.UpdateFile("test.txt", 1024, modTime, "hash123", "xxh128", true, true, false)
+
+	// Same version synced again without a hash keeps the recorded hash
+	state.UpdateFile("test.txt", 1024, modTime, "", "", true, true, false)
+	if fs := state.GetFileState("test.txt"); fs.Hash != "hash123" || fs.HashAlgorithm != "xxh128" {
+		t.Errorf("Hash = %s:%s, want xxh128:hash123", fs.HashAlgorithm, fs.Hash)
+	}
+
+	// A new version drops it
+	state.UpdateFile("test.txt", 2048, modTime, "", "", true, true, false)
+	if fs := state.GetFileState("test.txt"); fs.Hash != "" {
+		t.Errorf("Hash = %s, want empty for a modified file", fs.Hash)
+	}
+}
+
 func TestSyncState_RemoveFile(t *testing.T) {
 	state := NewSyncState("/source", "/dest")
 
-	state.UpdateFile("test.txt", 1024, time.Now(), "", true, true, false)
+	state.UpdateFile("test.txt", 1024, time.Now(), "", "", true, true, false)
 	if state.GetFileState("test.txt") == nil {
 		t.Fatal("File should exist before removal")
 	}
@@ -147,9 +172,9 @@ func TestSyncState_SaveAndLoad(t *testing.T) {
 	state := NewSyncState(sourcePath, destPath)
 	modTime := time.Now().Truncate(time.Second) // Truncate for JSON roundtrip
 
-	state.UpdateFile("file1.txt", 100, modTime, "hash1", true, true, false)
-	state.UpdateFile("file2.txt", 200, modTime, "hash2", true, false, false)
-	state.UpdateFile("dir/file3.txt", 300, modTime, "", true, true, false)
+	state.UpdateFile("file1.txt", 100, modTime, "hash1", "sha256", true, true, false)
+	state.UpdateFile("file2.txt", 200, modTime, "hash2", "sha256", true, false, false)
+	state.UpdateFile("dir/file3.txt", 300, modTime, "", "", true, true, false)
 	state.MarkSyncComplete()
 
 	// Save
@@ -203,6 +228,32 @@ func TestSyncState_SaveAndLoad(t *testing.T) {
 	}
 }
 
+func TestSyncState_SaveDurable(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	tempDir := t.TempDir()
+	sourcePath := filepath.Join(tempDir, "source")
+	destPath := filepath.Join(tempDir, "dest")
+
+	state := NewSyncState(sourcePath, destPath)
+	state.SetDurability(storage.DurabilityFull)
+	state.UpdateFile("file.txt", 100, time.Now().Truncate(time.Second), "hash", "sha256", true, true, false)
+
+	if err := state.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := os.Stat(getStateFilePath(sourcePath, destPath) + ".tmp"); !os.IsNotExist(err) {
+		t.Error("temporary state file should be renamed")
+	}
+
+	loaded, err := LoadState(sourcePath, destPath)
+	if err != nil {
+		t.Fatalf("LoadState() error = %v", err)
+	}
+	if loaded.GetFileState("file.txt") == nil {
+		t.Error("file.txt not found")
+	}
+}
+
 func TestLoadState_NonExistent(t *testing.T) {
 	// Use unique paths that don't have existing state
 	sourcePath := "/tmp/nonexistent-source-12345"
@@ -237,7 +288,7 @@ func TestClearState(t *testing.T) {
 
 	// Create and save state
 	state := NewSyncState(sourcePath, destPath)
-	state.UpdateFile("test.txt", 100, time.Now(), "", true, true, false)
+	state.UpdateFile("test.txt", 100, time.Now(), "", "", true, true, false)
 	if err := state.Save(); err != nil {
 		t.Fatalf("Save() error = %v", err)
 	}
@@ -280,7 +331,7 @@ func TestSyncState_DetectChange_Created(t *testing.T) {
 
 func TestSyncState_DetectChange_Deleted(t *testing.T) {
 	state := NewSyncState("/source", "/dest")
-	state.UpdateFile("old.txt", 100, time.Now(), "", true, true, false)
+	state.UpdateFile("old.txt", 100, time.Now(), "", "", true, true, false)
 
 	change := state.DetectChange("old.txt", 0, time.Time{}, false, SideSource)
 
@@ -291,7 +342,7 @@ func TestSyncState_DetectChange_Deleted(t *testing.T) {
 
 func TestSyncState_DetectChange_Modified_Size(t *testing.T) {
 	state := NewSyncState("/source", "/dest")
-	state.UpdateFile("file.txt", 100, time.Now(), "", true, true, false)
+	state.UpdateFile("file.txt", 100, time.Now(), "", "", true, true, false)
 
 	// Different size
 	change := state.DetectChange("file.txt", 200, time.Now(), true, SideSource)
@@ -304,7 +355,7 @@ func TestSyncState_DetectChange_Modified_Size(t *testing.T) {
 func TestSyncState_DetectChange_Modified_Time(t *testing.T) {
 	state := NewSyncState("/source", "/dest")
 	oldTime := time.Now().Add(-time.Hour)
-	state.UpdateFile("file.txt", 100, oldTime, "", true, true, false)
+	state.UpdateFile("file.txt", 100, oldTime, "", "", true, true, false)
 
 	// Same size, newer time
 	change := state.DetectChange("file.txt", 100, time.Now(), true, SideSource)
@@ -317,7 +368,7 @@ func TestSyncState_DetectChange_Modified_Time(t *testing.T) {
 func TestSyncState_DetectChange_None(t *testing.T) {
 	state := NewSyncState("/source", "/dest")
 	modTime := time.Now()
-	state.UpdateFile("file.txt", 100, modTime, "", true, true, false)
+	state.UpdateFile("file.txt", 100, modTime, "", "", true, true, false)
 
 	// Same size and time
 	change := state.DetectChange("file.txt", 100, modTime, true, SideSource)
@@ -327,6 +378,27 @@ func TestSyncState_DetectChange_None(t *testing.T) {
 	}
 }
 
+func TestSyncState_DetectChange_Tolerance(t *testing.T) {
+	state := NewSyncState("/source", "/dest")
+	modTime := time.Date(2025, 10, 25, 12, 0, 5, 300000000, time.UTC)
+	state.UpdateFile("file.txt", 100, modTime, "", "", true, true, false)
+
+	// A FAT destination rounded the time up to an even second, then DST shifted it by an hour
+	fatTime := time.Date(2025, 10, 25, 13, 0, 6, 0, time.UTC)
+
+	if change := state.DetectChange("file.txt", 100, fatTime, true, SideDest); change != ChangeModified {
+		t.Errorf("Change = %s, want modified without tolerance", change)
+	}
+
+	state.SetTimeTolerance(compare.TimeTolerance{Window: 2 * time.Second, IgnoreHourOffsets: true})
+	if change := state.DetectChange("file.txt", 100, fatTime, true, SideDest); change != ChangeNone {
+		t.Errorf("Change = %s, want none within the modify window and hour offset", change)
+	}
+	if change := state.DetectChange("file.txt", 100, fatTime.Add(time.Minute), true, SideDest); change != ChangeModified {
+		t.Errorf("Change = %s, want modified beyond the tolerance", change)
+	}
+}
+
 func TestSyncState_DetectChange_NonExistent(t *testing.T) {
 	state := NewSyncState("/source", "/dest")
 
@@ -2,6 +2,8 @@ package sync
 
 import (
 	"time"
+
+	"github.com/sdejongh/syncnorris/pkg/storage"
 )
 
 // TaskStatus represents the status of a file task in the pipeline
@@ -26,6 +28,8 @@ const (
 	ResultCopied TaskResult = "copied"
 	// ResultUpdated indicates the file was updated (content changed)
 	ResultUpdated TaskResult = "updated"
+	// ResultMetadataUpdated indicates only the file's metadata was updated (content identical)
+	ResultMetadataUpdated TaskResult = "metadata_updated"
 	// ResultSynchronized indicates the file was already identical
 	ResultSynchronized TaskResult = "synchronized"
 	// ResultSkipped indicates the file was skipped (dest-only, etc.)
@@ -54,6 +58,19 @@ type FileTask struct {
 	// Error holds any error that occurred during processing
 	Error error
 
+	// IsSymlink is set when the source entry is a symbolic link, recreated rather than copied
+	IsSymlink bool
+
+	// LinkTarget is the target of the source symbolic link
+	LinkTarget string
+
+	// HardLinkTo is the path of the first source file sharing this file's inode
+	// When set, the file is recreated as a hard link to that file in destination
+	HardLinkTo string
+
+	// SourceInfo is the source scan entry, holding the preserved metadata
+	SourceInfo *storage.FileInfo
+
 	// BytesTransferred tracks how many bytes were actually transferred
 	BytesTransferred int64
 
@@ -62,6 +79,18 @@ type FileTask struct {
 
 	// WorkerID identifies which worker processed this task
 	WorkerID int
+
+	// Content hashes computed during comparison (empty for non-hash comparators)
+	HashAlgorithm string
+	SourceHash    string
+	DestHash      string
+
+	// Comparison chain stage that decided the result and its reason (empty for other comparators)
+	CompareStage  string
+	CompareReason string
+
+	// Detailed description of a content difference (e.g., unified diff excerpt of text files)
+	CompareDetails string
 }
 
 // NewFileTask creates a new file task from scan data
@@ -0,0 +1,150 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/sdejongh/syncnorris/pkg/compare"
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+// verifyAlgorithm is the hash used to verify transferred files
+// Detecting corruption doesn't need a cryptographic hash, so the fastest 128-bit hash is used
+const verifyAlgorithm = compare.AlgorithmXXH128
+
+// VerifyError is returned when a written file doesn't match the content read from the source
+type VerifyError struct {
+	Path       string
+	SourceHash string
+	DestHash   string
+	Attempts   int // Number of times the file was written
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("verification failed after %d attempt(s): %s %s read from source, %s read back from destination",
+		e.Attempts, verifyAlgorithm, e.SourceHash, e.DestHash)
+}
+
+// isVerifyError reports whether err is a verification failure
+func isVerifyError(err error) (*VerifyError, bool) {
+	var verr *VerifyError
+	ok := errors.As(err, &verr)
+	return verr, ok
+}
+
+// hashingReader hashes content as it is read
+type hashingReader struct {
+	reader io.Reader
+	hash   hash.Hash
+}
+
+// newHashingReader wraps a reader so the streamed content is hashed with the verification algorithm
+func newHashingReader(reader io.Reader) *hashingReader {
+	h, _ := compare.NewHasher(verifyAlgorithm)
+	return &hashingReader{reader: reader, hash: h}
+}
+
+func (r *hashingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.hash.Write(p[:n])
+	}
+	return n, err
+}
+
+// Sum returns the hash of the content read so far
+func (r *hashingReader) Sum() string {
+	return fmt.Sprintf("%x", r.hash.Sum(nil))
+}
+
+// transferVerifier re-reads written files and copies them again when they don't match the source
+type transferVerifier struct {
+	source  storage.Backend
+	dest    storage.Backend
+	retries int                                        // Additional copies attempted after a mismatch
+	wrap    func(context.Context, io.Reader) io.Reader // Optional source reader wrapper (e.g., rate limiting)
+}
+
+// verify hashes the destination file and compares it with the hash of the streamed source content
+// On mismatch the file is copied again up to retries times; returns a *VerifyError if it never matches
+// onRetry, if set, is called before each new copy
+func (v *transferVerifier) verify(ctx context.Context, path string, metadata *storage.FileInfo, sourceHash string, onRetry func(attempt int, destHash string)) (string, error) {
+	for attempt := 1; ; attempt++ {
+		destHash, err := v.hashDest(ctx, path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read back destination: %w", err)
+		}
+		if destHash == sourceHash {
+			return destHash, nil
+		}
+		if attempt > v.retries {
+			return destHash, &VerifyError{Path: path, SourceHash: sourceHash, DestHash: destHash, Attempts: attempt}
+		}
+
+		if onRetry != nil {
+			onRetry(attempt, destHash)
+		}
+		if sourceHash, err = v.copy(ctx, path, metadata); err != nil {
+			return "", err
+		}
+	}
+}
+
+// copy copies a file again, hashing the source content while streaming it
+func (v *transferVerifier) copy(ctx context.Context, path string, metadata *storage.FileInfo) (string, error) {
+	reader, err := v.source.Read(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read source: %w", err)
+	}
+	defer reader.Close()
+
+	var readerToUse io.Reader = reader
+	if v.wrap != nil {
+		readerToUse = v.wrap(ctx, readerToUse)
+	}
+	hr := newHashingReader(readerToUse)
+
+	// Metadata that could not be preserved was already reported by the first copy
+	var metadataErr *storage.MetadataError
+	if err := v.dest.Write(ctx, path, hr, metadata.Size, metadata); err != nil && !errors.As(err, &metadataErr) {
+		return "", fmt.Errorf("failed to write destination: %w", err)
+	}
+	return hr.Sum(), nil
+}
+
+// hashDest reads the destination file back and hashes it
+// The hash cache is bypassed: a cached hash would describe what was expected, not what is on disk.
+// So is the page cache when the backend supports it, otherwise the data just written would be
+// read from memory and corruption on the way to the device would go unnoticed
+func (v *transferVerifier) hashDest(ctx context.Context, path string) (string, error) {
+	var reader io.ReadCloser
+	var err error
+	if uncached, ok := v.dest.(storage.UncachedReader); ok {
+		reader, err = uncached.ReadUncached(ctx, path)
+	} else {
+		reader, err = v.dest.Read(ctx, path)
+	}
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	hr := newHashingReader(reader)
+	buf := make([]byte, 256*1024)
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		_, err := hr.Read(buf)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return hr.Sum(), nil
+}
@@ -0,0 +1,175 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sdejongh/syncnorris/pkg/compare"
+	"github.com/sdejongh/syncnorris/pkg/models"
+	"github.com/sdejongh/syncnorris/pkg/storage"
+)
+
+// corruptingBackend flips a bit in the first bytes written, like a flaky target
+type corruptingBackend struct {
+	*storage.Local
+	corrupt atomic.Int32 // Number of writes still to corrupt (-1 = all)
+	writes  atomic.Int32
+}
+
+func (b *corruptingBackend) Write(ctx context.Context, path string, reader io.Reader, size int64, metadata *storage.FileInfo) error {
+	b.writes.Add(1)
+	if n := b.corrupt.Load(); n != 0 {
+		b.corrupt.Add(-1)
+		reader = &flipReader{reader: reader}
+	}
+	return b.Local.Write(ctx, path, reader, size, metadata)
+}
+
+// flipReader flips the lowest bit of the first byte read
+type flipReader struct {
+	reader  io.Reader
+	flipped bool
+}
+
+func (r *flipReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 && !r.flipped {
+		p[0] ^= 1
+		r.flipped = true
+	}
+	return n, err
+}
+
+func TestPipeline_Verify(t *testing.T) {
+	tests := []struct {
+		name        string
+		corrupt     int32
+		retries     int
+		wantStatus  models.SyncStatus
+		wantWrites  int32
+		wantCorrupt bool
+	}{
+		{"CleanWrite", 0, 1, models.StatusSuccess, 1, false},
+		{"RecoversOnRetry", 1, 1, models.StatusSuccess, 2, false},
+		{"RetriesExhausted", -1, 2, models.StatusFailed, 3, true},
+		{"NoRetries", -1, 0, models.StatusFailed, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewTestHelper(t)
+			defer h.Cleanup()
+
+			content := []byte("content that must survive the trip")
+			h.CreateSourceFile("file.txt", content)
+
+			dest := &corruptingBackend{Local: h.dest}
+			dest.corrupt.Store(tt.corrupt)
+
+			op := h.NewOperation()
+			op.Mode = models.ModeOneWay
+			op.Verify = true
+			op.VerifyRetries = tt.retries
+
+			pipeline := NewPipeline(h.source, dest, compare.NewHashComparator(4096), &nullFormatter{}, nil, op, PipelineConfig{MaxWorkers: 1, QueueSize: 100})
+			report, err := pipeline.Run(context.Background())
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			if report.Status != tt.wantStatus {
+				t.Errorf("Status = %s, want %s", report.Status, tt.wantStatus)
+			}
+			if got := dest.writes.Load(); got != tt.wantWrites {
+				t.Errorf("writes = %d, want %d", got, tt.wantWrites)
+			}
+
+			written, _ := h.ReadDestFile("file.txt")
+			if bytes.Equal(written, content) == tt.wantCorrupt {
+				t.Errorf("destination corrupt = %v, want %v", !tt.wantCorrupt, tt.wantCorrupt)
+			}
+
+			if !tt.wantCorrupt {
+				if len(report.Errors) != 0 {
+					t.Errorf("Errors = %+v, want none", report.Errors)
+				}
+				return
+			}
+
+			if len(report.Errors) != 1 || report.Errors[0].Operation != models.ActionVerify {
+				t.Fatalf("Errors = %+v, want one verify error", report.Errors)
+			}
+			if len(report.Differences) != 1 {
+				t.Fatalf("Differences = %+v, want 1", report.Differences)
+			}
+			diff := report.Differences[0]
+			if diff.Reason != models.ReasonVerifyFailed {
+				t.Errorf("Reason = %s, want %s", diff.Reason, models.ReasonVerifyFailed)
+			}
+			if diff.SourceInfo == nil || diff.DestInfo == nil || diff.SourceInfo.Hash == diff.DestInfo.Hash || diff.DestInfo.HashAlgorithm != verifyAlgorithm {
+				t.Errorf("difference hashes not recorded: %+v %+v", diff.SourceInfo, diff.DestInfo)
+			}
+		})
+	}
+}
+
+func TestPipeline_VerifyUpdate(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.CreateSourceFile("file.txt", []byte("new content"))
+	h.CreateDestFile("file.txt", []byte("old content"))
+
+	dest := &corruptingBackend{Local: h.dest}
+	dest.corrupt.Store(1)
+
+	op := h.NewOperation()
+	op.Mode = models.ModeOneWay
+	op.Verify = true
+	op.VerifyRetries = 1
+
+	pipeline := NewPipeline(h.source, dest, compare.NewHashComparator(4096), &nullFormatter{}, nil, op, PipelineConfig{MaxWorkers: 1, QueueSize: 100})
+	report, err := pipeline.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Status != models.StatusSuccess {
+		t.Errorf("Status = %s, want success", report.Status)
+	}
+	if got := report.Stats.FilesUpdated.Load(); got != 1 {
+		t.Errorf("FilesUpdated = %d, want 1", got)
+	}
+	if written, _ := h.ReadDestFile("file.txt"); string(written) != "new content" {
+		t.Errorf("destination = %q, want new content", written)
+	}
+}
+
+func TestBidirectionalPipeline_Verify(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.CreateSourceFile("file.txt", []byte("source content"))
+
+	dest := &corruptingBackend{Local: h.dest}
+	dest.corrupt.Store(-1)
+
+	op := h.NewOperation()
+	op.Verify = true
+	op.VerifyRetries = 1
+
+	pipeline := NewBidirectionalPipeline(h.source, dest, compare.NewHashComparator(4096), &nullFormatter{}, nil, op, PipelineConfig{MaxWorkers: 1, QueueSize: 100})
+	report, _ := pipeline.Run(context.Background())
+
+	if got := dest.writes.Load(); got != 2 {
+		t.Errorf("writes = %d, want 2", got)
+	}
+	if len(report.Errors) != 1 || report.Errors[0].Operation != models.ActionVerify {
+		t.Fatalf("Errors = %+v, want one verify error", report.Errors)
+	}
+	if len(report.Differences) != 1 || report.Differences[0].Reason != models.ReasonVerifyFailed {
+		t.Errorf("Differences = %+v, want one verification failure", report.Differences)
+	}
+}
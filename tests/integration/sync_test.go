@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -451,6 +452,115 @@ func TestOneWaySync_MultipleMethods(t *testing.T) {
 	}
 }
 
+func TestOneWaySync_ComparisonChain(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	modTime := time.Now().Add(-time.Hour)
+	h.CreateSourceFile("same.txt", []byte("unchanged"))
+	h.CreateDestFile("same.txt", []byte("unchanged"))
+	h.SetFileModTime(true, "same.txt", modTime)
+	h.SetFileModTime(false, "same.txt", modTime)
+	h.CreateSourceFile("edited.txt", []byte("new content"))
+	h.CreateDestFile("edited.txt", []byte("old content"))
+	h.SetFileModTime(false, "edited.txt", modTime)
+
+	comparator, err := compare.ParseChain([]string{"size", "mtime(window=2s)", "full-hash(xxh128)"}, 4096)
+	if err != nil {
+		t.Fatalf("ParseChain() error = %v", err)
+	}
+
+	op := h.NewOperation(models.ModeOneWay)
+	op.ComparisonMethod = models.CompareChain
+	op.DryRun = true
+
+	engine := sync.NewEngine(h.source, h.dest, comparator, &nullFormatter{}, nil, op)
+	report, err := engine.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(report.Differences) != 1 || report.Differences[0].Stage != "full-hash(xxh128)" {
+		t.Fatalf("Differences = %+v, want edited.txt decided by full-hash(xxh128)", report.Differences)
+	}
+	for _, op := range report.Operations {
+		if op.Entry.RelativePath == "same.txt" && op.Stage != "mtime" {
+			t.Errorf("same.txt decided by %q, want mtime", op.Stage)
+		}
+	}
+}
+
+// TestOneWaySync_TextComparison tests that line ending differences of text files are not copied
+func TestOneWaySync_TextComparison(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.CreateSourceFile("app.conf", []byte("port = 80\r\nhost = a\r\n"))
+	h.CreateDestFile("app.conf", []byte("port = 80\nhost = a\n"))
+	h.CreateSourceFile("db.conf", []byte("user = x\r\n"))
+	h.CreateDestFile("db.conf", []byte("user = y\n"))
+
+	comparator := compare.NewTextComparator(compare.NewCompositeComparator(true, 4096), []string{"*.conf"}, compare.TextOptions{})
+	op := h.NewOperation(models.ModeOneWay)
+
+	engine := sync.NewEngine(h.source, h.dest, comparator, &nullFormatter{}, nil, op)
+	report, err := engine.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// Only line endings differ: the destination keeps its LF file
+	content, err := h.ReadDestFile("app.conf")
+	if err != nil || string(content) != "port = 80\nhost = a\n" {
+		t.Errorf("app.conf = %q, %v; want the LF file left untouched", content, err)
+	}
+	content, err = h.ReadDestFile("db.conf")
+	if err != nil || string(content) != "user = x\r\n" {
+		t.Errorf("db.conf = %q, %v; want the source copied", content, err)
+	}
+
+	if len(report.Differences) != 1 || !strings.Contains(report.Differences[0].Details, "-user = y\n+user = x\n") {
+		t.Errorf("Differences = %+v, want db.conf with a diff excerpt", report.Differences)
+	}
+}
+
+// TestCompare_ShowDiff tests that compare reports describe content differences
+func TestCompare_ShowDiff(t *testing.T) {
+	h := NewTestHelper(t)
+	defer h.Cleanup()
+
+	h.CreateSourceFile("app.conf", []byte("port = 80\n"))
+	h.CreateDestFile("app.conf", []byte("port = 81\n"))
+	h.CreateSourceFile("image.bin", []byte{0, 1, 2, 3})
+	h.CreateDestFile("image.bin", []byte{0, 1, 9, 3})
+	h.CreateSourceFile("new.conf", []byte("only in source\n"))
+
+	op := h.NewOperation(models.ModeOneWay)
+	op.DryRun = true
+	op.ShowDiff = true
+
+	engine := sync.NewEngine(h.source, h.dest, compare.NewCompositeComparator(true, 4096), &nullFormatter{}, nil, op)
+	report, err := engine.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	byPath := make(map[string]models.FileDifference)
+	for _, diff := range report.Differences {
+		byPath[diff.RelativePath] = diff
+	}
+
+	if diff := byPath["app.conf"]; !strings.Contains(diff.Diff, "-port = 81\n+port = 80\n") || diff.DiffOffset != nil {
+		t.Errorf("app.conf difference = %+v, want a unified diff", diff)
+	}
+	if diff := byPath["image.bin"]; diff.Diff != "" || diff.DiffOffset == nil || *diff.DiffOffset != 2 {
+		t.Errorf("image.bin difference = %+v, want offset 2", diff)
+	}
+	if diff := byPath["new.conf"]; diff.Diff != "" || diff.DiffOffset != nil {
+		t.Errorf("new.conf difference = %+v, want no description", diff)
+	}
+}
+
 // ============== Bidirectional Sync Tests ==============
 
 func TestBidirectionalSync_NewFilesOnBothSides(t *testing.T) {